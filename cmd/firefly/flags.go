@@ -0,0 +1,154 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/shoresh319/firefly/internal/app"
+)
+
+// cliOptions is the result of parsing the command line.
+type cliOptions struct {
+	Config  app.Config
+	Timeout time.Duration // Overall run deadline; zero means none
+}
+
+// parseFlags builds the run configuration from args. Each flag falls back to a
+// FIREFLY_* environment variable, read through getenv, before its built-in default.
+func parseFlags(args []string, getenv func(string) string, stderr io.Writer) (cliOptions, error) {
+	defaultTop, err := envInt(getenv, "FIREFLY_TOP", 10)
+	if err != nil {
+		return cliOptions{}, err
+	}
+	defaultWorkers, err := envInt(getenv, "FIREFLY_WORKERS", 0)
+	if err != nil {
+		return cliOptions{}, err
+	}
+	defaultTimeout, err := envDuration(getenv, "FIREFLY_TIMEOUT", 0)
+	if err != nil {
+		return cliOptions{}, err
+	}
+	defaultMaxRuntime, err := envDuration(getenv, "FIREFLY_MAX_RUNTIME", 0)
+	if err != nil {
+		return cliOptions{}, err
+	}
+	defaultMaxArticles, err := envInt(getenv, "FIREFLY_MAX_ARTICLES", 0)
+	if err != nil {
+		return cliOptions{}, err
+	}
+	defaultHistogram, err := envBool(getenv, "FIREFLY_HISTOGRAM", false)
+	if err != nil {
+		return cliOptions{}, err
+	}
+	defaultDryRun, err := envBool(getenv, "FIREFLY_DRY_RUN", false)
+	if err != nil {
+		return cliOptions{}, err
+	}
+	defaultProgress, err := envBool(getenv, "FIREFLY_PROGRESS", false)
+	if err != nil {
+		return cliOptions{}, err
+	}
+	defaultDeterministic, err := envBool(getenv, "FIREFLY_DETERMINISTIC", false)
+	if err != nil {
+		return cliOptions{}, err
+	}
+	defaultAllowLocalFiles, err := envBool(getenv, "FIREFLY_ALLOW_LOCAL_FILES", false)
+	if err != nil {
+		return cliOptions{}, err
+	}
+
+	fs := flag.NewFlagSet("firefly", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	words := fs.String("words", envString(getenv, "FIREFLY_WORDS", filepath.Join("internal", "assets", "words.txt")), "path to the word bank file (env FIREFLY_WORDS)")
+	urls := fs.String("urls", envString(getenv, "FIREFLY_URLS", filepath.Join("internal", "assets", "endg-urls.txt")), "path to the article URL list, or - for stdin (env FIREFLY_URLS)")
+	top := fs.Int("top", defaultTop, "number of top words to output (env FIREFLY_TOP)")
+	workers := fs.Int("workers", defaultWorkers, "number of worker goroutines, 0 for one per CPU (env FIREFLY_WORKERS)")
+	timeout := fs.Duration("timeout", defaultTimeout, "overall run timeout, 0 for none (env FIREFLY_TIMEOUT)")
+	maxRuntime := fs.Duration("max-runtime", defaultMaxRuntime, "stop starting new articles after this long and output partial results, 0 for none (env FIREFLY_MAX_RUNTIME)")
+	maxArticles := fs.Int("max-articles", defaultMaxArticles, "stop after this many articles are fetched successfully and output their counts, 0 for no limit (env FIREFLY_MAX_ARTICLES)")
+	dryRun := fs.Bool("dry-run", defaultDryRun, "validate the word bank and URL list without fetching (env FIREFLY_DRY_RUN)")
+	histogram := fs.Bool("histogram", defaultHistogram, "also output a histogram of distinct words per word length (env FIREFLY_HISTOGRAM)")
+	progress := fs.Bool("progress", defaultProgress, "draw a progress bar on stderr while counting (env FIREFLY_PROGRESS)")
+	deterministic := fs.Bool("deterministic", defaultDeterministic, "process URLs one at a time in list order, for reproducible debugging runs (env FIREFLY_DETERMINISTIC)")
+	allowLocalFiles := fs.Bool("allow-local-files", defaultAllowLocalFiles, "read file:// URLs and absolute paths in the URL list from disk (env FIREFLY_ALLOW_LOCAL_FILES)")
+
+	if err := fs.Parse(args); err != nil {
+		return cliOptions{}, err
+	}
+	if fs.NArg() > 0 {
+		return cliOptions{}, fmt.Errorf("unexpected arguments: %v", fs.Args())
+	}
+
+	var progressOut io.Writer
+	if *progress {
+		progressOut = stderr
+	}
+
+	return cliOptions{
+		Config: app.Config{
+			TopWordNum:           *top,
+			WordBankPath:         *words,
+			ArticleListPath:      *urls,
+			WorkerCount:          *workers,
+			RetryMax:             10,
+			RetryWaitMin:         10 * time.Second,
+			RetryWaitMax:         5 * time.Minute,
+			ConcurrencyPerDomain: 10,
+			MaxRuntime:           *maxRuntime,
+			MaxArticles:          *maxArticles,
+			LengthHistogram:      *histogram,
+			DryRun:               *dryRun,
+			Progress:             progressOut,
+			Deterministic:        *deterministic,
+			AllowLocalFiles:      *allowLocalFiles,
+		},
+		Timeout: *timeout,
+	}, nil
+}
+
+func envString(getenv func(string) string, key, fallback string) string {
+	if v := getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
+
+func envInt(getenv func(string) string, key string, fallback int) (int, error) {
+	v := getenv(key)
+	if v == "" {
+		return fallback, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	return n, nil
+}
+
+func envDuration(getenv func(string) string, key string, fallback time.Duration) (time.Duration, error) {
+	v := getenv(key)
+	if v == "" {
+		return fallback, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	return d, nil
+}
+
+func envBool(getenv func(string) string, key string, fallback bool) (bool, error) {
+	v := getenv(key)
+	if v == "" {
+		return fallback, nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	return b, nil
+}
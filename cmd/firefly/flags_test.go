@@ -0,0 +1,193 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+)
+
+func envMap(values map[string]string) func(string) string {
+	return func(key string) string { return values[key] }
+}
+
+func TestParseFlagsDefaults(t *testing.T) {
+	opts, err := parseFlags(nil, envMap(nil), io.Discard)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if opts.Config.TopWordNum != 10 || opts.Config.WorkerCount != 0 || opts.Timeout != 0 {
+		t.Fatalf("unexpected defaults: %+v timeout=%v", opts.Config, opts.Timeout)
+	}
+	if opts.Config.WordBankPath == "" || opts.Config.ArticleListPath == "" {
+		t.Fatalf("expected default paths, got %+v", opts.Config)
+	}
+}
+
+func TestParseFlagsOverrides(t *testing.T) {
+	env := envMap(map[string]string{
+		"FIREFLY_WORDS":   "/env/words.txt",
+		"FIREFLY_TOP":     "25",
+		"FIREFLY_TIMEOUT": "30s",
+	})
+	args := []string{"-urls", "-", "-top", "5", "-workers", "8"}
+
+	opts, err := parseFlags(args, env, io.Discard)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cfg := opts.Config
+	if cfg.WordBankPath != "/env/words.txt" {
+		t.Fatalf("expected env word bank path, got %q", cfg.WordBankPath)
+	}
+	if cfg.ArticleListPath != "-" {
+		t.Fatalf("expected stdin article list, got %q", cfg.ArticleListPath)
+	}
+	if cfg.TopWordNum != 5 {
+		t.Fatalf("expected flag to override env top, got %d", cfg.TopWordNum)
+	}
+	if cfg.WorkerCount != 8 {
+		t.Fatalf("expected 8 workers, got %d", cfg.WorkerCount)
+	}
+	if opts.Timeout != 30*time.Second {
+		t.Fatalf("expected env timeout 30s, got %v", opts.Timeout)
+	}
+	if cfg.LengthHistogram {
+		t.Fatal("expected histogram to be off by default")
+	}
+}
+
+func TestParseFlagsDryRun(t *testing.T) {
+	opts, err := parseFlags([]string{"-dry-run"}, envMap(nil), io.Discard)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !opts.Config.DryRun {
+		t.Fatal("expected -dry-run to enable dry-run mode")
+	}
+}
+
+func TestParseFlagsDeterministic(t *testing.T) {
+	opts, err := parseFlags(nil, envMap(map[string]string{"FIREFLY_DETERMINISTIC": "true"}), io.Discard)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !opts.Config.Deterministic {
+		t.Fatal("expected FIREFLY_DETERMINISTIC to enable deterministic mode")
+	}
+
+	opts, err = parseFlags([]string{"-deterministic=false"}, envMap(map[string]string{"FIREFLY_DETERMINISTIC": "true"}), io.Discard)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if opts.Config.Deterministic {
+		t.Fatal("expected -deterministic=false to override the environment")
+	}
+}
+
+func TestParseFlagsAllowLocalFiles(t *testing.T) {
+	opts, err := parseFlags(nil, envMap(nil), io.Discard)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if opts.Config.AllowLocalFiles {
+		t.Fatal("expected local files to be disallowed by default")
+	}
+
+	opts, err = parseFlags([]string{"-allow-local-files"}, envMap(nil), io.Discard)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !opts.Config.AllowLocalFiles {
+		t.Fatal("expected -allow-local-files to allow local files")
+	}
+
+	opts, err = parseFlags(nil, envMap(map[string]string{"FIREFLY_ALLOW_LOCAL_FILES": "true"}), io.Discard)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !opts.Config.AllowLocalFiles {
+		t.Fatal("expected FIREFLY_ALLOW_LOCAL_FILES to allow local files")
+	}
+}
+
+func TestParseFlagsHistogram(t *testing.T) {
+	opts, err := parseFlags([]string{"-histogram"}, envMap(nil), io.Discard)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !opts.Config.LengthHistogram {
+		t.Fatal("expected -histogram to enable the length histogram")
+	}
+
+	opts, err = parseFlags(nil, envMap(map[string]string{"FIREFLY_HISTOGRAM": "true"}), io.Discard)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !opts.Config.LengthHistogram {
+		t.Fatal("expected FIREFLY_HISTOGRAM to enable the length histogram")
+	}
+}
+
+func TestParseFlagsProgress(t *testing.T) {
+	var stderr bytes.Buffer
+	opts, err := parseFlags([]string{"-progress"}, envMap(nil), &stderr)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if opts.Config.Progress != &stderr {
+		t.Fatal("expected -progress to draw progress on stderr")
+	}
+
+	opts, err = parseFlags(nil, envMap(nil), &stderr)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if opts.Config.Progress != nil {
+		t.Fatal("expected progress to be off by default")
+	}
+}
+
+func TestParseFlagsMaxRuntime(t *testing.T) {
+	opts, err := parseFlags([]string{"-max-runtime", "90s"}, envMap(map[string]string{"FIREFLY_MAX_RUNTIME": "5m"}), io.Discard)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if opts.Config.MaxRuntime != 90*time.Second {
+		t.Fatalf("expected flag to win with 90s, got %v", opts.Config.MaxRuntime)
+	}
+
+	opts, err = parseFlags(nil, envMap(map[string]string{"FIREFLY_MAX_RUNTIME": "5m"}), io.Discard)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if opts.Config.MaxRuntime != 5*time.Minute {
+		t.Fatalf("expected 5m from the environment, got %v", opts.Config.MaxRuntime)
+	}
+}
+
+func TestParseFlagsMaxArticles(t *testing.T) {
+	opts, err := parseFlags([]string{"-max-articles", "100"}, envMap(map[string]string{"FIREFLY_MAX_ARTICLES": "5"}), io.Discard)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if opts.Config.MaxArticles != 100 {
+		t.Fatalf("expected flag to win with 100, got %d", opts.Config.MaxArticles)
+	}
+
+	opts, err = parseFlags(nil, envMap(map[string]string{"FIREFLY_MAX_ARTICLES": "5"}), io.Discard)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if opts.Config.MaxArticles != 5 {
+		t.Fatalf("expected 5 from the environment, got %d", opts.Config.MaxArticles)
+	}
+}
+
+func TestParseFlagsInvalidEnv(t *testing.T) {
+	if _, err := parseFlags(nil, envMap(map[string]string{"FIREFLY_TOP": "many"}), io.Discard); err == nil {
+		t.Fatal("expected error for invalid FIREFLY_TOP")
+	}
+}
@@ -2,31 +2,41 @@ package main
 
 import (
 	"context"
-	"log"
+	"errors"
+	"flag"
 	"os"
-	"path/filepath"
-	"time"
 
 	"github.com/shoresh319/firefly/internal/app"
+	"github.com/shoresh319/firefly/internal/logging"
 	"github.com/shoresh319/firefly/pkg/version"
 )
 
 func main() {
-	log.Printf("starting firefly version=%s commit=%s built_at=%s", version.Version, version.Commit, version.BuiltAt)
+	opts, err := parseFlags(os.Args[1:], os.Getenv, os.Stderr)
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+
+	logger := logging.Default()
+	if err != nil {
+		logger.Error("invalid arguments", "error", err)
+		os.Exit(2)
+	}
+
+	logger.Info("starting firefly", "version", version.Version, "commit", version.Commit, "built_at", version.BuiltAt)
 
 	ctx := context.Background()
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+
+	cfg := opts.Config
+	cfg.Logger = logger
 
-	application := app.New(app.Config{
-		TopWordNum:           10,
-		WordBankPath:         filepath.Join("internal", "assets", "words.txt"),
-		ArticleListPath:      filepath.Join("internal", "assets", "endg-urls.txt"),
-		RetryMax:             10,
-		RetryWaitMin:         10 * time.Second,
-		RetryWaitMax:         5 * time.Minute,
-		ConcurrencyPerDomain: 10,
-	})
-
-	if err := application.Run(ctx, os.Stdout); err != nil {
-		log.Fatalf("firefly execution failed: %v", err)
+	if err := app.New(cfg).Run(ctx, os.Stdout); err != nil {
+		logger.Error("firefly execution failed", "error", err)
+		os.Exit(1)
 	}
 }
@@ -6,7 +6,21 @@ toolchain go1.24.4
 
 require (
 	github.com/hashicorp/go-retryablehttp v0.7.7
+	github.com/prometheus/client_golang v1.20.5
 	golang.org/x/net v0.46.0
+	golang.org/x/text v0.30.0
+	golang.org/x/time v0.12.0
 )
 
-require github.com/hashicorp/go-cleanhttp v0.5.2 // indirect
+require (
+	github.com/beorn7/perks v1.0.1 // indirect
+	github.com/cespare/xxhash/v2 v2.3.0 // indirect
+	github.com/hashicorp/go-cleanhttp v0.5.2 // indirect
+	github.com/klauspost/compress v1.17.9 // indirect
+	github.com/munnerz/goautoneg v0.0.0-20191010083416-a7dc8b61c822 // indirect
+	github.com/prometheus/client_model v0.6.1 // indirect
+	github.com/prometheus/common v0.55.0 // indirect
+	github.com/prometheus/procfs v0.15.1 // indirect
+	golang.org/x/sys v0.37.0 // indirect
+	google.golang.org/protobuf v1.34.2 // indirect
+)
@@ -2,17 +2,25 @@ package app
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"os"
+	"runtime"
 	"time"
 
 	"github.com/shoresh319/firefly/internal/articles"
+	"github.com/shoresh319/firefly/internal/logging"
 	"github.com/shoresh319/firefly/internal/processing"
 	"github.com/shoresh319/firefly/internal/wordbank"
 )
 
+// ErrPartialResult is wrapped, together with the context error, by Run when
+// cancellation or a deadline cut counting short. The counts gathered until
+// then have still been emitted. A MaxRuntime cutoff is not an error.
+var ErrPartialResult = errors.New("counting cut short")
+
 // Config encapsulates runtime configuration for the application.
 type Config struct {
 	WordBankPath    string
@@ -20,14 +28,69 @@ type Config struct {
 	TopWordNum      int
 	HTTPClient      *http.Client
 	WorkerCount     int
+	// WordBankPaths lists further word bank files merged with WordBankPath,
+	// such as domain-specific lists; words in several files count once
+	WordBankPaths []string
 	// Retry configuration for HTTP requests
 	RetryMax     int           // Maximum number of retries (default: 3)
 	RetryWaitMin time.Duration // Minimum wait time between retries (default: 1s)
 	RetryWaitMax time.Duration // Maximum wait time between retries (default: 5s)
+	// PerRequestTimeout bounds each HTTP attempt individually (default: disabled)
+	PerRequestTimeout time.Duration
 	// Concurrency configuration
 	ConcurrencyPerDomain int // Maximum concurrent requests per domain (default: 3)
+	MaxTotalConcurrency  int // Maximum concurrent requests across all domains (default: unlimited)
+	// AdaptiveConcurrency halves a domain's concurrency on each 429 and grows it
+	// back toward ConcurrencyPerDomain after successes (default: disabled)
+	AdaptiveConcurrency bool
+	// RequestsPerSecondPerDomain throttles throughput per domain (default: unlimited)
+	RequestsPerSecondPerDomain float64
+	// ProxyURL routes requests through an http, https or socks5 proxy (default: HTTP_PROXY/HTTPS_PROXY)
+	ProxyURL string
+	// AllowLocalFiles reads file:// URLs and absolute paths in the article list
+	// from disk
+	// (default: disabled, so only http and https URLs are fetched)
+	AllowLocalFiles bool
+	// OutputFormat selects the result encoding: "json" (default), "csv" or "text"
+	OutputFormat string
+	// OutputOrdered makes JSON output an array of {"word","count"} objects
+	// ranked by descending count, then alphabetically, instead of an object
+	OutputOrdered bool
+	// LengthHistogram adds a histogram of distinct valid words per word length,
+	// computed over every counted word rather than just the top words
+	LengthHistogram bool
+	// DryRun loads the word bank and checks every listed URL is a well-formed
+	// http(s) URL, then returns without fetching anything
+	DryRun bool
+	// MaxRuntime is a soft budget for counting: once it elapses no new articles
+	// are started, those in flight finish, and the partial result is written.
+	// A context deadline, by contrast, aborts fetches in flight (default: none)
+	MaxRuntime time.Duration
+	// MaxArticles stops counting once this many articles have been fetched
+	// successfully, cancelling the rest, and writes their counts; failures
+	// don't count toward it (default: no limit)
+	MaxArticles int
+	// Deterministic processes URLs one at a time in list order and retries
+	// without jitter, so logs are reproducible. A debugging aid; it ignores
+	// WorkerCount
+	Deterministic bool
+	// Sink, when set, receives the top words and run statistics in place of
+	// the writer passed to Run. LengthHistogram only affects the default
+	// WriterSink.
+	Sink ResultSink
+	// Progress, when set, receives a progress bar redrawn as articles finish,
+	// typically os.Stderr. The total is known unless URLs come from stdin.
+	Progress io.Writer
+	// Logger receives structured logs from every component (default: JSON to stderr)
+	Logger logging.Logger
 }
 
+// wordBankProgressLines is how often loading a word bank logs progress.
+const wordBankProgressLines = 1_000_000
+
+// StdinPath may be used as ArticleListPath to read URLs from standard input.
+const StdinPath = "-"
+
 // App glues together input sources, processors and outputs.
 type App struct {
 	cfg     Config
@@ -42,6 +105,10 @@ func New(cfg Config) *App {
 		}
 	}
 
+	if cfg.TopWordNum == 0 {
+		cfg.TopWordNum = 10
+	}
+
 	// Set default retry configuration if not provided
 	if cfg.RetryMax == 0 {
 		cfg.RetryMax = 3
@@ -55,48 +122,174 @@ func New(cfg Config) *App {
 	if cfg.ConcurrencyPerDomain == 0 {
 		cfg.ConcurrencyPerDomain = 3
 	}
+	if cfg.Logger == nil {
+		cfg.Logger = logging.Default()
+	}
+	var backoff articles.BackoffStrategy
+	if cfg.Deterministic {
+		backoff = articles.ExponentialBackoff{}
+	}
 
 	return &App{
 		cfg: cfg,
 		fetcher: articles.NewSource(articles.SourceConfig{
-			HTTPClient:           cfg.HTTPClient,
-			RetryMax:             cfg.RetryMax,
-			RetryWaitMin:         cfg.RetryWaitMin,
-			RetryWaitMax:         cfg.RetryWaitMax,
-			ConcurrencyPerDomain: cfg.ConcurrencyPerDomain,
+			HTTPClient:                 cfg.HTTPClient,
+			RetryMax:                   cfg.RetryMax,
+			RetryWaitMin:               cfg.RetryWaitMin,
+			RetryWaitMax:               cfg.RetryWaitMax,
+			Backoff:                    backoff,
+			ConcurrencyPerDomain:       cfg.ConcurrencyPerDomain,
+			MaxTotalConcurrency:        cfg.MaxTotalConcurrency,
+			AdaptiveConcurrency:        cfg.AdaptiveConcurrency,
+			RequestsPerSecondPerDomain: cfg.RequestsPerSecondPerDomain,
+			PerRequestTimeout:          cfg.PerRequestTimeout,
+			ProxyURL:                   cfg.ProxyURL,
+			AllowLocalFiles:            cfg.AllowLocalFiles,
+			Logger:                     cfg.Logger,
 		}),
 	}
 }
 
-// Run executes the application and writes the resulting JSON payload to out.
+// Run executes the application and emits the top words and run statistics
+// to Config.Sink, or, when no sink is set, writes them to out in
+// Config.OutputFormat. If ctx ends first, the partial result is still
+// emitted and Run returns an error wrapping ErrPartialResult.
 func (a *App) Run(ctx context.Context, out io.Writer) error {
-	wordBank, err := wordbank.Load(ctx, a.cfg.WordBankPath)
-	if err != nil {
-		return fmt.Errorf("load word bank from %s: %w", a.cfg.WordBankPath, err)
+	// Cancel on return so the URL list reader stops if counting ended early.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	if !supportedFormat(a.cfg.OutputFormat) {
+		return fmt.Errorf("unsupported output format %q", a.cfg.OutputFormat)
 	}
 
-	urlCh, err := articles.ListFromFile(ctx, a.cfg.ArticleListPath)
+	wordBank, err := a.loadWordBank(ctx)
 	if err != nil {
-		return fmt.Errorf("load article list from %s: %w", a.cfg.ArticleListPath, err)
+		return err
 	}
 
-	validator := wordbank.NewValidator(wordBank)
-	options := []processing.Option{}
+	var urlCh <-chan string
+	var listDone <-chan error
+	if a.cfg.ArticleListPath == StdinPath {
+		urlCh, listDone = articles.ListFromReaderWithDone(ctx, os.Stdin, articles.ListOptions{Logger: a.cfg.Logger})
+	} else {
+		urlCh, listDone, err = articles.ListFromFileWithDone(ctx, a.cfg.ArticleListPath, articles.ListOptions{Logger: a.cfg.Logger})
+		if err != nil {
+			return fmt.Errorf("load article list from %s: %w", a.cfg.ArticleListPath, err)
+		}
+	}
+
+	if a.cfg.DryRun {
+		return a.dryRun(ctx, len(wordBank), urlCh, listDone)
+	}
+
+	validator := wordbank.NewValidator(wordbank.NewWeightedBank(wordBank))
+	options := []processing.Option{processing.WithLogger(a.cfg.Logger)}
 	if a.cfg.WorkerCount > 0 {
 		options = append(options, processing.WithWorkerCount(a.cfg.WorkerCount))
 	}
+	if a.cfg.MaxRuntime > 0 {
+		options = append(options, processing.WithMaxRuntime(a.cfg.MaxRuntime))
+	}
+	if a.cfg.MaxArticles > 0 {
+		options = append(options, processing.WithMaxArticles(a.cfg.MaxArticles))
+	}
+	if a.cfg.Deterministic {
+		options = append(options, processing.WithDeterministic(true))
+	}
+	if a.cfg.Progress != nil {
+		if a.cfg.ArticleListPath != StdinPath {
+			total, err := articles.CountList(ctx, a.cfg.ArticleListPath, articles.ListOptions{Logger: a.cfg.Logger})
+			if err != nil {
+				return fmt.Errorf("count article list %s: %w", a.cfg.ArticleListPath, err)
+			}
+			options = append(options, processing.WithProgressTotal(total))
+		}
+		options = append(options, processing.WithProgress(progressBar(a.cfg.Progress)))
+		defer fmt.Fprintln(a.cfg.Progress)
+	}
 	counter := processing.NewCounter(a.fetcher, validator, options...)
 
-	topCounts, err := counter.CountTopWords(ctx, urlCh, a.cfg.TopWordNum)
-	if err != nil {
-		return fmt.Errorf("count top words: %w", err)
+	sink := a.cfg.Sink
+	writer := NewWriterSink(out, a.cfg.OutputFormat, a.cfg.OutputOrdered)
+	if sink == nil {
+		sink = writer
+	}
+
+	var topCounts map[string]int
+	var stats processing.Stats
+	var countErr error
+	if a.cfg.LengthHistogram {
+		var allCounts map[string]int
+		allCounts, stats, countErr = counter.CountAllWordsWithStats(ctx, urlCh)
+		topCounts = processing.TopWords(allCounts, a.cfg.TopWordNum)
+		writer.histogram = processing.LengthHistogram(allCounts)
+	} else {
+		topCounts, stats, countErr = counter.CountTopWordsWithStats(ctx, urlCh, a.cfg.TopWordNum)
 	}
+	a.warnPartial(countErr)
+	a.checkListDone(listDone)
 
-	encoder := json.NewEncoder(out)
-	encoder.SetIndent("", "  ")
-	if err := encoder.Encode(topCounts); err != nil {
-		return fmt.Errorf("encode result: %w", err)
+	// Emit even if ctx has ended, so a partial result still reaches the sink.
+	if err := sink.Emit(context.WithoutCancel(ctx), topCounts, stats); err != nil {
+		return fmt.Errorf("emit result: %w", err)
 	}
 
+	if countErr != nil {
+		return fmt.Errorf("%w: %w", ErrPartialResult, countErr)
+	}
 	return nil
 }
+
+// warnPartial logs the context error that cut counting short; the counts
+// gathered until then are still written out before Run reports it.
+func (a *App) warnPartial(err error) {
+	if err != nil {
+		a.cfg.Logger.Warn("counting cut short, writing partial results", "error", err)
+	}
+}
+
+// checkListDone logs when counting did not cover the whole article list,
+// either because reading it failed or was cancelled, or because counting
+// stopped before the list was drained.
+func (a *App) checkListDone(listDone <-chan error) {
+	select {
+	case err := <-listDone:
+		if err != nil {
+			a.cfg.Logger.Warn("article list truncated", "path", a.cfg.ArticleListPath, "error", err)
+		}
+	default:
+		a.cfg.Logger.Warn("article list not fully read", "path", a.cfg.ArticleListPath)
+	}
+}
+
+// loadWordBank loads WordBankPath, merging in WordBankPaths when present, and
+// returns each word's weight: 1 unless set by a CSV bank.
+func (a *App) loadWordBank(ctx context.Context) (map[string]int, error) {
+	opts := []wordbank.LoadOption{
+		wordbank.WithLoadProgress(wordBankProgressLines, func(lines int) {
+			a.cfg.Logger.Info("loading word bank", "lines", lines)
+		}),
+		wordbank.WithLoadConcurrency(runtime.GOMAXPROCS(0)),
+	}
+	if len(a.cfg.WordBankPaths) == 0 {
+		words, err := wordbank.LoadWeighted(ctx, a.cfg.WordBankPath, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("load word bank from %s: %w", a.cfg.WordBankPath, err)
+		}
+		return words, nil
+	}
+
+	var paths []string
+	if a.cfg.WordBankPath != "" {
+		paths = append(paths, a.cfg.WordBankPath)
+	}
+	paths = append(paths, a.cfg.WordBankPaths...)
+
+	words, stats, err := wordbank.LoadAllWeightedWithStats(ctx, paths, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("load word banks: %w", err)
+	}
+	a.cfg.Logger.Info("merged word banks", "files", stats.Files, "total", stats.Total, "unique", stats.Unique)
+	return words, nil
+}
@@ -0,0 +1,55 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// maxListedInvalidURLs caps how many invalid URLs a dry-run error spells out.
+const maxListedInvalidURLs = 10
+
+// dryRun drains urlCh and checks every entry is an absolute http or https URL.
+// It logs a summary and returns an error naming the invalid entries, if any,
+// or the error that stopped the list from being read in full.
+func (a *App) dryRun(ctx context.Context, wordCount int, urlCh <-chan string, listDone <-chan error) error {
+	var valid int
+	var invalid []string
+	for rawURL := range urlCh {
+		if validArticleURL(rawURL) {
+			valid++
+		} else {
+			invalid = append(invalid, rawURL)
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := <-listDone; err != nil {
+		return err
+	}
+
+	a.cfg.Logger.Info("dry run complete", "words", wordCount, "valid_urls", valid, "invalid_urls", len(invalid))
+
+	if len(invalid) == 0 {
+		return nil
+	}
+	listed := invalid
+	if len(listed) > maxListedInvalidURLs {
+		listed = listed[:maxListedInvalidURLs]
+	}
+	msg := strings.Join(listed, ", ")
+	if extra := len(invalid) - len(listed); extra > 0 {
+		msg += fmt.Sprintf(" and %d more", extra)
+	}
+	return fmt.Errorf("dry run found %d invalid URLs: %s", len(invalid), msg)
+}
+
+func validArticleURL(rawURL string) bool {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	return (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Host != ""
+}
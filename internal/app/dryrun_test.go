@@ -0,0 +1,96 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/shoresh319/firefly/internal/logging"
+)
+
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write %s: %v", name, err)
+	}
+	return path
+}
+
+func TestRunDryRun(t *testing.T) {
+	var hits int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	urls := strings.Join([]string{
+		srv.URL + "/one",
+		"https://example.com/two",
+		"ftp://example.com/file",
+		"not a url",
+		"http://%zz",
+	}, "\n")
+
+	var out bytes.Buffer
+	err := New(Config{
+		WordBankPath:    writeFile(t, dir, "words.txt", "apple\nbanana\n"),
+		ArticleListPath: writeFile(t, dir, "urls.txt", urls),
+		DryRun:          true,
+		Logger:          logging.Nop(),
+	}).Run(context.Background(), &out)
+
+	if err == nil {
+		t.Fatal("expected error listing invalid URLs")
+	}
+	for _, want := range []string{"3 invalid URLs", "ftp://example.com/file", "not a url", "http://%zz"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Fatalf("expected error to mention %q, got %v", want, err)
+		}
+	}
+	if strings.Contains(err.Error(), "example.com/two") {
+		t.Fatalf("expected valid URLs to be omitted, got %v", err)
+	}
+	if n := atomic.LoadInt32(&hits); n != 0 {
+		t.Fatalf("expected no fetches in dry-run mode, got %d", n)
+	}
+	if out.Len() != 0 {
+		t.Fatalf("expected no result output, got %q", out.String())
+	}
+}
+
+func TestRunDryRunAllValid(t *testing.T) {
+	dir := t.TempDir()
+	err := New(Config{
+		WordBankPath:    writeFile(t, dir, "words.txt", "apple\n"),
+		ArticleListPath: writeFile(t, dir, "urls.txt", "https://example.com/a\nhttp://example.org/b\n"),
+		DryRun:          true,
+		Logger:          logging.Nop(),
+	}).Run(context.Background(), &bytes.Buffer{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestRunDryRunReportsUnreadableList(t *testing.T) {
+	dir := t.TempDir()
+	tooLong := "https://example.com/" + strings.Repeat("a", 2*1024*1024)
+	err := New(Config{
+		WordBankPath:    writeFile(t, dir, "words.txt", "apple\n"),
+		ArticleListPath: writeFile(t, dir, "urls.txt", "https://example.com/a\n"+tooLong+"\n"),
+		DryRun:          true,
+		Logger:          logging.Nop(),
+	}).Run(context.Background(), io.Discard)
+
+	if err == nil || !strings.Contains(err.Error(), "read article list") {
+		t.Fatalf("expected article list read error, got %v", err)
+	}
+}
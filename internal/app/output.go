@@ -0,0 +1,132 @@
+package app
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+)
+
+// Supported values for Config.OutputFormat.
+const (
+	FormatJSON = "json"
+	FormatCSV  = "csv"
+	FormatText = "text"
+)
+
+func supportedFormat(format string) bool {
+	switch format {
+	case "", FormatJSON, FormatCSV, FormatText:
+		return true
+	default:
+		return false
+	}
+}
+
+type wordCount struct {
+	Word  string `json:"word"`
+	Count int    `json:"count"`
+}
+
+// sortedCounts orders counts by descending count, breaking ties alphabetically.
+func sortedCounts(counts map[string]int) []wordCount {
+	pairs := make([]wordCount, 0, len(counts))
+	for word, count := range counts {
+		pairs = append(pairs, wordCount{Word: word, Count: count})
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i].Count != pairs[j].Count {
+			return pairs[i].Count > pairs[j].Count
+		}
+		return pairs[i].Word < pairs[j].Word
+	})
+	return pairs
+}
+
+// sortedLengths returns the lengths present in histogram in ascending order.
+func sortedLengths(histogram map[int]int) []int {
+	lengths := make([]int, 0, len(histogram))
+	for length := range histogram {
+		lengths = append(lengths, length)
+	}
+	sort.Ints(lengths)
+	return lengths
+}
+
+// resultWithHistogram is the JSON layout used when a histogram is requested.
+// TopWords holds either the counts map or, when ordered, a []wordCount.
+type resultWithHistogram struct {
+	TopWords        any         `json:"topWords"`
+	LengthHistogram map[int]int `json:"lengthHistogram"`
+}
+
+// encodeResult writes counts to out in the requested format. An empty format
+// selects JSON, which is an object keyed by word unless ordered is set, in
+// which case it is an array of {"word","count"} objects ranked like the CSV
+// and text output. A non-nil histogram is written after the counts: JSON nests
+// both under one object, while CSV and text append a second section separated
+// by a blank line.
+func encodeResult(out io.Writer, format string, ordered bool, counts map[string]int, histogram map[int]int) error {
+	switch format {
+	case "", FormatJSON:
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		var topWords any = counts
+		if ordered {
+			topWords = sortedCounts(counts)
+		}
+		if histogram != nil {
+			return encoder.Encode(resultWithHistogram{TopWords: topWords, LengthHistogram: histogram})
+		}
+		return encoder.Encode(topWords)
+	case FormatCSV:
+		w := csv.NewWriter(out)
+		if err := w.Write([]string{"word", "count"}); err != nil {
+			return err
+		}
+		for _, pair := range sortedCounts(counts) {
+			if err := w.Write([]string{pair.Word, strconv.Itoa(pair.Count)}); err != nil {
+				return err
+			}
+		}
+		w.Flush()
+		if err := w.Error(); err != nil || histogram == nil {
+			return err
+		}
+		if _, err := io.WriteString(out, "\n"); err != nil {
+			return err
+		}
+		if err := w.Write([]string{"length", "words"}); err != nil {
+			return err
+		}
+		for _, length := range sortedLengths(histogram) {
+			if err := w.Write([]string{strconv.Itoa(length), strconv.Itoa(histogram[length])}); err != nil {
+				return err
+			}
+		}
+		w.Flush()
+		return w.Error()
+	case FormatText:
+		for _, pair := range sortedCounts(counts) {
+			if _, err := fmt.Fprintf(out, "%s: %d\n", pair.Word, pair.Count); err != nil {
+				return err
+			}
+		}
+		if histogram == nil {
+			return nil
+		}
+		if _, err := io.WriteString(out, "\n"); err != nil {
+			return err
+		}
+		for _, length := range sortedLengths(histogram) {
+			if _, err := fmt.Fprintf(out, "length %d: %d\n", length, histogram[length]); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported output format %q", format)
+	}
+}
@@ -0,0 +1,133 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+var sampleCounts = map[string]int{"banana": 2, "apple": 5, "cherry": 2, "date": 1}
+
+func TestEncodeResultJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := encodeResult(&buf, FormatJSON, false, sampleCounts, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var got map[string]int
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("failed to unmarshal output: %v", err)
+	}
+	if len(got) != len(sampleCounts) || got["apple"] != 5 {
+		t.Fatalf("expected %v, got %v", sampleCounts, got)
+	}
+}
+
+func TestEncodeResultCSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := encodeResult(&buf, FormatCSV, false, sampleCounts, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := "word,count\napple,5\nbanana,2\ncherry,2\ndate,1\n"
+	if buf.String() != want {
+		t.Fatalf("expected %q, got %q", want, buf.String())
+	}
+}
+
+func TestEncodeResultText(t *testing.T) {
+	var buf bytes.Buffer
+	if err := encodeResult(&buf, FormatText, false, sampleCounts, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := "apple: 5\nbanana: 2\ncherry: 2\ndate: 1\n"
+	if buf.String() != want {
+		t.Fatalf("expected %q, got %q", want, buf.String())
+	}
+}
+
+func TestEncodeResultUnsupported(t *testing.T) {
+	if err := encodeResult(&bytes.Buffer{}, "xml", false, sampleCounts, nil); err == nil {
+		t.Fatal("expected error for unsupported format")
+	}
+}
+
+func TestEncodeResultJSONOrdered(t *testing.T) {
+	var buf bytes.Buffer
+	if err := encodeResult(&buf, FormatJSON, true, sampleCounts, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var got []wordCount
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("failed to unmarshal output: %v", err)
+	}
+	want := []wordCount{{"apple", 5}, {"banana", 2}, {"cherry", 2}, {"date", 1}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+}
+
+func TestEncodeResultJSONOrderedWithHistogram(t *testing.T) {
+	var buf bytes.Buffer
+	if err := encodeResult(&buf, FormatJSON, true, sampleCounts, sampleHistogram); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var got struct {
+		TopWords        []wordCount `json:"topWords"`
+		LengthHistogram map[int]int `json:"lengthHistogram"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("failed to unmarshal output: %v", err)
+	}
+	if len(got.TopWords) != 4 || got.TopWords[0] != (wordCount{"apple", 5}) || got.LengthHistogram[6] != 2 {
+		t.Fatalf("unexpected output %+v", got)
+	}
+}
+
+var sampleHistogram = map[int]int{4: 1, 5: 1, 6: 2}
+
+func TestEncodeResultJSONWithHistogram(t *testing.T) {
+	var buf bytes.Buffer
+	if err := encodeResult(&buf, FormatJSON, false, sampleCounts, sampleHistogram); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var got struct {
+		TopWords        map[string]int `json:"topWords"`
+		LengthHistogram map[int]int    `json:"lengthHistogram"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("failed to unmarshal output: %v", err)
+	}
+	if got.TopWords["apple"] != 5 || got.LengthHistogram[6] != 2 || len(got.LengthHistogram) != 3 {
+		t.Fatalf("unexpected output %+v", got)
+	}
+}
+
+func TestEncodeResultCSVWithHistogram(t *testing.T) {
+	var buf bytes.Buffer
+	if err := encodeResult(&buf, FormatCSV, false, sampleCounts, sampleHistogram); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := "word,count\napple,5\nbanana,2\ncherry,2\ndate,1\n\nlength,words\n4,1\n5,1\n6,2\n"
+	if buf.String() != want {
+		t.Fatalf("expected %q, got %q", want, buf.String())
+	}
+}
+
+func TestEncodeResultTextWithHistogram(t *testing.T) {
+	var buf bytes.Buffer
+	if err := encodeResult(&buf, FormatText, false, sampleCounts, sampleHistogram); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := "apple: 5\nbanana: 2\ncherry: 2\ndate: 1\n\nlength 4: 1\nlength 5: 1\nlength 6: 2\n"
+	if buf.String() != want {
+		t.Fatalf("expected %q, got %q", want, buf.String())
+	}
+}
@@ -0,0 +1,135 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/shoresh319/firefly/internal/processing"
+)
+
+// otherPartition names the partition of words that do not start with a letter.
+const otherPartition = "other"
+
+// PartitionedSink writes results to one file per first letter in a directory,
+// map-reduce style: "apple" goes to a.json, "Zebra" to z.json, and words
+// starting with anything but a letter, such as "42nd", to other.json. Each
+// file holds its partition's counts encoded and sorted as WriterSink would.
+type PartitionedSink struct {
+	dir     string
+	format  string
+	ordered bool
+}
+
+// NewPartitionedSink returns a sink writing partitions into dir, which is
+// created if needed. format and ordered select the encoding as for
+// NewWriterSink, and the file extension follows format (.json, .csv or .txt).
+// Each Emit replaces the partition files of format already in dir, removing
+// those for letters absent from the run; other files are left alone.
+func NewPartitionedSink(dir, format string, ordered bool) *PartitionedSink {
+	return &PartitionedSink{dir: dir, format: format, ordered: ordered}
+}
+
+// Emit writes each partition of counts to its own file; stats are not part of
+// the output.
+func (s *PartitionedSink) Emit(_ context.Context, counts map[string]int, _ processing.Stats) error {
+	if !supportedFormat(s.format) {
+		return fmt.Errorf("unsupported output format %q", s.format)
+	}
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return fmt.Errorf("create output directory: %w", err)
+	}
+
+	partitions := make(map[string]map[string]int)
+	for word, n := range counts {
+		key := partitionKey(word)
+		if partitions[key] == nil {
+			partitions[key] = make(map[string]int)
+		}
+		partitions[key][word] = n
+	}
+
+	var errs []error
+	if err := s.removeStale(partitions); err != nil {
+		errs = append(errs, err)
+	}
+	for key, part := range partitions {
+		if err := s.writePartition(filepath.Join(s.dir, key+s.extension()), part); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// removeStale deletes partition files of s.format in s.dir, left by earlier
+// runs, whose letters are not among partitions.
+func (s *PartitionedSink) removeStale(partitions map[string]map[string]int) error {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return fmt.Errorf("read output directory: %w", err)
+	}
+	var errs []error
+	for _, entry := range entries {
+		key, ok := strings.CutSuffix(entry.Name(), s.extension())
+		if !ok || !entry.Type().IsRegular() || !isPartitionKey(key) {
+			continue
+		}
+		if _, current := partitions[key]; current {
+			continue
+		}
+		if err := os.Remove(filepath.Join(s.dir, entry.Name())); err != nil {
+			errs = append(errs, fmt.Errorf("remove stale partition: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (s *PartitionedSink) writePartition(path string, counts map[string]int) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("create partition: %w", err)
+	}
+	if err := encodeResult(f, s.format, s.ordered, counts, nil); err != nil {
+		f.Close()
+		return fmt.Errorf("write partition %s: %w", path, err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("write partition %s: %w", path, err)
+	}
+	return nil
+}
+
+func (s *PartitionedSink) extension() string {
+	switch s.format {
+	case FormatCSV:
+		return ".csv"
+	case FormatText:
+		return ".txt"
+	default:
+		return ".json"
+	}
+}
+
+// partitionKey returns the lowercased first letter of word, or otherPartition
+// when word does not start with a letter.
+func partitionKey(word string) string {
+	r, _ := utf8.DecodeRuneInString(word)
+	if !unicode.IsLetter(r) {
+		return otherPartition
+	}
+	return string(unicode.ToLower(r))
+}
+
+// isPartitionKey reports whether key could be returned by partitionKey.
+func isPartitionKey(key string) bool {
+	if key == otherPartition {
+		return true
+	}
+	r, size := utf8.DecodeRuneInString(key)
+	return size == len(key) && unicode.IsLetter(r) && unicode.ToLower(r) == r
+}
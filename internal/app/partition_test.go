@@ -0,0 +1,108 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/shoresh319/firefly/internal/processing"
+)
+
+func TestPartitionedSinkSplitsByFirstLetter(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "out")
+	counts := map[string]int{
+		"apple": 5, "avocado": 2, "Banana": 3, "berry": 1,
+		"élan": 4, "42nd": 6, "_id": 1,
+	}
+
+	if err := NewPartitionedSink(dir, "", false).Emit(context.Background(), counts, processing.Stats{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := map[string]map[string]int{
+		"a.json":     {"apple": 5, "avocado": 2},
+		"b.json":     {"Banana": 3, "berry": 1},
+		"é.json":     {"élan": 4},
+		"other.json": {"42nd": 6, "_id": 1},
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("read output directory: %v", err)
+	}
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	wantNames := make([]string, 0, len(want))
+	for name := range want {
+		wantNames = append(wantNames, name)
+	}
+	sort.Strings(wantNames)
+	if !reflect.DeepEqual(names, wantNames) {
+		t.Fatalf("expected files %v, got %v", wantNames, names)
+	}
+
+	for name, wantCounts := range want {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("read %s: %v", name, err)
+		}
+		var got map[string]int
+		if err := json.Unmarshal(data, &got); err != nil {
+			t.Fatalf("unmarshal %s: %v", name, err)
+		}
+		if !reflect.DeepEqual(got, wantCounts) {
+			t.Fatalf("expected %s to hold %v, got %v", name, wantCounts, got)
+		}
+	}
+}
+
+func TestPartitionedSinkRemovesStalePartitions(t *testing.T) {
+	dir := t.TempDir()
+	sink := NewPartitionedSink(dir, "", false)
+	if err := sink.Emit(context.Background(), map[string]int{"apple": 1, "42nd": 2}, processing.Stats{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// Files that are not partitions of this format must survive.
+	for _, name := range []string{"a.csv", "notes.json", "ab.json"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("keep"), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+
+	if err := sink.Emit(context.Background(), map[string]int{"banana": 3}, processing.Stats{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("read output directory: %v", err)
+	}
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	if want := []string{"a.csv", "ab.json", "b.json", "notes.json"}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("expected files %v, got %v", want, names)
+	}
+}
+
+func TestPartitionedSinkSortsCSVPartitions(t *testing.T) {
+	dir := t.TempDir()
+	counts := map[string]int{"cherry": 1, "cat": 3, "cab": 3}
+
+	if err := NewPartitionedSink(dir, FormatCSV, false).Emit(context.Background(), counts, processing.Stats{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "c.csv"))
+	if err != nil {
+		t.Fatalf("read partition: %v", err)
+	}
+	if want := "word,count\ncab,3\ncat,3\ncherry,1\n"; string(data) != want {
+		t.Fatalf("expected %q, got %q", want, data)
+	}
+}
@@ -0,0 +1,27 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+const progressBarWidth = 30
+
+// progressBar returns a processing.WithProgress callback that redraws a single
+// status line on w. Without a known total it shows only the finished count.
+func progressBar(w io.Writer) func(done, total int) {
+	return func(done, total int) {
+		if total <= 0 {
+			fmt.Fprintf(w, "\r%d articles", done)
+			return
+		}
+		if done > total {
+			done = total
+		}
+		filled := progressBarWidth * done / total
+		fmt.Fprintf(w, "\r[%s%s] %d/%d (%d%%)",
+			strings.Repeat("#", filled), strings.Repeat(" ", progressBarWidth-filled),
+			done, total, 100*done/total)
+	}
+}
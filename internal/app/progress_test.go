@@ -0,0 +1,59 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/shoresh319/firefly/internal/logging"
+)
+
+func TestProgressBar(t *testing.T) {
+	var buf bytes.Buffer
+	render := progressBar(&buf)
+
+	render(1, 4)
+	if want := "\r[#######                       ] 1/4 (25%)"; buf.String() != want {
+		t.Fatalf("expected %q, got %q", want, buf.String())
+	}
+
+	buf.Reset()
+	render(7, 0)
+	if want := "\r7 articles"; buf.String() != want {
+		t.Fatalf("expected %q, got %q", want, buf.String())
+	}
+}
+
+func TestRunReportsProgress(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("<html><body><p>apple banana</p></body></html>"))
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	urls := strings.Join([]string{srv.URL + "/one", srv.URL + "/two", srv.URL + "/three"}, "\n")
+
+	var out, progress bytes.Buffer
+	err := New(Config{
+		WordBankPath:    writeFile(t, dir, "words.txt", "apple\nbanana\n"),
+		ArticleListPath: writeFile(t, dir, "urls.txt", urls),
+		Progress:        &progress,
+		Logger:          logging.Nop(),
+	}).Run(context.Background(), &out)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := strings.Count(progress.String(), "\r"); got != 3 {
+		t.Fatalf("expected 3 progress updates, got %d in %q", got, progress.String())
+	}
+	if !strings.HasSuffix(progress.String(), "3/3 (100%)\n") {
+		t.Fatalf("expected progress to end at 3/3, got %q", progress.String())
+	}
+	if !strings.Contains(out.String(), "apple") {
+		t.Fatalf("expected result output, got %q", out.String())
+	}
+}
@@ -0,0 +1,86 @@
+package app
+
+import (
+	"net/url"
+	"time"
+)
+
+// PublicConfig is the JSON view of a Config reported by GET /config. It leaves
+// out the HTTP client, logger, sink and progress writer, and redacts any
+// password in ProxyURL. Durations are Go duration strings such as "5s".
+type PublicConfig struct {
+	WordBankPath               string   `json:"wordBankPath"`
+	WordBankPaths              []string `json:"wordBankPaths,omitempty"`
+	ArticleListPath            string   `json:"articleListPath"`
+	TopN                       int      `json:"topN"`
+	Workers                    int      `json:"workers"`
+	HTTPTimeout                string   `json:"httpTimeout"`
+	RetryMax                   int      `json:"retryMax"`
+	RetryWaitMin               string   `json:"retryWaitMin"`
+	RetryWaitMax               string   `json:"retryWaitMax"`
+	PerRequestTimeout          string   `json:"perRequestTimeout"`
+	ConcurrencyPerDomain       int      `json:"concurrencyPerDomain"`
+	MaxTotalConcurrency        int      `json:"maxTotalConcurrency"`
+	AdaptiveConcurrency        bool     `json:"adaptiveConcurrency"`
+	RequestsPerSecondPerDomain float64  `json:"requestsPerSecondPerDomain"`
+	ProxyURL                   string   `json:"proxyURL,omitempty"`
+	AllowLocalFiles            bool     `json:"allowLocalFiles"`
+	OutputFormat               string   `json:"outputFormat"`
+	OutputOrdered              bool     `json:"outputOrdered"`
+	LengthHistogram            bool     `json:"lengthHistogram"`
+	DryRun                     bool     `json:"dryRun"`
+	MaxRuntime                 string   `json:"maxRuntime"`
+	MaxArticles                int      `json:"maxArticles"`
+	Deterministic              bool     `json:"deterministic"`
+}
+
+// Public returns the parts of c that are safe to expose.
+func (c Config) Public() PublicConfig {
+	var httpTimeout time.Duration
+	if c.HTTPClient != nil {
+		httpTimeout = c.HTTPClient.Timeout
+	}
+	return PublicConfig{
+		WordBankPath:               c.WordBankPath,
+		WordBankPaths:              c.WordBankPaths,
+		ArticleListPath:            c.ArticleListPath,
+		TopN:                       c.TopWordNum,
+		Workers:                    c.WorkerCount,
+		HTTPTimeout:                httpTimeout.String(),
+		RetryMax:                   c.RetryMax,
+		RetryWaitMin:               c.RetryWaitMin.String(),
+		RetryWaitMax:               c.RetryWaitMax.String(),
+		PerRequestTimeout:          c.PerRequestTimeout.String(),
+		ConcurrencyPerDomain:       c.ConcurrencyPerDomain,
+		MaxTotalConcurrency:        c.MaxTotalConcurrency,
+		AdaptiveConcurrency:        c.AdaptiveConcurrency,
+		RequestsPerSecondPerDomain: c.RequestsPerSecondPerDomain,
+		ProxyURL:                   redactURL(c.ProxyURL),
+		AllowLocalFiles:            c.AllowLocalFiles,
+		OutputFormat:               c.OutputFormat,
+		OutputOrdered:              c.OutputOrdered,
+		LengthHistogram:            c.LengthHistogram,
+		DryRun:                     c.DryRun,
+		MaxRuntime:                 c.MaxRuntime.String(),
+		MaxArticles:                c.MaxArticles,
+		Deterministic:              c.Deterministic,
+	}
+}
+
+// Config returns the configuration the App runs with, defaults included.
+func (a *App) Config() Config {
+	return a.cfg
+}
+
+// redactURL masks the password in rawURL. A URL that does not parse is
+// dropped entirely, since it may still hold credentials.
+func redactURL(rawURL string) string {
+	if rawURL == "" {
+		return ""
+	}
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return "[invalid URL]"
+	}
+	return parsed.Redacted()
+}
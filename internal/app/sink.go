@@ -0,0 +1,37 @@
+package app
+
+import (
+	"context"
+	"io"
+
+	"github.com/shoresh319/firefly/internal/processing"
+)
+
+// ResultSink receives the outcome of a run, such as to store it in a database
+// or publish it to a queue instead of printing it. counts holds the top words.
+type ResultSink interface {
+	Emit(ctx context.Context, counts map[string]int, stats processing.Stats) error
+}
+
+// WriterSink encodes results to an io.Writer in one of the OutputFormat
+// encodings. It is the sink Run uses when Config.Sink is nil.
+type WriterSink struct {
+	out     io.Writer
+	format  string
+	ordered bool
+	// histogram is appended to the output when set; Run fills it in for
+	// Config.LengthHistogram.
+	histogram map[int]int
+}
+
+// NewWriterSink returns a sink writing to out in format ("json", "csv" or
+// "text"; empty means JSON). ordered selects ranked JSON arrays, as
+// Config.OutputOrdered does.
+func NewWriterSink(out io.Writer, format string, ordered bool) *WriterSink {
+	return &WriterSink{out: out, format: format, ordered: ordered}
+}
+
+// Emit writes counts; stats are not part of the output.
+func (s *WriterSink) Emit(_ context.Context, counts map[string]int, _ processing.Stats) error {
+	return encodeResult(s.out, s.format, s.ordered, counts, s.histogram)
+}
@@ -0,0 +1,127 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shoresh319/firefly/internal/logging"
+	"github.com/shoresh319/firefly/internal/processing"
+)
+
+// recordingSink keeps every result it is given.
+type recordingSink struct {
+	counts []map[string]int
+	stats  []processing.Stats
+	err    error
+}
+
+func (s *recordingSink) Emit(_ context.Context, counts map[string]int, stats processing.Stats) error {
+	s.counts = append(s.counts, counts)
+	s.stats = append(s.stats, stats)
+	return s.err
+}
+
+func TestRunEmitsToSink(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte("<html><body><p>apple banana apple cherry</p></body></html>"))
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	urls := strings.Join([]string{srv.URL + "/one", srv.URL + "/two", srv.URL + "/missing"}, "\n")
+
+	sink := &recordingSink{}
+	var out bytes.Buffer
+	err := New(Config{
+		WordBankPath:    writeFile(t, dir, "words.txt", "apple\nbanana\n"),
+		ArticleListPath: writeFile(t, dir, "urls.txt", urls),
+		TopWordNum:      5,
+		Sink:            sink,
+		Logger:          logging.Nop(),
+	}).Run(context.Background(), &out)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(sink.counts) != 1 {
+		t.Fatalf("expected one emitted result, got %d", len(sink.counts))
+	}
+	if want := map[string]int{"apple": 4, "banana": 2}; !reflect.DeepEqual(sink.counts[0], want) {
+		t.Fatalf("expected counts %v, got %v", want, sink.counts[0])
+	}
+	stats := sink.stats[0]
+	if stats.Successes != 2 || stats.Failures != 1 || stats.TotalTokens != 6 {
+		t.Fatalf("expected 2 successes, 1 failure and 6 tokens, got %+v", stats)
+	}
+	if out.Len() != 0 {
+		t.Fatalf("expected nothing written to out when a sink is set, got %q", out.String())
+	}
+}
+
+func TestRunReportsSinkError(t *testing.T) {
+	dir := t.TempDir()
+	sink := &recordingSink{err: errors.New("queue unavailable")}
+	err := New(Config{
+		WordBankPath:    writeFile(t, dir, "words.txt", "apple\n"),
+		ArticleListPath: writeFile(t, dir, "urls.txt", ""),
+		Sink:            sink,
+		Logger:          logging.Nop(),
+	}).Run(context.Background(), &bytes.Buffer{})
+
+	if err == nil || !strings.Contains(err.Error(), "queue unavailable") {
+		t.Fatalf("expected sink error, got %v", err)
+	}
+}
+
+func TestRunReportsDeadlineAfterEmitting(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	sink := &recordingSink{}
+	err := New(Config{
+		WordBankPath:    writeFile(t, dir, "words.txt", "apple\n"),
+		ArticleListPath: writeFile(t, dir, "urls.txt", srv.URL+"/slow"),
+		Sink:            sink,
+		Logger:          logging.Nop(),
+	}).Run(ctx, &bytes.Buffer{})
+
+	if !errors.Is(err, ErrPartialResult) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected ErrPartialResult wrapping context.DeadlineExceeded, got %v", err)
+	}
+	if len(sink.counts) != 1 {
+		t.Fatalf("expected the partial result to be emitted once, got %d", len(sink.counts))
+	}
+}
+
+func TestWriterSink(t *testing.T) {
+	var buf bytes.Buffer
+	sink := NewWriterSink(&buf, FormatText, false)
+	if err := sink.Emit(context.Background(), sampleCounts, processing.Stats{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var want bytes.Buffer
+	if err := encodeResult(&want, FormatText, false, sampleCounts, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if buf.String() != want.String() {
+		t.Fatalf("expected %q, got %q", want.String(), buf.String())
+	}
+}
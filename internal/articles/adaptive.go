@@ -0,0 +1,119 @@
+package articles
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"sync"
+)
+
+// adaptiveDecrease is the factor a domain's concurrency limit is multiplied by
+// on each 429.
+const adaptiveDecrease = 0.5
+
+// concurrencyController is an AIMD controller of per-domain concurrency. Each
+// domain starts at max concurrent requests; every 429 halves its limit, down
+// to one, and every other response adds 1/limit, so the limit grows back by
+// roughly one per limit successes until it reaches max again. A nil
+// *concurrencyController leaves concurrency to the static per-domain
+// semaphores.
+type concurrencyController struct {
+	max int
+
+	mu      sync.Mutex
+	domains map[string]*adaptiveState
+}
+
+type adaptiveState struct {
+	limit    float64
+	inFlight int
+	wake     chan struct{} // Closed and replaced whenever a slot may have freed
+}
+
+func newConcurrencyController(enabled bool, max int) *concurrencyController {
+	if !enabled {
+		return nil
+	}
+	return &concurrencyController{max: max, domains: make(map[string]*adaptiveState)}
+}
+
+// state returns domain's state, creating it at full concurrency. Callers hold
+// c.mu.
+func (c *concurrencyController) state(domain string) *adaptiveState {
+	st, ok := c.domains[domain]
+	if !ok {
+		st = &adaptiveState{limit: float64(c.max), wake: make(chan struct{})}
+		c.domains[domain] = st
+	}
+	return st
+}
+
+// effective is the number of requests st currently admits at once.
+func (st *adaptiveState) effective() int {
+	return int(math.Floor(st.limit))
+}
+
+// broadcast wakes every fetch waiting on st. Callers hold c.mu.
+func (st *adaptiveState) broadcast() {
+	close(st.wake)
+	st.wake = make(chan struct{})
+}
+
+// acquire waits until domain is below its current limit and takes a slot. The
+// returned func gives it back.
+func (c *concurrencyController) acquire(ctx context.Context, domain string) (func(), error) {
+	for {
+		c.mu.Lock()
+		st := c.state(domain)
+		if st.inFlight < st.effective() {
+			st.inFlight++
+			c.mu.Unlock()
+			return func() { c.release(st) }, nil
+		}
+		wake := st.wake
+		c.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-wake:
+		}
+	}
+}
+
+func (c *concurrencyController) release(st *adaptiveState) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	st.inFlight--
+	st.broadcast()
+}
+
+// record adjusts domain's limit after a response with status code and returns
+// its new effective concurrency.
+func (c *concurrencyController) record(domain string, code int) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	st := c.state(domain)
+	before := st.effective()
+	if code == http.StatusTooManyRequests {
+		st.limit = math.Max(1, st.limit*adaptiveDecrease)
+	} else {
+		st.limit = math.Min(float64(c.max), st.limit+1/st.limit)
+	}
+	if st.effective() > before {
+		st.broadcast()
+	}
+	return st.effective()
+}
+
+// limits returns each domain's current effective concurrency.
+func (c *concurrencyController) limits() map[string]int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	limits := make(map[string]int, len(c.domains))
+	for domain, st := range c.domains {
+		limits[domain] = st.effective()
+	}
+	return limits
+}
@@ -0,0 +1,111 @@
+package articles
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/shoresh319/firefly/internal/logging"
+)
+
+func TestAdaptiveConcurrencyBacksOffAndRecovers(t *testing.T) {
+	var throttle atomic.Bool
+	var inFlight, peak atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(2 * time.Millisecond)
+		if throttle.Load() {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		_, _ = w.Write([]byte(testHTML))
+	}))
+	defer server.Close()
+	u, _ := url.Parse(server.URL)
+	host := u.Hostname()
+
+	source := NewSource(SourceConfig{
+		Logger:               logging.Nop(),
+		ConcurrencyPerDomain: 8,
+		AdaptiveConcurrency:  true,
+	})
+	fetchAll := func(n int) {
+		var wg sync.WaitGroup
+		for i := 0; i < n; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, _ = source.Fetch(context.Background(), server.URL)
+			}()
+		}
+		wg.Wait()
+	}
+
+	throttle.Store(true)
+	fetchAll(8)
+	if got := source.DomainConcurrency()[host]; got != 1 {
+		t.Fatalf("expected a burst of 429s to cut concurrency to 1, got %d", got)
+	}
+
+	peak.Store(0)
+	throttle.Store(false)
+	fetchAll(8)
+	if p := peak.Load(); p > 3 {
+		t.Fatalf("expected concurrency to grow back gradually, got %d requests at once", p)
+	}
+
+	for i := 0; i < 20 && source.DomainConcurrency()[host] < 8; i++ {
+		fetchAll(8)
+	}
+	if got := source.DomainConcurrency()[host]; got != 8 {
+		t.Fatalf("expected sustained successes to restore concurrency to 8, got %d", got)
+	}
+}
+
+func TestConcurrencyControllerWaitsForSlot(t *testing.T) {
+	c := newConcurrencyController(true, 2)
+	c.record("example.com", http.StatusTooManyRequests)
+
+	release, err := c.acquire(context.Background(), "example.com")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := c.acquire(ctx, "example.com"); err != context.DeadlineExceeded {
+		t.Fatalf("expected the second fetch to wait past its deadline, got %v", err)
+	}
+
+	release()
+	release, err = c.acquire(context.Background(), "example.com")
+	if err != nil {
+		t.Fatalf("expected a freed slot to be reused, got %v", err)
+	}
+	release()
+}
+
+func TestDomainConcurrencyStatic(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+	u, _ := url.Parse(server.URL)
+
+	source := NewSource(SourceConfig{Logger: logging.Nop(), ConcurrencyPerDomain: 4})
+	_, _ = source.Fetch(context.Background(), server.URL)
+	if got := source.DomainConcurrency()[u.Hostname()]; got != 4 {
+		t.Fatalf("expected the static limit of 4 without AdaptiveConcurrency, got %d", got)
+	}
+}
@@ -0,0 +1,95 @@
+package articles
+
+import (
+	"context"
+	"math/rand/v2"
+	"net/http"
+	"time"
+)
+
+// BackoffStrategy computes the wait before retry attemptNum, counted from 0,
+// given the configured RetryWaitMin and RetryWaitMax. Results outside that
+// range are clamped to it.
+type BackoffStrategy interface {
+	Backoff(min, max time.Duration, attemptNum int) time.Duration
+}
+
+// FullJitterBackoff waits a uniformly random duration between zero and an
+// exponential cap of min doubled per attempt, itself limited to max. Because
+// no two workers are likely to pick the same wait, retries against a shared
+// origin stay decorrelated. The result is raised to min when it falls short.
+type FullJitterBackoff struct{}
+
+func (FullJitterBackoff) Backoff(min, max time.Duration, attemptNum int) time.Duration {
+	limit := exponentialCap(min, max, attemptNum)
+	if limit <= 0 {
+		return min
+	}
+	return clampDuration(rand.N(limit+1), min, max)
+}
+
+// ExponentialBackoff waits min doubled per attempt, limited to max, with no
+// jitter, so retries happen at the same offsets on every run.
+type ExponentialBackoff struct{}
+
+func (ExponentialBackoff) Backoff(min, max time.Duration, attemptNum int) time.Duration {
+	return clampDuration(exponentialCap(min, max, attemptNum), min, max)
+}
+
+// exponentialCap returns min*2^attemptNum, limited to max without overflowing.
+func exponentialCap(min, max time.Duration, attemptNum int) time.Duration {
+	if attemptNum < 0 {
+		attemptNum = 0
+	}
+	if attemptNum >= 62 || min > max>>uint(attemptNum) {
+		return max
+	}
+	return min << uint(attemptNum)
+}
+
+// statusCode returns resp's status code, or zero when there is no response.
+func statusCode(resp *http.Response) int {
+	if resp == nil {
+		return 0
+	}
+	return resp.StatusCode
+}
+
+// fetchScopeKey carries the *fetchScope of the fetch a request belongs to.
+type fetchScopeKey struct{}
+
+// fetchScope lets capToDeadline find a fetch's own deadline, which per-attempt
+// timeouts would otherwise hide, and end the fetch early.
+type fetchScope struct {
+	ctx   context.Context
+	abort context.CancelCauseFunc
+}
+
+// withAbort derives the context a fetch's attempts run under. Cancelling it
+// stops the fetch; cancelling with a cause reports that cause to the caller.
+func withAbort(ctx context.Context) (context.Context, context.CancelCauseFunc) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	scope := &fetchScope{ctx: ctx, abort: cancel}
+	return context.WithValue(ctx, fetchScopeKey{}, scope), cancel
+}
+
+// capToDeadline returns wait unless it would reach the deadline of the fetch
+// that ctx belongs to. In that case no further attempt could finish in time,
+// so the fetch is aborted with context.DeadlineExceeded at once rather than
+// sleeping until the deadline.
+func capToDeadline(ctx context.Context, wait time.Duration) time.Duration {
+	scope, ok := ctx.Value(fetchScopeKey{}).(*fetchScope)
+	if !ok {
+		return wait
+	}
+	deadline, ok := scope.ctx.Deadline()
+	if !ok {
+		return wait
+	}
+	remaining := time.Until(deadline)
+	if wait < remaining {
+		return wait
+	}
+	scope.abort(context.DeadlineExceeded)
+	return max(remaining, 0)
+}
@@ -0,0 +1,158 @@
+package articles
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/shoresh319/firefly/internal/logging"
+)
+
+func TestFullJitterBackoffStaysWithinCap(t *testing.T) {
+	const (
+		min = 100 * time.Millisecond
+		max = 5 * time.Second
+	)
+	var strategy FullJitterBackoff
+
+	for attempt := 0; attempt < 8; attempt++ {
+		limit := exponentialCap(min, max, attempt)
+		seen := make(map[time.Duration]struct{})
+		for i := 0; i < 500; i++ {
+			got := strategy.Backoff(min, max, attempt)
+			if got < min || got > limit {
+				t.Fatalf("attempt %d: expected between %v and %v, got %v", attempt, min, limit, got)
+			}
+			seen[got] = struct{}{}
+		}
+		if attempt > 0 && len(seen) < 2 {
+			t.Fatalf("attempt %d: expected jittered waits, got only %v", attempt, seen)
+		}
+	}
+}
+
+func TestExponentialBackoffHasNoJitter(t *testing.T) {
+	const (
+		min = 100 * time.Millisecond
+		max = time.Second
+	)
+	want := []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond, time.Second}
+	for attempt, w := range want {
+		for i := 0; i < 3; i++ {
+			if got := (ExponentialBackoff{}).Backoff(min, max, attempt); got != w {
+				t.Fatalf("attempt %d: expected %v, got %v", attempt, w, got)
+			}
+		}
+	}
+}
+
+func TestExponentialCap(t *testing.T) {
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{0, time.Second},
+		{2, 4 * time.Second},
+		{5, 30 * time.Second},
+		{100, 30 * time.Second},
+	}
+	for _, tt := range tests {
+		if got := exponentialCap(time.Second, 30*time.Second, tt.attempt); got != tt.want {
+			t.Fatalf("attempt %d: expected %v, got %v", tt.attempt, tt.want, got)
+		}
+	}
+}
+
+func TestBackoffStrategyDefersToRetryAfter(t *testing.T) {
+	backoff := retryBackoff(defaultRetryableStatus, FullJitterBackoff{})
+
+	resp := &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{"Retry-After": {"7"}}}
+	if got := backoff(time.Second, time.Minute, 3, resp); got != 7*time.Second {
+		t.Fatalf("expected Retry-After to win with 7s, got %v", got)
+	}
+
+	for i := 0; i < 100; i++ {
+		if got := backoff(time.Second, time.Minute, 3, nil); got < time.Second || got > 8*time.Second {
+			t.Fatalf("expected transport error retry between 1s and 8s, got %v", got)
+		}
+	}
+}
+
+func TestFetchGivesUpWhenBackoffOutlastsDeadline(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		retryAfter string
+	}{
+		{name: "retry-after", status: http.StatusTooManyRequests, retryAfter: "30"},
+		{name: "exponential", status: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				atomic.AddInt32(&calls, 1)
+				if tt.retryAfter != "" {
+					w.Header().Set("Retry-After", tt.retryAfter)
+				}
+				w.WriteHeader(tt.status)
+			}))
+			defer srv.Close()
+
+			source := NewSource(SourceConfig{
+				RetryMax:          3,
+				RetryWaitMin:      10 * time.Second,
+				RetryWaitMax:      time.Minute,
+				PerRequestTimeout: 100 * time.Millisecond,
+				Logger:            logging.Nop(),
+			})
+
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+			defer cancel()
+			start := time.Now()
+			_, err := source.Fetch(ctx, srv.URL)
+			elapsed := time.Since(start)
+
+			if !errors.Is(err, context.DeadlineExceeded) {
+				t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+			}
+			if elapsed > time.Second {
+				t.Fatalf("expected to give up well before the deadline, took %v", elapsed)
+			}
+			if n := atomic.LoadInt32(&calls); n != 1 {
+				t.Fatalf("expected a single attempt, got %d", n)
+			}
+		})
+	}
+}
+
+func TestFetchRetriesWhenBackoffFitsDeadline(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		_, _ = w.Write([]byte(testHTML))
+	}))
+	defer srv.Close()
+
+	source := NewSource(SourceConfig{
+		RetryMax:     3,
+		RetryWaitMin: 10 * time.Millisecond,
+		RetryWaitMax: time.Second,
+		Logger:       logging.Nop(),
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := source.Fetch(ctx, srv.URL); err != nil {
+		t.Fatalf("expected retry within the deadline to succeed, got %v", err)
+	}
+}
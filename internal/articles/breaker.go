@@ -0,0 +1,80 @@
+package articles
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned by Fetch when a domain has failed repeatedly and
+// its circuit breaker is still cooling down.
+var ErrCircuitOpen = errors.New("circuit breaker open")
+
+// circuitBreaker tracks consecutive failures per domain and rejects requests to
+// a domain for a cooldown period once the threshold is reached. After the
+// cooldown a single further failure reopens the circuit, while a success
+// closes it. A nil *circuitBreaker allows everything.
+type circuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+
+	mu      sync.Mutex
+	domains map[string]*breakerState
+}
+
+type breakerState struct {
+	failures  int
+	openUntil time.Time
+}
+
+func newCircuitBreaker(threshold int, cooldown time.Duration) *circuitBreaker {
+	if threshold <= 0 {
+		return nil
+	}
+	return &circuitBreaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		now:       time.Now,
+		domains:   make(map[string]*breakerState),
+	}
+}
+
+// allow returns ErrCircuitOpen while domain's circuit is open.
+func (b *circuitBreaker) allow(domain string) error {
+	if b == nil {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if state, ok := b.domains[domain]; ok && b.now().Before(state.openUntil) {
+		return ErrCircuitOpen
+	}
+	return nil
+}
+
+// record updates domain's state with the outcome of a request.
+func (b *circuitBreaker) record(domain string, failed bool) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	state, ok := b.domains[domain]
+	if !failed {
+		if ok {
+			delete(b.domains, domain)
+		}
+		return
+	}
+	if !ok {
+		state = &breakerState{}
+		b.domains[domain] = state
+	}
+	state.failures++
+	if state.failures >= b.threshold {
+		state.openUntil = b.now().Add(b.cooldown)
+	}
+}
@@ -0,0 +1,98 @@
+package articles
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CacheEntry is the validator and extracted text remembered for a URL.
+type CacheEntry struct {
+	ETag         string
+	LastModified string
+	Text         string
+	// Expires is when the entry stops being fresh, from the response's
+	// Cache-Control max-age or Expires header. Until then it is served
+	// without contacting the origin; afterwards, or when zero, it is
+	// revalidated with ETag and LastModified.
+	Expires time.Time
+}
+
+// fresh reports whether the entry may be served without revalidation at now.
+func (e CacheEntry) fresh(now time.Time) bool {
+	return !e.Expires.IsZero() && now.Before(e.Expires)
+}
+
+// ResponseCache stores extracted article text together with the validators
+// needed for conditional requests. Implementations must be safe for
+// concurrent use.
+type ResponseCache interface {
+	Get(url string) (CacheEntry, bool)
+	Set(url string, entry CacheEntry)
+}
+
+// MemoryCache is an in-memory ResponseCache.
+type MemoryCache struct {
+	mu      sync.RWMutex
+	entries map[string]CacheEntry
+}
+
+// NewMemoryCache constructs an empty MemoryCache.
+func NewMemoryCache() *MemoryCache {
+	return &MemoryCache{entries: make(map[string]CacheEntry)}
+}
+
+// Get returns the entry cached for url.
+func (c *MemoryCache) Get(url string) (CacheEntry, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	entry, ok := c.entries[url]
+	return entry, ok
+}
+
+// Set stores entry for url, replacing any previous one.
+func (c *MemoryCache) Set(url string, entry CacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[url] = entry
+}
+
+// cachePolicy reads the Cache-Control, Age and Expires headers of a response
+// received at now. It reports whether the response may be stored at all,
+// which no-store forbids, and until when it is fresh. Per RFC 9111, max-age
+// takes precedence over Expires, no-cache and an Expires that cannot be
+// parsed require revalidation on every use, and a response without either
+// header has no freshness of its own.
+func cachePolicy(h http.Header, now time.Time) (store bool, expires time.Time) {
+	maxAge := -1
+	for _, directives := range h.Values("Cache-Control") {
+		for _, directive := range strings.Split(directives, ",") {
+			name, value, _ := strings.Cut(strings.TrimSpace(directive), "=")
+			switch strings.ToLower(name) {
+			case "no-store":
+				return false, time.Time{}
+			case "no-cache":
+				maxAge = 0
+			case "max-age":
+				if n, err := strconv.Atoi(strings.Trim(value, `"`)); err == nil && maxAge != 0 {
+					maxAge = max(n, 0)
+				}
+			}
+		}
+	}
+
+	if maxAge >= 0 {
+		age, _ := strconv.Atoi(h.Get("Age"))
+		return true, now.Add(time.Duration(maxAge-max(age, 0)) * time.Second)
+	}
+	if value := h.Get("Expires"); value != "" {
+		t, err := http.ParseTime(value)
+		if err != nil {
+			return true, now
+		}
+		return true, t
+	}
+	return true, time.Time{}
+}
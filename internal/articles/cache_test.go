@@ -0,0 +1,147 @@
+package articles
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/shoresh319/firefly/internal/logging"
+)
+
+func TestFetchRevalidatesWithETag(t *testing.T) {
+	var full, notModified int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			atomic.AddInt32(&notModified, 1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		atomic.AddInt32(&full, 1)
+		_, _ = w.Write([]byte(testHTML))
+	}))
+	defer srv.Close()
+
+	source := NewSource(SourceConfig{Cache: NewMemoryCache(), Logger: logging.Nop()})
+
+	first, err := source.Fetch(context.Background(), srv.URL)
+	if err != nil {
+		t.Fatalf("first fetch: %v", err)
+	}
+	second, err := source.Fetch(context.Background(), srv.URL)
+	if err != nil {
+		t.Fatalf("expected 304 to be treated as success, got %v", err)
+	}
+
+	if second != first {
+		t.Fatalf("expected cached text %q, got %q", first, second)
+	}
+	if full != 1 || notModified != 1 {
+		t.Fatalf("expected 1 full response and 1 revalidation, got %d and %d", full, notModified)
+	}
+}
+
+func TestFetchRevalidatesWithLastModified(t *testing.T) {
+	const stamp = "Wed, 21 Oct 2015 07:28:00 GMT"
+	var notModified int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-Modified-Since") == stamp {
+			atomic.AddInt32(&notModified, 1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("Last-Modified", stamp)
+		_, _ = w.Write([]byte(testHTML))
+	}))
+	defer srv.Close()
+
+	source := NewSource(SourceConfig{Cache: NewMemoryCache(), Logger: logging.Nop()})
+	for i := 0; i < 2; i++ {
+		if _, err := source.Fetch(context.Background(), srv.URL); err != nil {
+			t.Fatalf("fetch %d: %v", i, err)
+		}
+	}
+
+	if notModified != 1 {
+		t.Fatalf("expected second fetch to revalidate, got %d 304s", notModified)
+	}
+}
+
+// countingServer serves testHTML with the given Cache-Control header and
+// counts the requests it receives.
+func countingServer(t *testing.T, cacheControl string, calls *int32) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(calls, 1)
+		w.Header().Set("Cache-Control", cacheControl)
+		_, _ = w.Write([]byte(testHTML))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestFetchServesFreshCacheEntries(t *testing.T) {
+	var calls int32
+	srv := countingServer(t, "public, max-age=3600", &calls)
+	source := NewSource(SourceConfig{Cache: NewMemoryCache(), Logger: logging.Nop()})
+
+	first, err := source.Fetch(context.Background(), srv.URL)
+	if err != nil {
+		t.Fatalf("first fetch: %v", err)
+	}
+	second, err := source.Fetch(context.Background(), srv.URL)
+	if err != nil {
+		t.Fatalf("second fetch: %v", err)
+	}
+	if second != first {
+		t.Fatalf("expected cached text %q, got %q", first, second)
+	}
+	if calls != 1 {
+		t.Fatalf("expected the fresh entry to be served without a request, got %d requests", calls)
+	}
+}
+
+func TestFetchRefetchesExpiredOrUncacheableResponses(t *testing.T) {
+	for _, cacheControl := range []string{"max-age=0", "no-store, max-age=3600", "no-cache"} {
+		var calls int32
+		srv := countingServer(t, cacheControl, &calls)
+		source := NewSource(SourceConfig{Cache: NewMemoryCache(), Logger: logging.Nop()})
+
+		for i := 0; i < 2; i++ {
+			if _, err := source.Fetch(context.Background(), srv.URL); err != nil {
+				t.Fatalf("fetch %d with %q: %v", i, cacheControl, err)
+			}
+		}
+		if calls != 2 {
+			t.Fatalf("expected %q to force a refetch, got %d requests", cacheControl, calls)
+		}
+	}
+}
+
+func TestCachePolicy(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		header  http.Header
+		store   bool
+		expires time.Time
+	}{
+		{"none", http.Header{}, true, time.Time{}},
+		{"max-age", http.Header{"Cache-Control": {"max-age=60"}}, true, now.Add(time.Minute)},
+		{"age", http.Header{"Cache-Control": {"max-age=60"}, "Age": {"20"}}, true, now.Add(40 * time.Second)},
+		{"max-age over expires", http.Header{"Cache-Control": {"max-age=60"}, "Expires": {"Thu, 01 May 2025 12:00:00 GMT"}}, true, now.Add(time.Minute)},
+		{"expires", http.Header{"Expires": {"Wed, 01 May 2024 13:00:00 GMT"}}, true, now.Add(time.Hour)},
+		{"invalid expires", http.Header{"Expires": {"0"}}, true, now},
+		{"no-cache", http.Header{"Cache-Control": {"max-age=60, no-cache"}}, true, now},
+		{"no-store", http.Header{"Cache-Control": {"no-store"}}, false, time.Time{}},
+	}
+	for _, tc := range tests {
+		store, expires := cachePolicy(tc.header, now)
+		if store != tc.store || !expires.Equal(tc.expires) {
+			t.Fatalf("%s: expected store=%v expires=%v, got store=%v expires=%v", tc.name, tc.store, tc.expires, store, expires)
+		}
+	}
+}
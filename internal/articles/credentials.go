@@ -0,0 +1,54 @@
+package articles
+
+import (
+	"context"
+	"sync"
+)
+
+// CredentialProvider returns a fresh bearer token for sources whose tokens
+// expire. Source calls it when a response is 401 Unauthorized.
+type CredentialProvider func(ctx context.Context) (token string, err error)
+
+// credentialCache holds the token last returned by a CredentialProvider until
+// a 401 invalidates it. A nil *credentialCache sends no token.
+type credentialCache struct {
+	provider CredentialProvider
+
+	mu    sync.Mutex
+	token string
+}
+
+func newCredentialCache(provider CredentialProvider) *credentialCache {
+	if provider == nil {
+		return nil
+	}
+	return &credentialCache{provider: provider}
+}
+
+// current returns the cached token, or "" before the first refresh.
+func (c *credentialCache) current() string {
+	if c == nil {
+		return ""
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.token
+}
+
+// refresh replaces stale, the token a request was rejected with, by asking
+// the provider for a new one. Fetches rejected together share one refresh:
+// if another has already replaced stale, its token is returned instead.
+func (c *credentialCache) refresh(ctx context.Context, stale string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.token != stale {
+		return c.token, nil
+	}
+	token, err := c.provider(ctx)
+	if err != nil {
+		c.token = ""
+		return "", err
+	}
+	c.token = token
+	return token, nil
+}
@@ -0,0 +1,109 @@
+package articles
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html/charset"
+)
+
+// decodeContent wraps body with the decompressors required by the supplied
+// Content-Encoding header values. Encodings are listed in the order they were
+// applied, so they are undone in reverse. An empty body is returned as-is.
+func decodeContent(body io.Reader, headerValues []string) (io.Reader, error) {
+	var encodings []string
+	for _, value := range headerValues {
+		for _, enc := range strings.Split(value, ",") {
+			enc = strings.ToLower(strings.TrimSpace(enc))
+			if enc != "" && enc != "identity" {
+				encodings = append(encodings, enc)
+			}
+		}
+	}
+	if len(encodings) == 0 {
+		return body, nil
+	}
+
+	buffered := bufio.NewReader(body)
+	if _, err := buffered.Peek(1); err != nil {
+		if errors.Is(err, io.EOF) {
+			return buffered, nil
+		}
+		return nil, fmt.Errorf("read body: %w", err)
+	}
+
+	var reader io.Reader = buffered
+	for i := len(encodings) - 1; i >= 0; i-- {
+		decoded, err := newDecoder(reader, encodings[i])
+		if err != nil {
+			return nil, fmt.Errorf("decode %s content: %w", encodings[i], err)
+		}
+		reader = decoded
+	}
+
+	return &checkedReader{reader: reader}, nil
+}
+
+// newDecoder returns a reader that undoes a single content encoding.
+func newDecoder(r io.Reader, encoding string) (io.Reader, error) {
+	switch encoding {
+	case "gzip", "x-gzip":
+		return gzip.NewReader(r)
+	case "deflate":
+		// "deflate" is specified as zlib-wrapped data, but plenty of servers send
+		// raw DEFLATE streams, so sniff the zlib header before choosing.
+		buffered := bufio.NewReader(r)
+		header, err := buffered.Peek(2)
+		if err != nil {
+			return nil, err
+		}
+		if isZlibHeader(header) {
+			return zlib.NewReader(buffered)
+		}
+		return flate.NewReader(buffered), nil
+	default:
+		return nil, fmt.Errorf("unsupported content encoding %q", encoding)
+	}
+}
+
+// isZlibHeader reports whether b starts with a valid zlib stream header.
+func isZlibHeader(b []byte) bool {
+	return len(b) >= 2 && b[0]&0x0f == 8 && (uint16(b[0])<<8|uint16(b[1]))%31 == 0
+}
+
+// checkedReader annotates errors raised while decompressing so they are not
+// mistaken for transport failures.
+type checkedReader struct {
+	reader io.Reader
+}
+
+func (c *checkedReader) Read(p []byte) (int, error) {
+	n, err := c.reader.Read(p)
+	if err != nil && !errors.Is(err, io.EOF) {
+		err = fmt.Errorf("decompress body: %w", err)
+	}
+	return n, err
+}
+
+// decodeCharset converts body to UTF-8 using the charset declared by a BOM, the
+// Content-Type header or a <meta> tag. Content without a reliable declaration
+// that is already valid UTF-8 is returned untouched.
+func decodeCharset(body []byte, contentType string) ([]byte, error) {
+	enc, name, certain := charset.DetermineEncoding(body, contentType)
+	if name == "utf-8" || (!certain && utf8.Valid(body)) {
+		return body, nil
+	}
+
+	decoded, err := enc.NewDecoder().Bytes(body)
+	if err != nil {
+		return nil, fmt.Errorf("decode %s charset: %w", name, err)
+	}
+	return decoded, nil
+}
@@ -0,0 +1,93 @@
+package articles
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/shoresh319/firefly/internal/processing"
+)
+
+// Operations reported in FetchError.Op.
+const (
+	OpRequest     = "execute request"    // no usable response was received
+	OpStatus      = "check status"       // the server answered with a status not in AcceptStatusCodes
+	OpContentType = "check content type" // the response's media type is not accepted
+	OpRobots      = "check robots.txt"   // the site's robots.txt disallows the URL
+	OpLanguage    = "check language"     // the article is not in an allowed language
+	OpScheme      = "check scheme"       // the URL's scheme cannot be fetched
+)
+
+// ErrUnsupportedScheme is wrapped by a FetchError whose Op is OpScheme.
+var ErrUnsupportedScheme = errors.New("unsupported URL scheme")
+
+// ErrUnexpectedStatus is wrapped by a FetchError whose Op is OpStatus.
+var ErrUnexpectedStatus = errors.New("unexpected status")
+
+// ErrUnsupportedContentType is wrapped by a FetchError whose Op is
+// OpContentType.
+var ErrUnsupportedContentType = errors.New("unsupported content type")
+
+// ErrDisallowedByRobots is wrapped by a FetchError whose Op is OpRobots. It
+// wraps processing.ErrSkipped, so counts skip such URLs without recording a
+// failure.
+var ErrDisallowedByRobots = fmt.Errorf("disallowed by robots.txt: %w", processing.ErrSkipped)
+
+// ErrLanguageNotAllowed is wrapped by a FetchError whose Op is OpLanguage. Like
+// ErrDisallowedByRobots, it wraps processing.ErrSkipped.
+var ErrLanguageNotAllowed = fmt.Errorf("language not allowed: %w", processing.ErrSkipped)
+
+// FetchError describes a failed HTTP fetch so callers can use errors.As to
+// tell, say, a 404 from a timeout.
+type FetchError struct {
+	URL        string
+	StatusCode int // Zero when no response was received
+	Op         string
+	Err        error
+}
+
+func (e *FetchError) Error() string {
+	return fmt.Sprintf("%s %s: %v", e.Op, e.URL, e.Err)
+}
+
+func (e *FetchError) Unwrap() error {
+	return e.Err
+}
+
+// statusError reports that urlStr answered with a status code that is not in
+// SourceConfig.AcceptStatusCodes.
+func statusError(urlStr string, code int) *FetchError {
+	return &FetchError{
+		URL:        urlStr,
+		StatusCode: code,
+		Op:         OpStatus,
+		Err:        fmt.Errorf("%w: %d", ErrUnexpectedStatus, code),
+	}
+}
+
+// contentTypeError reports that urlStr answered code with a mediaType that is
+// not in SourceConfig.AcceptedContentTypes.
+func contentTypeError(urlStr string, code int, mediaType string) *FetchError {
+	return &FetchError{
+		URL:        urlStr,
+		StatusCode: code,
+		Op:         OpContentType,
+		Err:        fmt.Errorf("%w: %s", ErrUnsupportedContentType, mediaType),
+	}
+}
+
+// robotsError reports that urlStr was not fetched because robots.txt
+// disallows it.
+func robotsError(urlStr string) *FetchError {
+	return &FetchError{URL: urlStr, Op: OpRobots, Err: ErrDisallowedByRobots}
+}
+
+// languageError reports that urlStr was skipped because its text is in lang,
+// which SourceConfig.AllowedLanguages does not list.
+func languageError(urlStr, lang string) *FetchError {
+	return &FetchError{URL: urlStr, Op: OpLanguage, Err: fmt.Errorf("%w: %s", ErrLanguageNotAllowed, lang)}
+}
+
+// schemeError reports that urlStr has a scheme the Source does not fetch.
+func schemeError(urlStr, scheme string) *FetchError {
+	return &FetchError{URL: urlStr, Op: OpScheme, Err: fmt.Errorf("%w %q", ErrUnsupportedScheme, scheme)}
+}
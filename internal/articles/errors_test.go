@@ -0,0 +1,54 @@
+package articles
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/shoresh319/firefly/internal/logging"
+)
+
+func TestFetchErrorCarriesStatusCode(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+
+	_, err := newTestSource().Fetch(context.Background(), srv.URL+"/missing")
+
+	var fetchErr *FetchError
+	if !errors.As(err, &fetchErr) {
+		t.Fatalf("expected *FetchError, got %T: %v", err, err)
+	}
+	if fetchErr.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected status 404, got %d", fetchErr.StatusCode)
+	}
+	if fetchErr.Op != OpStatus || fetchErr.URL != srv.URL+"/missing" {
+		t.Fatalf("unexpected error fields: %+v", fetchErr)
+	}
+	if !errors.Is(err, ErrUnexpectedStatus) {
+		t.Fatalf("expected error to wrap ErrUnexpectedStatus, got %v", err)
+	}
+}
+
+func TestFetchErrorForRequestFailure(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+
+	source := NewSource(SourceConfig{PerRequestTimeout: 20 * time.Millisecond, Logger: logging.Nop()})
+	_, err := source.Fetch(context.Background(), srv.URL)
+
+	var fetchErr *FetchError
+	if !errors.As(err, &fetchErr) {
+		t.Fatalf("expected *FetchError, got %T: %v", err, err)
+	}
+	if fetchErr.Op != OpRequest || fetchErr.StatusCode != 0 {
+		t.Fatalf("unexpected error fields: %+v", fetchErr)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the timeout to be unwrappable, got %v", err)
+	}
+}
@@ -0,0 +1,187 @@
+package articles
+
+import (
+	"bytes"
+	"fmt"
+	"mime"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+
+	"github.com/shoresh319/firefly/internal/logging"
+)
+
+// TextExtractor turns a fetched document into the plain text that is counted.
+// contentType is the response's Content-Type header, or the type implied by
+// the file extension for local files, and may be empty.
+type TextExtractor interface {
+	Extract(contentType string, body []byte) (string, error)
+}
+
+// htmlExtractor is the default TextExtractor. It treats every document as HTML
+// except text/plain ones, which are returned as they are, with runs of
+// whitespace collapsed when normalizeSpace is set. Unless noFallback is set,
+// documents the HTML parser rejects are reduced to text by stripTags instead
+// of failing.
+type htmlExtractor struct {
+	opts           extractOptions
+	noFallback     bool
+	normalizeSpace bool
+	logger         logging.Logger
+}
+
+func (e htmlExtractor) Extract(contentType string, body []byte) (string, error) {
+	if isPlainText(contentType) {
+		if e.normalizeSpace {
+			return strings.Join(strings.Fields(string(body)), " "), nil
+		}
+		return string(body), nil
+	}
+
+	text, err := extractHTMLText(body, e.opts)
+	if err == nil || e.noFallback {
+		return text, err
+	}
+	e.logger.Warn("falling back to tag stripping", "error", err)
+	return stripTags(body), nil
+}
+
+// DefaultAcceptedContentTypes are the media type prefixes extracted when
+// SourceConfig.AcceptedContentTypes is empty.
+var DefaultAcceptedContentTypes = []string{"text/", "application/xhtml+xml"}
+
+// acceptContentType reports whether the media type in the contentType header
+// starts with one of the accepted prefixes, returning the media type for error
+// messages. An empty header is accepted.
+func acceptContentType(contentType string, accepted []string) (string, bool) {
+	if contentType == "" {
+		return "", true
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType, _, _ = strings.Cut(contentType, ";")
+		mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+	}
+	for _, prefix := range accepted {
+		if strings.HasPrefix(mediaType, strings.ToLower(prefix)) {
+			return mediaType, true
+		}
+	}
+	return mediaType, false
+}
+
+// isPlainText reports whether the contentType header names text/plain.
+func isPlainText(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && mediaType == "text/plain"
+}
+
+// skippedElements hold non-prose content whose text must not be counted.
+var skippedElements = map[atom.Atom]struct{}{
+	atom.Script:   {},
+	atom.Style:    {},
+	atom.Noscript: {},
+}
+
+// Default tag lists used by content-only extraction.
+var (
+	DefaultContentTags = []string{"article", "p", "h1", "h2", "h3", "h4", "h5", "h6", "li"}
+	DefaultSkipTags    = []string{"nav", "header", "footer", "aside"}
+)
+
+// extractOptions controls which parts of a document contribute text. The zero
+// value extracts all visible text.
+type extractOptions struct {
+	// contentTags, when non-nil, limits extraction to text nested inside one of
+	// these elements.
+	contentTags map[string]struct{}
+	// skipTags are elements whose subtree is ignored entirely.
+	skipTags map[string]struct{}
+}
+
+// newContentOnlyOptions builds options that keep only content-bearing elements,
+// falling back to the default tag lists when none are given.
+func newContentOnlyOptions(contentTags, skipTags []string) extractOptions {
+	if len(contentTags) == 0 {
+		contentTags = DefaultContentTags
+	}
+	if len(skipTags) == 0 {
+		skipTags = DefaultSkipTags
+	}
+	return extractOptions{
+		contentTags: tagSet(contentTags),
+		skipTags:    tagSet(skipTags),
+	}
+}
+
+func tagSet(tags []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(tags))
+	for _, tag := range tags {
+		set[strings.ToLower(strings.TrimSpace(tag))] = struct{}{}
+	}
+	return set
+}
+
+// extractHTMLText parses body as HTML and returns its visible text, one
+// trimmed text node per line.
+func extractHTMLText(body []byte, opts extractOptions) (string, error) {
+	doc, err := html.Parse(bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("parse HTML: %w", err)
+	}
+
+	var textBuilder strings.Builder
+	var crawler func(n *html.Node, inContent bool)
+	crawler = func(n *html.Node, inContent bool) {
+		if n.Type == html.ElementNode {
+			if _, skip := skippedElements[n.DataAtom]; skip {
+				return
+			}
+			if _, skip := opts.skipTags[n.Data]; skip {
+				return
+			}
+			if _, content := opts.contentTags[n.Data]; content {
+				inContent = true
+			}
+		}
+		if n.Type == html.TextNode && inContent {
+			trimmed := strings.TrimSpace(n.Data)
+			if trimmed != "" {
+				textBuilder.WriteString(trimmed)
+				textBuilder.WriteByte('\n')
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			crawler(c, inContent)
+		}
+	}
+	crawler(doc, opts.contentTags == nil)
+
+	return textBuilder.String(), nil
+}
+
+var (
+	hiddenBlockPattern = regexp.MustCompile(`(?is)<(script|style|noscript)\b.*?</(script|style|noscript)\s*>|<!--.*?-->`)
+	tagPattern         = regexp.MustCompile(`(?s)<[^>]*>`)
+)
+
+// stripTags is the crude fallback for documents html.Parse rejects, such as
+// ones nested too deeply: it drops scripts, styles and comments, removes
+// anything that looks like a tag and unescapes entities. Content-only
+// options are not applied.
+func stripTags(body []byte) string {
+	text := hiddenBlockPattern.ReplaceAll(body, []byte(" "))
+	text = tagPattern.ReplaceAll(text, []byte("\n"))
+
+	var textBuilder strings.Builder
+	for _, line := range strings.Split(html.UnescapeString(string(text)), "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed != "" {
+			textBuilder.WriteString(trimmed)
+			textBuilder.WriteByte('\n')
+		}
+	}
+	return textBuilder.String()
+}
@@ -0,0 +1,263 @@
+package articles
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/shoresh319/firefly/internal/logging"
+	"github.com/shoresh319/firefly/internal/processing"
+)
+
+func TestExtractHTMLTextSkipsNonProse(t *testing.T) {
+	doc := `<html><head>
+<style>body { color: red; }</style>
+<script>function track() { var visitor = 1; }</script>
+</head><body>
+<p>Fireflies glow at night</p>
+<noscript>Enable javascript please</noscript>
+<script type="text/javascript">var another = function() {};</script>
+</body></html>`
+
+	got, err := extractHTMLText([]byte(doc), extractOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !strings.Contains(got, "Fireflies glow at night") {
+		t.Fatalf("expected paragraph text, got %q", got)
+	}
+	for _, banned := range []string{"function", "var", "color", "javascript", "visitor"} {
+		if strings.Contains(got, banned) {
+			t.Fatalf("expected %q to be skipped, got %q", banned, got)
+		}
+	}
+}
+
+const boilerplateDoc = `<html><body>
+<header><p>Subscribe newsletter</p></header>
+<nav><ul><li>Home</li><li>Gadgets</li></ul></nav>
+<div>Sponsored widget</div>
+<article>
+  <h1>Fireflies glow</h1>
+  <p>Bioluminescence attracts mates</p>
+  <aside><p>Related stories</p></aside>
+  <ul><li>Summer nights</li></ul>
+</article>
+<footer><p>Copyright notice</p></footer>
+</body></html>`
+
+func TestExtractHTMLTextContentOnly(t *testing.T) {
+	got, err := extractHTMLText([]byte(boilerplateDoc), newContentOnlyOptions(nil, nil))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := "Fireflies glow\nBioluminescence attracts mates\nSummer nights\n"
+	if got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+}
+
+func TestExtractHTMLTextCustomTags(t *testing.T) {
+	got, err := extractHTMLText([]byte(boilerplateDoc), newContentOnlyOptions([]string{"NAV"}, []string{"footer"}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := "Home\nGadgets\n"
+	if got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+}
+
+type upperExtractor struct {
+	contentTypes []string
+}
+
+func (e *upperExtractor) Extract(contentType string, body []byte) (string, error) {
+	e.contentTypes = append(e.contentTypes, contentType)
+	return strings.ToUpper(string(body)), nil
+}
+
+func TestFetchUsesCustomExtractor(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Write([]byte("fireflies glow"))
+	}))
+	defer server.Close()
+
+	extractor := &upperExtractor{}
+	source := NewSource(SourceConfig{Extractor: extractor, Logger: logging.Nop()})
+
+	got, err := source.Fetch(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "FIREFLIES GLOW" {
+		t.Fatalf("expected %q, got %q", "FIREFLIES GLOW", got)
+	}
+	if len(extractor.contentTypes) != 1 || extractor.contentTypes[0] != "text/plain; charset=utf-8" {
+		t.Fatalf("expected extractor to see the response content type, got %q", extractor.contentTypes)
+	}
+}
+
+func serveContentType(contentType string, body []byte) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", contentType)
+		_, _ = w.Write(body)
+	}))
+}
+
+func TestContentTypeErrorKeepsStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		w.WriteHeader(http.StatusNonAuthoritativeInfo)
+	}))
+	defer server.Close()
+
+	source := NewSource(SourceConfig{AcceptStatusCodes: []int{200, http.StatusNonAuthoritativeInfo}, Logger: logging.Nop()})
+	_, err := source.Fetch(context.Background(), server.URL)
+	var fetchErr *FetchError
+	if !errors.As(err, &fetchErr) || fetchErr.Op != OpContentType {
+		t.Fatalf("expected *FetchError with Op %q, got %v", OpContentType, err)
+	}
+	if fetchErr.StatusCode != http.StatusNonAuthoritativeInfo {
+		t.Fatalf("expected status %d, got %d", http.StatusNonAuthoritativeInfo, fetchErr.StatusCode)
+	}
+}
+
+func TestFetchRejectsUnsupportedContentType(t *testing.T) {
+	server := serveContentType("image/png", []byte("\x89PNG\r\n\x1a\n"))
+	defer server.Close()
+
+	extractor := &upperExtractor{}
+	source := NewSource(SourceConfig{Extractor: extractor, Logger: logging.Nop()})
+
+	got, err := source.Fetch(context.Background(), server.URL)
+	if !errors.Is(err, ErrUnsupportedContentType) {
+		t.Fatalf("expected ErrUnsupportedContentType, got %v", err)
+	}
+	var fetchErr *FetchError
+	if !errors.As(err, &fetchErr) || fetchErr.Op != OpContentType {
+		t.Fatalf("expected *FetchError with Op %q, got %v", OpContentType, err)
+	}
+	if got != "" {
+		t.Fatalf("expected empty text, got %q", got)
+	}
+	if len(extractor.contentTypes) != 0 {
+		t.Fatalf("expected extractor not to run, got %q", extractor.contentTypes)
+	}
+}
+
+func TestFetchAcceptedContentTypes(t *testing.T) {
+	server := serveContentType("application/pdf", []byte("fireflies glow"))
+	defer server.Close()
+
+	source := NewSource(SourceConfig{
+		Extractor:            &upperExtractor{},
+		AcceptedContentTypes: []string{"application/pdf"},
+		Logger:               logging.Nop(),
+	})
+
+	got, err := source.Fetch(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "FIREFLIES GLOW" {
+		t.Fatalf("expected %q, got %q", "FIREFLIES GLOW", got)
+	}
+}
+
+func TestAcceptContentType(t *testing.T) {
+	tests := []struct {
+		contentType string
+		want        bool
+	}{
+		{"", true},
+		{"text/html; charset=utf-8", true},
+		{"TEXT/Plain", true},
+		{"application/xhtml+xml", true},
+		{"application/json", false},
+		{"image/png", false},
+	}
+	for _, tt := range tests {
+		if _, got := acceptContentType(tt.contentType, DefaultAcceptedContentTypes); got != tt.want {
+			t.Fatalf("acceptContentType(%q): expected %v, got %v", tt.contentType, tt.want, got)
+		}
+	}
+}
+
+// deeplyNestedHTML exceeds html.Parse's nesting limit, so parsing fails.
+var deeplyNestedHTML = strings.Repeat("<div>", 600) +
+	"<p>Fireflies &amp; glowworms</p><script>var hidden = 1;</script><!-- secret -->" +
+	strings.Repeat("</div>", 600)
+
+func TestFetchFallsBackWhenHTMLParseFails(t *testing.T) {
+	if _, err := extractHTMLText([]byte(deeplyNestedHTML), extractOptions{}); err == nil {
+		t.Fatal("expected html.Parse to reject the document")
+	}
+
+	server := serveContentType("text/html", []byte(deeplyNestedHTML))
+	defer server.Close()
+
+	got, err := NewSource(SourceConfig{Logger: logging.Nop()}).Fetch(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.TrimSpace(got) != "Fireflies & glowworms" {
+		t.Fatalf("expected stripped text %q, got %q", "Fireflies & glowworms", got)
+	}
+}
+
+func TestFetchParseFallbackDisabled(t *testing.T) {
+	server := serveContentType("text/html", []byte(deeplyNestedHTML))
+	defer server.Close()
+
+	source := NewSource(SourceConfig{Logger: logging.Nop(), DisableParseFallback: true})
+	if _, err := source.Fetch(context.Background(), server.URL); err == nil || !strings.Contains(err.Error(), "parse HTML") {
+		t.Fatalf("expected parse error, got %v", err)
+	}
+}
+
+func TestFetchPassesPlainTextThrough(t *testing.T) {
+	const doc = "Fireflies <glow> at dusk &amp; dawn\n\n<script>lanterns</script>   flicker\n"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		_, _ = w.Write([]byte(doc))
+	}))
+	defer server.Close()
+
+	source := NewSource(SourceConfig{Logger: logging.Nop()})
+	text, err := source.Fetch(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if text != doc {
+		t.Fatalf("expected the body unchanged, got %q", text)
+	}
+
+	urls := make(chan string, 1)
+	urls <- server.URL
+	close(urls)
+	counts, err := processing.NewCounter(source, anyWord{}, processing.WithLogger(logging.Nop())).CountAllWords(context.Background(), urls)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, word := range []string{"glow", "amp", "script", "lanterns", "flicker"} {
+		if counts[word] == 0 {
+			t.Fatalf("expected %q to be counted, got %v", word, counts)
+		}
+	}
+
+	text, err = NewSource(SourceConfig{Logger: logging.Nop(), NormalizePlainText: true}).Fetch(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "Fireflies <glow> at dusk &amp; dawn <script>lanterns</script> flicker"; text != want {
+		t.Fatalf("expected %q, got %q", want, text)
+	}
+}
@@ -0,0 +1,54 @@
+package articles
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// localPath reports whether rawURL is a file:// URL or an absolute path to
+// read from disk and returns the path. Both are only honored with
+// SourceConfig.AllowLocalFiles; otherwise they fail with ErrUnsupportedScheme,
+// as do relative paths and any scheme other than http and https. Unparseable
+// URLs are left for the HTTP client to report.
+func (s *Source) localPath(rawURL string) (string, bool, error) {
+	if s.allowLocalFiles && filepath.IsAbs(rawURL) {
+		return filepath.Clean(rawURL), true, nil
+	}
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return "", false, nil
+	}
+	switch strings.ToLower(parsed.Scheme) {
+	case "http", "https":
+		return "", false, nil
+	case "file":
+		if s.allowLocalFiles {
+			return parsed.Path, true, nil
+		}
+	}
+	return "", false, schemeError(rawURL, parsed.Scheme)
+}
+
+// fetchFile reads a document from disk and extracts its text, using the file
+// extension to infer the content type.
+func (s *Source) fetchFile(ctx context.Context, path string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	body, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("read file: %w", err)
+	}
+
+	text, err := s.extractor.Extract(mime.TypeByExtension(filepath.Ext(path)), body)
+	if err != nil {
+		return "", fmt.Errorf("extract text: %w", err)
+	}
+	return text, nil
+}
@@ -0,0 +1,93 @@
+package articles
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/shoresh319/firefly/internal/logging"
+)
+
+func TestFetchLocalFileMatchesHTTP(t *testing.T) {
+	fixture := filepath.Join("testdata", "article.html")
+	content, err := os.ReadFile(fixture)
+	if err != nil {
+		t.Fatalf("read fixture: %v", err)
+	}
+
+	srv := serveBody("", content)
+	defer srv.Close()
+
+	source := NewSource(SourceConfig{AllowLocalFiles: true, Logger: logging.Nop()})
+	want, err := source.Fetch(context.Background(), srv.URL)
+	if err != nil {
+		t.Fatalf("fetch over HTTP: %v", err)
+	}
+	if !strings.Contains(want, "bioluminescence") {
+		t.Fatalf("unexpected HTTP text %q", want)
+	}
+
+	abs, err := filepath.Abs(fixture)
+	if err != nil {
+		t.Fatalf("abs: %v", err)
+	}
+
+	target := "file://" + filepath.ToSlash(abs)
+	got, err := source.Fetch(context.Background(), target)
+	if err != nil {
+		t.Fatalf("fetch %s: %v", target, err)
+	}
+	if got != want {
+		t.Fatalf("fetch %s: expected %q, got %q", target, want, got)
+	}
+
+	got, err = source.Fetch(context.Background(), abs)
+	if err != nil {
+		t.Fatalf("fetch %s: %v", abs, err)
+	}
+	if got != want {
+		t.Fatalf("fetch %s: expected %q, got %q", abs, want, got)
+	}
+
+	// Relative paths are never read, even with local files allowed.
+	if _, err := source.Fetch(context.Background(), fixture); !errors.Is(err, ErrUnsupportedScheme) {
+		t.Fatalf("expected ErrUnsupportedScheme for a relative path, got %v", err)
+	}
+}
+
+func TestFetchRejectsLocalFilesByDefault(t *testing.T) {
+	source := newTestSource()
+	for _, target := range []string{"/etc/passwd", "file:///etc/passwd", "example.com/page"} {
+		text, err := source.Fetch(context.Background(), target)
+		var fetchErr *FetchError
+		if !errors.Is(err, ErrUnsupportedScheme) || !errors.As(err, &fetchErr) || fetchErr.Op != OpScheme {
+			t.Fatalf("expected ErrUnsupportedScheme for %s, got %v", target, err)
+		}
+		if text != "" {
+			t.Fatalf("expected no text for %s, got %q", target, text)
+		}
+		if _, err := source.FetchRaw(context.Background(), target); !errors.Is(err, ErrUnsupportedScheme) {
+			t.Fatalf("expected FetchRaw to reject %s, got %v", target, err)
+		}
+	}
+}
+
+func TestFetchLocalFileMissing(t *testing.T) {
+	source := NewSource(SourceConfig{AllowLocalFiles: true, Logger: logging.Nop()})
+	_, err := source.Fetch(context.Background(), "file://"+filepath.ToSlash(filepath.Join(t.TempDir(), "missing.html")))
+	if err == nil {
+		t.Fatal("expected error for missing file")
+	}
+}
+
+func TestLocalPathLeavesHTTPAlone(t *testing.T) {
+	source := NewSource(SourceConfig{AllowLocalFiles: true, Logger: logging.Nop()})
+	for _, raw := range []string{"http://example.com/a", "https://example.com/b", "HTTPS://example.com/c"} {
+		if _, ok, err := source.localPath(raw); ok || err != nil {
+			t.Fatalf("expected %s to be fetched over HTTP", raw)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package articles
+
+import (
+	"context"
+	"strings"
+	"unicode"
+
+	"github.com/shoresh319/firefly/internal/logging"
+)
+
+// minLanguageEvidence is how many function words DetectLanguage must see
+// before naming a language.
+const minLanguageEvidence = 5
+
+// languageStopWords lists frequent function words of the languages
+// DetectLanguage knows, keyed by ISO 639-1 code.
+var languageStopWords = map[string][]string{
+	"en": {"the", "and", "of", "to", "in", "is", "that", "it", "was", "for", "with", "as", "on", "be", "at", "by", "this", "have", "from", "are", "not", "but", "or", "which", "you", "were", "they", "his", "her", "an"},
+	"fr": {"le", "la", "les", "et", "des", "est", "une", "un", "du", "dans", "que", "qui", "pour", "pas", "sur", "au", "avec", "ce", "il", "elle", "sont", "mais", "ou", "nous", "vous", "aux", "ces", "été", "leur", "plus"},
+	"de": {"der", "die", "und", "das", "ist", "nicht", "ein", "eine", "zu", "den", "mit", "sich", "des", "auf", "für", "im", "dem", "von", "auch", "es", "sind", "wird", "bei", "nach", "oder", "aber", "wie", "wir", "noch", "einer"},
+	"es": {"el", "la", "los", "las", "de", "que", "y", "en", "un", "una", "es", "por", "con", "para", "no", "se", "del", "al", "lo", "como", "más", "pero", "sus", "su", "ha", "este", "esta", "son", "entre", "muy"},
+	"it": {"il", "lo", "la", "gli", "le", "di", "che", "e", "un", "una", "per", "non", "con", "del", "della", "sono", "è", "si", "da", "nel", "ma", "anche", "come", "più", "questo", "alla", "dei", "delle", "ha", "essere"},
+	"pt": {"o", "a", "os", "as", "de", "que", "e", "um", "uma", "do", "da", "em", "não", "para", "com", "por", "se", "dos", "das", "mais", "mas", "como", "ao", "é", "foi", "seu", "sua", "pelo", "ele", "ela"},
+	"nl": {"de", "het", "een", "en", "van", "is", "dat", "niet", "op", "te", "zijn", "met", "voor", "die", "ook", "aan", "er", "maar", "om", "als", "dit", "bij", "wordt", "nog", "door", "naar", "heeft", "wel", "ze", "werd"},
+}
+
+// stopWordLanguages maps each function word to the languages using it.
+var stopWordLanguages = func() map[string][]string {
+	index := make(map[string][]string)
+	for lang, words := range languageStopWords {
+		for _, w := range words {
+			index[w] = append(index[w], lang)
+		}
+	}
+	return index
+}()
+
+// DetectLanguage guesses the language of text from its function words and
+// returns its ISO 639-1 code: one of en, fr, de, es, it, pt or nl. It returns
+// "" when text is too short or too evenly split to tell. It is the default
+// SourceConfig.LanguageDetector.
+func DetectLanguage(text string) string {
+	scores := make(map[string]int)
+	for _, word := range strings.FieldsFunc(text, func(r rune) bool { return !unicode.IsLetter(r) }) {
+		for _, lang := range stopWordLanguages[strings.ToLower(word)] {
+			scores[lang]++
+		}
+	}
+
+	best, bestScore, runnerUp := "", 0, 0
+	for lang, score := range scores {
+		switch {
+		case score > bestScore || (score == bestScore && lang < best):
+			best, bestScore, runnerUp = lang, score, max(runnerUp, bestScore)
+		case score > runnerUp:
+			runnerUp = score
+		}
+	}
+	// Shared words such as "de" or "la" score for several languages, so ask
+	// for a clear lead as well as enough evidence.
+	if bestScore < minLanguageEvidence || bestScore*4 < runnerUp*5 {
+		return ""
+	}
+	return best
+}
+
+// checkLanguage fails with ErrLanguageNotAllowed when SourceConfig.
+// AllowedLanguages is set and text is in a language it does not list. Text
+// whose language cannot be detected is let through.
+func (s *Source) checkLanguage(ctx context.Context, urlStr, text string) error {
+	if len(s.allowedLanguages) == 0 {
+		return nil
+	}
+	lang := s.detectLanguage(text)
+	if lang == "" || s.allowedLanguages[lang] {
+		return nil
+	}
+	logging.FromContext(ctx, s.logger).Info("skipping article in disallowed language", "url", urlStr, "language", lang)
+	return languageError(urlStr, lang)
+}
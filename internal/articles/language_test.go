@@ -0,0 +1,85 @@
+package articles
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/shoresh319/firefly/internal/logging"
+	"github.com/shoresh319/firefly/internal/processing"
+)
+
+const (
+	englishArticle = "The fox ran to the river and it was quick. This is the story of the fox that jumped over the dog."
+	frenchArticle  = "Le renard est dans la forêt et il court avec les chiens. Ce renard est plus rapide que le chien qui dort sur la route."
+)
+
+type anyWord struct{}
+
+func (anyWord) Validate(string) bool { return true }
+
+func TestDetectLanguage(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{englishArticle, "en"},
+		{frenchArticle, "fr"},
+		{"Der Fuchs ist nicht in dem Wald, und er läuft mit den Hunden auf die Straße.", "de"},
+		{"firefly glow", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := DetectLanguage(tt.text); got != tt.want {
+			t.Fatalf("DetectLanguage(%q): expected %q, got %q", tt.text, tt.want, got)
+		}
+	}
+}
+
+func TestAllowedLanguagesSkipsOtherLanguages(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		switch r.URL.Path {
+		case "/en":
+			_, _ = w.Write([]byte(englishArticle))
+		case "/fr":
+			_, _ = w.Write([]byte(frenchArticle))
+		}
+	}))
+	defer server.Close()
+
+	source := NewSource(SourceConfig{Logger: logging.Nop(), AllowedLanguages: []string{"en"}})
+
+	_, err := source.Fetch(context.Background(), server.URL+"/fr")
+	if !errors.Is(err, ErrLanguageNotAllowed) || !errors.Is(err, processing.ErrSkipped) {
+		t.Fatalf("expected ErrLanguageNotAllowed wrapping processing.ErrSkipped, got %v", err)
+	}
+	var fetchErr *FetchError
+	if !errors.As(err, &fetchErr) || fetchErr.Op != OpLanguage {
+		t.Fatalf("expected FetchError with Op %q, got %v", OpLanguage, err)
+	}
+
+	urls := make(chan string, 2)
+	urls <- server.URL + "/en"
+	urls <- server.URL + "/fr"
+	close(urls)
+
+	counter := processing.NewCounter(source, anyWord{}, processing.WithLogger(logging.Nop()))
+	counts, stats, err := counter.CountAllWordsWithStats(context.Background(), urls)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stats.Successes != 1 || stats.Skipped != 1 || stats.Failures != 0 {
+		t.Fatalf("expected 1 success and 1 skip without failures, got %+v", stats)
+	}
+	if counts["fox"] != 2 || counts["the"] != 4 {
+		t.Fatalf("expected the English words to be counted, got %v", counts)
+	}
+	for _, word := range []string{"renard", "le", "forêt", "chiens"} {
+		if _, ok := counts[word]; ok {
+			t.Fatalf("expected French word %q not to be counted, got %v", word, counts)
+		}
+	}
+}
@@ -2,50 +2,312 @@ package articles
 
 import (
 	"bufio"
+	"compress/gzip"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
+	"io"
 	"os"
+	"regexp"
 	"strings"
+
+	"github.com/shoresh319/firefly/internal/logging"
 )
 
+// DefaultListBufferSize is the capacity of the channel article lists are
+// streamed on when ListOptions.BufferSize is zero.
+const DefaultListBufferSize = 1000
+
+// ListOptions tunes how article lists are streamed.
+type ListOptions struct {
+	// Dedupe drops repeated URLs, preserving the order of first appearance.
+	Dedupe bool
+	// BufferSize is how many URLs the reader may queue ahead of the workers
+	// (default: DefaultListBufferSize). Each queued URL costs only its string,
+	// so the default suits most lists; a few times the worker count is enough
+	// when memory is tight.
+	BufferSize int
+	// Exclude drops URLs matching any of the patterns, such as
+	// regexp.MustCompile(`\.pdf$`), before deduplication. How many were
+	// dropped is logged once the list is read.
+	Exclude []*regexp.Regexp
+	// Logger receives skipped-line and read-error logs (default:
+	// logging.Default()).
+	Logger logging.Logger
+}
+
+// logger returns o.Logger, or logging.Default() when it is unset.
+func (o ListOptions) logger() logging.Logger {
+	if o.Logger == nil {
+		return logging.Default()
+	}
+	return o.Logger
+}
+
+// ArticleRef is an article URL together with the metadata given for it in a
+// JSON-lines article list. Plain-text lists yield refs with only URL set.
+type ArticleRef struct {
+	URL    string   `json:"url"`
+	Tags   []string `json:"tags,omitempty"`
+	Weight float64  `json:"weight,omitempty"`
+}
+
 // ListFromFile streams article URLs read from the provided file path.
 // It reads all lines from the file, but respects context cancellation when sending.
+// Gzip-compressed lists, recognised by a .gz suffix or the gzip magic number,
+// are decompressed transparently. Files ending in .jsonl (or .jsonl.gz) are
+// read as JSON lines, one ArticleRef object per line, and only their URLs are
+// streamed. Lines containing {start..end} ranges are expanded with
+// ExpandURLTemplate; those expanding to more than MaxURLTemplateExpansion
+// URLs are logged and skipped.
 func ListFromFile(ctx context.Context, filePath string) (<-chan string, error) {
+	return ListFromFileWithOptions(ctx, filePath, ListOptions{})
+}
+
+// ListFromFileWithOptions behaves like ListFromFile with the supplied options applied.
+func ListFromFileWithOptions(ctx context.Context, filePath string, opts ListOptions) (<-chan string, error) {
+	rc, err := openList(filePath)
+	if err != nil {
+		return nil, err
+	}
+	urls, _ := streamList(ctx, rc, rc, filePath, listParser(filePath), opts, refURL)
+	return urls, nil
+}
+
+// ListFromFileWithDone behaves like ListFromFileWithOptions and also returns a
+// channel reporting why the URL channel closed. It delivers exactly one value,
+// ready by the time the URL channel is closed: nil once the whole file was
+// read, the context's error if streaming was cancelled, or the error that
+// stopped reading the file.
+func ListFromFileWithDone(ctx context.Context, filePath string, opts ListOptions) (<-chan string, <-chan error, error) {
+	rc, err := openList(filePath)
+	if err != nil {
+		return nil, nil, err
+	}
+	urls, done := streamList(ctx, rc, rc, filePath, listParser(filePath), opts, refURL)
+	return urls, done, nil
+}
+
+// RefsFromFile behaves like ListFromFileWithOptions but streams whole
+// ArticleRefs, keeping the metadata of JSON-lines lists.
+func RefsFromFile(ctx context.Context, filePath string, opts ListOptions) (<-chan ArticleRef, error) {
+	rc, err := openList(filePath)
+	if err != nil {
+		return nil, err
+	}
+	refs, _ := streamList(ctx, rc, rc, filePath, listParser(filePath), opts, wholeRef)
+	return refs, nil
+}
+
+// openList opens the article list at filePath, decompressing it if needed.
+func openList(filePath string) (io.ReadCloser, error) {
 	f, err := os.Open(filePath)
 	if err != nil {
 		return nil, fmt.Errorf("open article list: %w", err)
 	}
 
+	rc, err := maybeGunzip(f, strings.HasSuffix(filePath, ".gz"))
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("open article list: %w", err)
+	}
+	return rc, nil
+}
+
+// listParser picks the line format for filePath by its extension.
+func listParser(filePath string) func(line string) (ArticleRef, error) {
+	if strings.HasSuffix(strings.TrimSuffix(filePath, ".gz"), ".jsonl") {
+		return parseJSONLine
+	}
+	return parsePlainLine
+}
+
+func parsePlainLine(line string) (ArticleRef, error) {
+	return ArticleRef{URL: line}, nil
+}
+
+func parseJSONLine(line string) (ArticleRef, error) {
+	var ref ArticleRef
+	if err := json.Unmarshal([]byte(line), &ref); err != nil {
+		return ArticleRef{}, fmt.Errorf("parse article ref: %w", err)
+	}
+	ref.URL = strings.TrimSpace(ref.URL)
+	if ref.URL == "" {
+		return ArticleRef{}, errors.New("parse article ref: missing url")
+	}
+	return ref, nil
+}
+
+func refURL(ref ArticleRef) string       { return ref.URL }
+func wholeRef(ref ArticleRef) ArticleRef { return ref }
+
+// gzipFile closes both the decompressor and the underlying file.
+type gzipFile struct {
+	*gzip.Reader
+	file io.Closer
+}
+
+func (g gzipFile) Close() error {
+	g.Reader.Close()
+	return g.file.Close()
+}
+
+// maybeGunzip wraps f in a gzip reader when force is set or its content starts
+// with the gzip magic number. Otherwise f is returned with its content intact.
+func maybeGunzip(f *os.File, force bool) (io.ReadCloser, error) {
+	br := bufio.NewReader(f)
+	if !force {
+		magic, _ := br.Peek(2)
+		if len(magic) < 2 || magic[0] != 0x1f || magic[1] != 0x8b {
+			return struct {
+				io.Reader
+				io.Closer
+			}{br, f}, nil
+		}
+	}
+
+	zr, err := gzip.NewReader(br)
+	if err != nil {
+		return nil, fmt.Errorf("gzip: %w", err)
+	}
+	return gzipFile{Reader: zr, file: f}, nil
+}
+
+// CountList returns how many URLs ListFromFileWithOptions would stream from
+// filePath, so callers can report progress against a known total. It reads
+// the whole file.
+func CountList(ctx context.Context, filePath string, opts ListOptions) (int, error) {
+	ch, err := ListFromFileWithOptions(ctx, filePath, opts)
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	for range ch {
+		n++
+	}
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+// ListFromReader streams article URLs read line by line from r, such as
+// os.Stdin. The caller retains ownership of r.
+func ListFromReader(ctx context.Context, r io.Reader) <-chan string {
+	return ListFromReaderWithOptions(ctx, r, ListOptions{})
+}
+
+// ListFromReaderWithOptions behaves like ListFromReader with the supplied options applied.
+func ListFromReaderWithOptions(ctx context.Context, r io.Reader, opts ListOptions) <-chan string {
+	urls, _ := streamList(ctx, r, nil, "reader", parsePlainLine, opts, refURL)
+	return urls
+}
+
+// ListFromReaderWithDone behaves like ListFromReaderWithOptions and also
+// returns a channel reporting why the URL channel closed, as
+// ListFromFileWithDone does.
+func ListFromReaderWithDone(ctx context.Context, r io.Reader, opts ListOptions) (<-chan string, <-chan error) {
+	return streamList(ctx, r, nil, "reader", parsePlainLine, opts, refURL)
+}
+
+// streamList scans r in a background goroutine, parsing each non-empty line,
+// expanding its URL template and sending each URL, converted by emit, on the
+// returned channel until r is exhausted or ctx is cancelled. Lines that fail
+// to parse or expand past MaxURLTemplateExpansion are logged and skipped. closer, if non-nil, is closed when scanning stops; name identifies
+// the source in logs. The done channel receives the reason scanning stopped,
+// nil at the end of r, before the output channel is closed.
+func streamList[T any](ctx context.Context, r io.Reader, closer io.Closer, name string, parse func(string) (ArticleRef, error), opts ListOptions, emit func(ArticleRef) T) (<-chan T, <-chan error) {
 	// Use a buffered channel to prevent blocking the file reader
-	out := make(chan string, 1000)
+	bufferSize := opts.BufferSize
+	if bufferSize <= 0 {
+		bufferSize = DefaultListBufferSize
+	}
+	logger := opts.logger()
+	out := make(chan T, bufferSize)
+	done := make(chan error, 1)
 	go func() {
 		defer close(out)
-		defer f.Close()
+		var stopErr error
+		defer func() {
+			done <- stopErr
+			close(done)
+		}()
+		if closer != nil {
+			defer closer.Close()
+		}
 
-		scanner := bufio.NewScanner(f)
+		scanner := bufio.NewScanner(r)
 		// Increase buffer size to handle any unusually long lines
 		buf := make([]byte, 0, 64*1024)
 		scanner.Buffer(buf, 1024*1024) // 1MB max line length
 
+		var seen map[string]struct{}
+		if opts.Dedupe {
+			seen = make(map[string]struct{})
+		}
+		excluded := 0
+		if len(opts.Exclude) > 0 {
+			defer func() {
+				if excluded > 0 {
+					logger.Info("excluded article list URLs", "path", name, "excluded", excluded)
+				}
+			}()
+		}
+
 		for scanner.Scan() {
 			line := strings.TrimSpace(scanner.Text())
 			if line == "" {
 				continue
 			}
+			ref, err := parse(line)
+			if err != nil {
+				logger.Error("skipping malformed article list line", "path", name, "error", err)
+				continue
+			}
+			if URLTemplateSize(ref.URL) > MaxURLTemplateExpansion {
+				logger.Error("skipping article list line over the template expansion limit", "path", name, "url", ref.URL, "max_urls", MaxURLTemplateExpansion)
+				continue
+			}
+			for u := range ExpandURLTemplate(ref.URL) {
+				if excludedURL(u, opts.Exclude) {
+					excluded++
+					continue
+				}
+				if seen != nil {
+					if _, dup := seen[u]; dup {
+						continue
+					}
+					seen[u] = struct{}{}
+				}
 
-			// Try to send the line, but respect context cancellation
-			select {
-			case <-ctx.Done():
-				return
-			case out <- line:
+				expanded := ref
+				expanded.URL = u
+				// Try to send the URL, but respect context cancellation
+				select {
+				case <-ctx.Done():
+					stopErr = ctx.Err()
+					return
+				case out <- emit(expanded):
+				}
 			}
 		}
 
 		if err := scanner.Err(); err != nil {
-			log.Printf("error reading article list from %s: %v", filePath, err)
+			logger.Error("error reading article list", "path", name, "error", err)
+			stopErr = fmt.Errorf("read article list %s: %w", name, err)
 		}
 	}()
 
-	return out, nil
+	return out, done
+}
+
+// excludedURL reports whether u matches any of the patterns.
+func excludedURL(u string, patterns []*regexp.Regexp) bool {
+	for _, re := range patterns {
+		if re.MatchString(u) {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,305 @@
+package articles
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func writeList(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "urls.txt")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write list: %v", err)
+	}
+	return path
+}
+
+func drain(ch <-chan string) []string {
+	var out []string
+	for v := range ch {
+		out = append(out, v)
+	}
+	return out
+}
+
+func assertURLs(t *testing.T, got, want []string) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, got)
+		}
+	}
+}
+
+const duplicateList = "https://a.example/1\nhttps://b.example/2\n\nhttps://a.example/1\nhttps://c.example/3\nhttps://b.example/2\n"
+
+func TestListFromFileKeepsDuplicatesByDefault(t *testing.T) {
+	ch, err := ListFromFile(context.Background(), writeList(t, duplicateList))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	assertURLs(t, drain(ch), []string{
+		"https://a.example/1",
+		"https://b.example/2",
+		"https://a.example/1",
+		"https://c.example/3",
+		"https://b.example/2",
+	})
+}
+
+func TestListFromFileDedupe(t *testing.T) {
+	ch, err := ListFromFileWithOptions(context.Background(), writeList(t, duplicateList), ListOptions{Dedupe: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	assertURLs(t, drain(ch), []string{
+		"https://a.example/1",
+		"https://b.example/2",
+		"https://c.example/3",
+	})
+}
+
+func TestListBufferSize(t *testing.T) {
+	ch, err := ListFromFile(context.Background(), writeList(t, duplicateList))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cap(ch) != DefaultListBufferSize {
+		t.Fatalf("expected default capacity %d, got %d", DefaultListBufferSize, cap(ch))
+	}
+	drain(ch)
+
+	ch, err = ListFromFileWithOptions(context.Background(), writeList(t, duplicateList), ListOptions{BufferSize: 4})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cap(ch) != 4 {
+		t.Fatalf("expected configured capacity 4, got %d", cap(ch))
+	}
+	if got := drain(ch); len(got) != 5 {
+		t.Fatalf("expected all 5 URLs through the smaller buffer, got %v", got)
+	}
+}
+
+func TestListExcludePatterns(t *testing.T) {
+	list := "https://a.example/paper.pdf\nhttps://a.example/article\nhttps://b.example/login?next=/\nhttps://b.example/report.PDF\nhttps://c.example/pdf-guide\n"
+	logger := &recordingLogger{}
+	opts := ListOptions{Exclude: []*regexp.Regexp{regexp.MustCompile(`\.pdf$`), regexp.MustCompile(`/login\b`)}, Logger: logger}
+
+	ch, err := ListFromFileWithOptions(context.Background(), writeList(t, list), opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertURLs(t, drain(ch), []string{
+		"https://a.example/article",
+		"https://b.example/report.PDF",
+		"https://c.example/pdf-guide",
+	})
+
+	assertURLs(t, drain(ListFromReaderWithOptions(context.Background(), strings.NewReader(list), opts)), []string{
+		"https://a.example/article",
+		"https://b.example/report.PDF",
+		"https://c.example/pdf-guide",
+	})
+	if _, ok := logger.find("excluded article list URLs"); !ok {
+		t.Fatalf("expected the exclusions to be logged to the given logger, got %v", logger.records)
+	}
+}
+
+func TestListFromReader(t *testing.T) {
+	input := "https://a.example/1\n\n  https://b.example/2  \nhttps://c.example/3"
+
+	assertURLs(t, drain(ListFromReader(context.Background(), strings.NewReader(input))), []string{
+		"https://a.example/1",
+		"https://b.example/2",
+		"https://c.example/3",
+	})
+}
+
+func writeGzipList(t *testing.T, name, content string) string {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write([]byte(content)); err != nil {
+		t.Fatalf("compress list: %v", err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("compress list: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		t.Fatalf("write list: %v", err)
+	}
+	return path
+}
+
+func TestListFromFileGzip(t *testing.T) {
+	want := []string{"https://a.example/1", "https://b.example/2"}
+	for _, name := range []string{"urls.txt.gz", "urls.txt"} {
+		path := writeGzipList(t, name, "https://a.example/1\nhttps://b.example/2\n")
+
+		ch, err := ListFromFile(context.Background(), path)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+		assertURLs(t, drain(ch), want)
+	}
+}
+
+func TestListFromFileInvalidGzip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "urls.txt.gz")
+	if err := os.WriteFile(path, []byte("https://a.example/1\n"), 0o644); err != nil {
+		t.Fatalf("write list: %v", err)
+	}
+
+	if _, err := ListFromFile(context.Background(), path); err == nil {
+		t.Fatalf("expected error for invalid gzip list")
+	}
+}
+
+func TestCountList(t *testing.T) {
+	path := writeList(t, duplicateList)
+
+	n, err := CountList(context.Background(), path, ListOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n != 5 {
+		t.Fatalf("expected 5 URLs, got %d", n)
+	}
+
+	n, err = CountList(context.Background(), path, ListOptions{Dedupe: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n != 3 {
+		t.Fatalf("expected 3 distinct URLs, got %d", n)
+	}
+}
+
+const jsonlList = `{"url": "https://a.example/1", "tags": ["news", "tech"], "weight": 2}
+{"url": "https://b.example/2"}
+
+not json
+{"tags": ["orphan"]}
+{"url": "https://a.example/1", "tags": ["dup"]}
+`
+
+func TestRefsFromFileJSONLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "urls.jsonl")
+	if err := os.WriteFile(path, []byte(jsonlList), 0o644); err != nil {
+		t.Fatalf("write list: %v", err)
+	}
+
+	ch, err := RefsFromFile(context.Background(), path, ListOptions{Dedupe: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var got []ArticleRef
+	for ref := range ch {
+		got = append(got, ref)
+	}
+
+	want := []ArticleRef{
+		{URL: "https://a.example/1", Tags: []string{"news", "tech"}, Weight: 2},
+		{URL: "https://b.example/2"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %+v, got %+v", want, got)
+	}
+}
+
+func TestListFromFileJSONLinesStreamsURLs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "urls.jsonl")
+	if err := os.WriteFile(path, []byte(jsonlList), 0o644); err != nil {
+		t.Fatalf("write list: %v", err)
+	}
+
+	ch, err := ListFromFile(context.Background(), path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertURLs(t, drain(ch), []string{"https://a.example/1", "https://b.example/2", "https://a.example/1"})
+}
+
+func TestRefsFromFilePlainText(t *testing.T) {
+	ch, err := RefsFromFile(context.Background(), writeList(t, "https://a.example/1\n"), ListOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var got []ArticleRef
+	for ref := range ch {
+		got = append(got, ref)
+	}
+	if !reflect.DeepEqual(got, []ArticleRef{{URL: "https://a.example/1"}}) {
+		t.Fatalf("expected a single URL-only ref, got %+v", got)
+	}
+}
+
+func TestListFromFileWithDoneReportsEOF(t *testing.T) {
+	ch, done, err := ListFromFileWithDone(context.Background(), writeList(t, duplicateList), ListOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := len(drain(ch)); got != 5 {
+		t.Fatalf("expected 5 URLs, got %d", got)
+	}
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("expected nil at end of file, got %v", err)
+		}
+	default:
+		t.Fatal("expected completion to be reported once the channel closed")
+	}
+}
+
+func TestListFromFileWithDoneReportsCancellation(t *testing.T) {
+	var list strings.Builder
+	for i := 0; i < 5000; i++ {
+		list.WriteString("https://example.com/" + strconv.Itoa(i) + "\n")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ch, done, err := ListFromFileWithDone(ctx, writeList(t, list.String()), ListOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	<-ch
+	cancel()
+	received := 1 + len(drain(ch))
+	if received >= 5000 {
+		t.Fatalf("expected cancellation to cut the list short, got all %d URLs", received)
+	}
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestListFromReaderWithDoneReportsScanError(t *testing.T) {
+	long := strings.Repeat("a", 2*1024*1024)
+	ch, done := ListFromReaderWithDone(context.Background(), strings.NewReader("https://example.com/1\n"+long+"\n"), ListOptions{})
+
+	assertURLs(t, drain(ch), []string{"https://example.com/1"})
+	if err := <-done; err == nil || !errors.Is(err, bufio.ErrTooLong) {
+		t.Fatalf("expected bufio.ErrTooLong, got %v", err)
+	}
+}
@@ -0,0 +1,58 @@
+package articles
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+
+	"golang.org/x/net/proxy"
+)
+
+// proxyTransport returns a copy of base that routes every connection through
+// the proxy at rawURL. http and https proxies are used via CONNECT or absolute
+// request URIs; socks5 and socks5h proxies are dialed directly. A nil base
+// means http.DefaultTransport. An invalid proxy URL yields a transport that
+// fails every request, so misconfiguration is never silently bypassed.
+func proxyTransport(base *http.Transport, rawURL string) *http.Transport {
+	if base == nil {
+		base = http.DefaultTransport.(*http.Transport)
+	}
+	transport := base.Clone()
+
+	proxyURL, err := url.Parse(rawURL)
+	if err != nil {
+		return failingTransport(transport, fmt.Errorf("parse proxy URL: %w", err))
+	}
+
+	switch proxyURL.Scheme {
+	case "http", "https":
+		transport.Proxy = http.ProxyURL(proxyURL)
+	case "socks5", "socks5h":
+		dialer, err := proxy.FromURL(proxyURL, proxy.Direct)
+		if err != nil {
+			return failingTransport(transport, fmt.Errorf("configure SOCKS proxy: %w", err))
+		}
+		transport.Proxy = nil
+		if contextDialer, ok := dialer.(proxy.ContextDialer); ok {
+			transport.DialContext = contextDialer.DialContext
+		} else {
+			transport.DialContext = func(_ context.Context, network, addr string) (net.Conn, error) {
+				return dialer.Dial(network, addr)
+			}
+		}
+	default:
+		return failingTransport(transport, fmt.Errorf("unsupported proxy scheme %q", proxyURL.Scheme))
+	}
+
+	return transport
+}
+
+// failingTransport makes every request through transport fail with err.
+func failingTransport(transport *http.Transport, err error) *http.Transport {
+	transport.Proxy = func(*http.Request) (*url.URL, error) {
+		return nil, err
+	}
+	return transport
+}
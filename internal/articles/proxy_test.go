@@ -0,0 +1,127 @@
+package articles
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/shoresh319/firefly/internal/logging"
+)
+
+func TestFetchThroughHTTPProxy(t *testing.T) {
+	var mu sync.Mutex
+	var proxied []string
+	proxySrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		proxied = append(proxied, r.URL.String())
+		mu.Unlock()
+		w.Write([]byte(testHTML))
+	}))
+	defer proxySrv.Close()
+
+	source := NewSource(SourceConfig{ProxyURL: proxySrv.URL, Logger: logging.Nop()})
+
+	text, err := source.Fetch(context.Background(), "http://article.invalid/page")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(text, "Quick brown fox") {
+		t.Fatalf("expected proxied article text, got %q", text)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(proxied) != 1 || proxied[0] != "http://article.invalid/page" {
+		t.Fatalf("expected one request for the article via the proxy, got %v", proxied)
+	}
+}
+
+func TestFetchThroughSOCKS5Proxy(t *testing.T) {
+	articleSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(testHTML))
+	}))
+	defer articleSrv.Close()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer listener.Close()
+
+	targets := make(chan string, 1)
+	go serveSOCKS5(listener, articleSrv.Listener.Addr().String(), targets)
+
+	source := NewSource(SourceConfig{ProxyURL: "socks5h://" + listener.Addr().String(), Logger: logging.Nop()})
+
+	text, err := source.Fetch(context.Background(), "http://article.invalid:80/page")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(text, "Quick brown fox") {
+		t.Fatalf("expected proxied article text, got %q", text)
+	}
+	if target := <-targets; target != "article.invalid:80" {
+		t.Fatalf("expected proxy to be asked for article.invalid:80, got %q", target)
+	}
+}
+
+func TestFetchUnsupportedProxyScheme(t *testing.T) {
+	source := NewSource(SourceConfig{ProxyURL: "ftp://proxy.invalid", Logger: logging.Nop()})
+
+	_, err := source.Fetch(context.Background(), "http://article.invalid/page")
+	if err == nil || !strings.Contains(err.Error(), "unsupported proxy scheme") {
+		t.Fatalf("expected unsupported proxy scheme error, got %v", err)
+	}
+}
+
+// serveSOCKS5 accepts a single unauthenticated SOCKS5 CONNECT with a domain
+// name target, reports the requested target and tunnels it to upstream.
+func serveSOCKS5(listener net.Listener, upstream string, targets chan<- string) {
+	conn, err := listener.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	// Greeting: version, method count, methods. Reply "no authentication".
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return
+	}
+	if _, err := io.ReadFull(conn, make([]byte, header[1])); err != nil {
+		return
+	}
+	conn.Write([]byte{5, 0})
+
+	// Request: version, command, reserved, address type 3 (domain name).
+	request := make([]byte, 5)
+	if _, err := io.ReadFull(conn, request); err != nil || request[3] != 3 {
+		return
+	}
+	host := make([]byte, request[4])
+	port := make([]byte, 2)
+	if _, err := io.ReadFull(conn, host); err != nil {
+		return
+	}
+	if _, err := io.ReadFull(conn, port); err != nil {
+		return
+	}
+	targets <- net.JoinHostPort(string(host), strconv.Itoa(int(binary.BigEndian.Uint16(port))))
+
+	target, err := net.Dial("tcp", upstream)
+	if err != nil {
+		return
+	}
+	defer target.Close()
+	conn.Write([]byte{5, 0, 0, 1, 0, 0, 0, 0, 0, 0})
+
+	go io.Copy(target, conn)
+	io.Copy(conn, target)
+}
@@ -0,0 +1,120 @@
+package articles
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/shoresh319/firefly/internal/processing"
+)
+
+// RecordMode selects how a Recorder uses its cassette.
+type RecordMode int
+
+const (
+	// RecordAll fetches every URL through the wrapped fetcher and saves the
+	// text, replacing any earlier recording of the same URL.
+	RecordAll RecordMode = iota
+	// ReplayOnly serves URLs from the cassette and never touches the network.
+	// URLs that were not recorded fail with ErrNotRecorded.
+	ReplayOnly
+	// ReplayOrRecord serves recorded URLs from the cassette and fetches and
+	// records the rest.
+	ReplayOrRecord
+)
+
+// ErrNotRecorded is returned in ReplayOnly mode for URLs missing from the
+// cassette.
+var ErrNotRecorded = errors.New("url not recorded")
+
+// Recorder is a processing.ArticleFetcher that records the text fetched by
+// another fetcher to a JSON cassette file of {url: text} pairs and replays it
+// later without network access, for deterministic tests and offline runs.
+// Failed fetches are not recorded. It is safe for concurrent use.
+type Recorder struct {
+	inner processing.ArticleFetcher
+	path  string
+	mode  RecordMode
+
+	mu       sync.Mutex
+	cassette map[string]string
+}
+
+// NewRecorder wraps inner with a cassette stored at path. An existing cassette
+// is loaded; a missing one starts empty except in ReplayOnly mode, where it is
+// an error. inner may be nil in ReplayOnly mode.
+func NewRecorder(inner processing.ArticleFetcher, path string, mode RecordMode) (*Recorder, error) {
+	cassette := make(map[string]string)
+	data, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist) && mode != ReplayOnly:
+	case err != nil:
+		return nil, fmt.Errorf("read cassette: %w", err)
+	default:
+		if err := json.Unmarshal(data, &cassette); err != nil {
+			return nil, fmt.Errorf("parse cassette %s: %w", path, err)
+		}
+	}
+
+	return &Recorder{inner: inner, path: path, mode: mode, cassette: cassette}, nil
+}
+
+// Fetch returns the text for url according to the recorder's mode.
+func (r *Recorder) Fetch(ctx context.Context, url string) (string, error) {
+	if r.mode != RecordAll {
+		r.mu.Lock()
+		text, ok := r.cassette[url]
+		r.mu.Unlock()
+		if ok {
+			return text, nil
+		}
+		if r.mode == ReplayOnly {
+			return "", fmt.Errorf("replay %s: %w", url, ErrNotRecorded)
+		}
+	}
+
+	text, err := r.inner.Fetch(ctx, url)
+	if err != nil {
+		return "", err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cassette[url] = text
+	if err := r.save(); err != nil {
+		return "", err
+	}
+	return text, nil
+}
+
+// save writes the cassette to a temporary file and renames it into place, so
+// an interrupted run never leaves a truncated cassette. Callers hold r.mu.
+func (r *Recorder) save() error {
+	data, err := json.MarshalIndent(r.cassette, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode cassette: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(r.path), filepath.Base(r.path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("create cassette: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write cassette: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("close cassette: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), r.path); err != nil {
+		return fmt.Errorf("replace cassette: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package articles
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestRecorderRecordThenReplay(t *testing.T) {
+	var hits int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		_, _ = w.Write([]byte(testHTML))
+	}))
+	defer srv.Close()
+
+	path := filepath.Join(t.TempDir(), "cassette.json")
+
+	recorder, err := NewRecorder(newTestSource(), path, RecordAll)
+	if err != nil {
+		t.Fatalf("new recorder: %v", err)
+	}
+	recorded, err := recorder.Fetch(context.Background(), srv.URL)
+	if err != nil {
+		t.Fatalf("record: %v", err)
+	}
+	if !strings.Contains(recorded, "lazy dog") {
+		t.Fatalf("unexpected recorded text %q", recorded)
+	}
+
+	srv.Close()
+
+	replayer, err := NewRecorder(nil, path, ReplayOnly)
+	if err != nil {
+		t.Fatalf("new replayer: %v", err)
+	}
+	replayed, err := replayer.Fetch(context.Background(), srv.URL)
+	if err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	if replayed != recorded {
+		t.Fatalf("expected %q, got %q", recorded, replayed)
+	}
+	if n := atomic.LoadInt32(&hits); n != 1 {
+		t.Fatalf("expected a single network fetch, got %d", n)
+	}
+
+	if _, err := replayer.Fetch(context.Background(), srv.URL+"/other"); !errors.Is(err, ErrNotRecorded) {
+		t.Fatalf("expected ErrNotRecorded, got %v", err)
+	}
+}
+
+func TestRecorderReplayOrRecord(t *testing.T) {
+	var hits int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		_, _ = w.Write([]byte(testHTML))
+	}))
+	defer srv.Close()
+
+	recorder, err := NewRecorder(newTestSource(), filepath.Join(t.TempDir(), "cassette.json"), ReplayOrRecord)
+	if err != nil {
+		t.Fatalf("new recorder: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := recorder.Fetch(context.Background(), srv.URL); err != nil {
+			t.Fatalf("fetch %d: %v", i, err)
+		}
+	}
+	if n := atomic.LoadInt32(&hits); n != 1 {
+		t.Fatalf("expected later fetches to replay, got %d network fetches", n)
+	}
+}
+
+func TestRecorderReplayOnlyRequiresCassette(t *testing.T) {
+	if _, err := NewRecorder(nil, filepath.Join(t.TempDir(), "missing.json"), ReplayOnly); err == nil {
+		t.Fatal("expected error for a missing cassette in replay-only mode")
+	}
+}
@@ -0,0 +1,52 @@
+package articles
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// ErrTooManyRedirects is returned by Fetch when a response redirects more
+// times than SourceConfig.MaxRedirects allows.
+var ErrTooManyRedirects = errors.New("too many redirects")
+
+// limitRedirects returns a CheckRedirect policy that fails once max redirects
+// have been followed, deferring to next, if any, for the ones it allows.
+func limitRedirects(max int, next func(*http.Request, []*http.Request) error) func(*http.Request, []*http.Request) error {
+	return func(req *http.Request, via []*http.Request) error {
+		if len(via) > max {
+			return fmt.Errorf("stopped after %d redirects: %w", max, ErrTooManyRedirects)
+		}
+		if next != nil {
+			return next(req, via)
+		}
+		return nil
+	}
+}
+
+// acceptsRedirect reports whether accepted contains a 3xx status.
+func acceptsRedirect(accepted map[int]struct{}) bool {
+	for code := range accepted {
+		if code >= 300 && code < 400 {
+			return true
+		}
+	}
+	return false
+}
+
+// stopAtAcceptedRedirects returns a CheckRedirect policy that keeps redirect
+// responses whose status is accepted instead of following them, deferring to
+// next, if any, for the others.
+func stopAtAcceptedRedirects(accepted map[int]struct{}, next func(*http.Request, []*http.Request) error) func(*http.Request, []*http.Request) error {
+	return func(req *http.Request, via []*http.Request) error {
+		if req.Response != nil {
+			if _, ok := accepted[req.Response.StatusCode]; ok {
+				return http.ErrUseLastResponse
+			}
+		}
+		if next != nil {
+			return next(req, via)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,98 @@
+package articles
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/shoresh319/firefly/internal/logging"
+)
+
+// redirectChain redirects /hop/N to /hop/N-1 until /hop/0 serves testHTML.
+func redirectChain(calls *int32) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(calls, 1)
+		n, _ := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/hop/"))
+		if n > 0 {
+			http.Redirect(w, r, fmt.Sprintf("/hop/%d", n-1), http.StatusFound)
+			return
+		}
+		_, _ = w.Write([]byte(testHTML))
+	})
+}
+
+func TestFetchMaxRedirectsExceeded(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(redirectChain(&calls))
+	defer srv.Close()
+
+	source := NewSource(SourceConfig{MaxRedirects: 2, RetryMax: 3, Logger: logging.Nop()})
+
+	_, err := source.Fetch(context.Background(), srv.URL+"/hop/5")
+	if !errors.Is(err, ErrTooManyRedirects) {
+		t.Fatalf("expected ErrTooManyRedirects, got %v", err)
+	}
+	if n := atomic.LoadInt32(&calls); n != 3 {
+		t.Fatalf("expected the original request plus 2 redirects without retries, got %d requests", n)
+	}
+}
+
+func TestFetchWithMetaReportsFinalURL(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(redirectChain(&calls))
+	defer srv.Close()
+
+	source := NewSource(SourceConfig{MaxRedirects: 2, Logger: logging.Nop()})
+
+	result, err := source.FetchWithMeta(context.Background(), srv.URL+"/hop/2")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.FinalURL != srv.URL+"/hop/0" {
+		t.Fatalf("expected final URL %s, got %s", srv.URL+"/hop/0", result.FinalURL)
+	}
+	if result.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", result.StatusCode)
+	}
+	if !strings.Contains(result.Text, "lazy dog") {
+		t.Fatalf("unexpected text %q", result.Text)
+	}
+}
+
+func TestFetchWithMetaReportsFailedStatus(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+
+	result, err := newTestSource().FetchWithMeta(context.Background(), srv.URL+"/gone")
+	if err == nil {
+		t.Fatal("expected error for 404 response")
+	}
+	if result.StatusCode != http.StatusNotFound || result.FinalURL != srv.URL+"/gone" {
+		t.Fatalf("expected 404 metadata, got %+v", result)
+	}
+}
+
+func TestAcceptStatusCodesKeepsRedirects(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(redirectChain(&calls))
+	defer srv.Close()
+
+	source := NewSource(SourceConfig{AcceptStatusCodes: []int{200, http.StatusFound}, Logger: logging.Nop()})
+
+	result, err := source.FetchWithMeta(context.Background(), srv.URL+"/hop/3")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.StatusCode != http.StatusFound || result.FinalURL != srv.URL+"/hop/3" {
+		t.Fatalf("expected the 302 from /hop/3 to be recorded, got %d from %s", result.StatusCode, result.FinalURL)
+	}
+	if calls != 1 {
+		t.Fatalf("expected the redirect not to be followed, got %d requests", calls)
+	}
+}
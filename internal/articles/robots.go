@@ -0,0 +1,298 @@
+package articles
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shoresh319/firefly/internal/logging"
+)
+
+// DefaultRobotsCacheTTL is how long a parsed robots.txt is reused when
+// SourceConfig.RobotsCacheTTL is zero.
+const DefaultRobotsCacheTTL = time.Hour
+
+// maxRobotsSize bounds how much of a robots.txt is read; RFC 9309 asks
+// crawlers to parse at least 500 KiB.
+const maxRobotsSize = 512 << 10
+
+// robotsRule is a single Allow or Disallow line.
+type robotsRule struct {
+	allow   bool
+	pattern string
+}
+
+// robotsRules are the rules of the robots.txt group that applies to us. A nil
+// *robotsRules allows everything.
+type robotsRules struct {
+	rules []robotsRule
+}
+
+// allowed reports whether path, including any query, may be fetched. The
+// longest matching pattern wins, and Allow wins a tie.
+func (r *robotsRules) allowed(path string) bool {
+	if r == nil {
+		return true
+	}
+	best, allow := -1, true
+	for _, rule := range r.rules {
+		if !robotsMatch(rule.pattern, path) {
+			continue
+		}
+		if n := len(rule.pattern); n > best || (n == best && rule.allow) {
+			best, allow = n, rule.allow
+		}
+	}
+	return allow
+}
+
+// robotsMatch matches path against a robots.txt pattern, where * matches any
+// run of characters and a trailing $ anchors the end of the path.
+func robotsMatch(pattern, path string) bool {
+	anchored := strings.HasSuffix(pattern, "$")
+	return wildcardMatch(strings.TrimSuffix(pattern, "$"), path, anchored)
+}
+
+func wildcardMatch(pattern, path string, anchored bool) bool {
+	for pattern != "" {
+		if pattern[0] == '*' {
+			pattern = strings.TrimLeft(pattern, "*")
+			if pattern == "" && !anchored {
+				return true
+			}
+			for i := 0; i <= len(path); i++ {
+				if wildcardMatch(pattern, path[i:], anchored) {
+					return true
+				}
+			}
+			return false
+		}
+		if path == "" || path[0] != pattern[0] {
+			return false
+		}
+		pattern, path = pattern[1:], path[1:]
+	}
+	return !anchored || path == ""
+}
+
+// parseRobots reads a robots.txt and returns the rules for the group naming
+// agent, falling back to the * group. agent is matched case-insensitively
+// against each User-agent value as a substring, and the longest matching
+// value wins.
+func parseRobots(r io.Reader, agent string) (*robotsRules, error) {
+	agent = strings.ToLower(agent)
+
+	var (
+		groupAgents []string
+		inRules     bool
+		best        = -1
+		bestRules   []robotsRule
+		wildcard    []robotsRule
+		haveWild    bool
+		current     []robotsRule
+	)
+	flush := func() {
+		for _, a := range groupAgents {
+			switch {
+			case a == "*":
+				wildcard = append(wildcard, current...)
+				haveWild = true
+			case agent != "" && strings.Contains(agent, a) && len(a) >= best:
+				if len(a) > best {
+					bestRules = nil
+				}
+				best = len(a)
+				bestRules = append(bestRules, current...)
+			}
+		}
+		groupAgents, current, inRules = nil, nil, false
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 4096), maxRobotsSize)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "user-agent":
+			if inRules {
+				flush()
+			}
+			groupAgents = append(groupAgents, strings.ToLower(value))
+		case "allow", "disallow":
+			inRules = true
+			// An empty Disallow allows everything and adds nothing.
+			if value != "" {
+				current = append(current, robotsRule{allow: key == "allow", pattern: value})
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("scan robots.txt: %w", err)
+	}
+	flush()
+
+	if best >= 0 {
+		return &robotsRules{rules: bestRules}, nil
+	}
+	if haveWild {
+		return &robotsRules{rules: wildcard}, nil
+	}
+	return nil, nil
+}
+
+// robotsCache holds the parsed robots.txt of each origin for a TTL. Concurrent
+// lookups for the same origin share a single download.
+type robotsCache struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*robotsEntry
+}
+
+type robotsEntry struct {
+	ready   chan struct{} // closed once the download has finished
+	rules   *robotsRules
+	expires time.Time
+	failed  bool // the download was cancelled and nothing was cached
+}
+
+func newRobotsCache(ttl time.Duration) *robotsCache {
+	if ttl <= 0 {
+		ttl = DefaultRobotsCacheTTL
+	}
+	return &robotsCache{ttl: ttl, now: time.Now, entries: make(map[string]*robotsEntry)}
+}
+
+// rules returns the cached rules for origin, calling load to fill the cache
+// when they are missing or expired. A load cut short by cancellation of ctx
+// is not cached.
+func (c *robotsCache) rules(ctx context.Context, origin string, load func() *robotsRules) (*robotsRules, error) {
+	for {
+		c.mu.Lock()
+		entry, ok := c.entries[origin]
+		if ok && entry.isReady() && c.now().After(entry.expires) {
+			ok = false
+		}
+		if !ok {
+			entry = &robotsEntry{ready: make(chan struct{})}
+			c.entries[origin] = entry
+			c.mu.Unlock()
+			return c.fill(ctx, origin, entry, load)
+		}
+		c.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-entry.ready:
+		}
+		if !entry.failed {
+			return entry.rules, nil
+		}
+	}
+}
+
+func (c *robotsCache) fill(ctx context.Context, origin string, entry *robotsEntry, load func() *robotsRules) (*robotsRules, error) {
+	rules := load()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	defer close(entry.ready)
+	if err := ctx.Err(); err != nil {
+		entry.failed = true
+		delete(c.entries, origin)
+		return nil, err
+	}
+	entry.rules, entry.expires = rules, c.now().Add(c.ttl)
+	return rules, nil
+}
+
+func (e *robotsEntry) isReady() bool {
+	select {
+	case <-e.ready:
+		return true
+	default:
+		return false
+	}
+}
+
+// checkRobots fails with ErrDisallowedByRobots when urlStr's origin forbids it
+// in robots.txt. It does nothing unless SourceConfig.RespectRobotsTxt is set.
+func (s *Source) checkRobots(ctx context.Context, urlStr string) error {
+	if s.robots == nil {
+		return nil
+	}
+	parsed, err := url.Parse(urlStr)
+	if err != nil {
+		return fmt.Errorf("parse URL: %w", err)
+	}
+	origin := parsed.Scheme + "://" + parsed.Host
+
+	rules, err := s.robots.rules(ctx, origin, func() *robotsRules {
+		return s.loadRobots(ctx, origin)
+	})
+	if err != nil {
+		return err
+	}
+
+	path := parsed.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	if parsed.RawQuery != "" {
+		path += "?" + parsed.RawQuery
+	}
+	if !rules.allowed(path) {
+		logging.FromContext(ctx, s.logger).Info("skipping fetch disallowed by robots.txt", "url", urlStr)
+		return robotsError(urlStr)
+	}
+	return nil
+}
+
+// loadRobots downloads and parses origin's robots.txt. A missing or
+// unreadable file allows everything, as RFC 9309 prescribes for 4xx replies;
+// other failures are logged and also allow everything rather than stall the
+// crawl on one misbehaving host.
+func (s *Source) loadRobots(ctx context.Context, origin string) *robotsRules {
+	robotsURL := origin + "/robots.txt"
+	logger := logging.FromContext(ctx, s.logger)
+	resp, err := s.get(ctx, robotsURL, nil)
+	if err != nil {
+		logger.Warn("failed to fetch robots.txt", "url", robotsURL, "error", err)
+		return nil
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		if resp.StatusCode >= http.StatusInternalServerError {
+			logger.Warn("unexpected robots.txt status", "url", robotsURL, "status", resp.StatusCode)
+		}
+		return nil
+	}
+
+	reader, err := decodeContent(resp.Body, resp.Header.Values("Content-Encoding"))
+	if err != nil {
+		logger.Warn("failed to decode robots.txt", "url", robotsURL, "error", err)
+		return nil
+	}
+	rules, err := parseRobots(io.LimitReader(reader, maxRobotsSize), s.robotsAgent)
+	if err != nil {
+		logger.Warn("failed to parse robots.txt", "url", robotsURL, "error", err)
+		return nil
+	}
+	return rules
+}
@@ -0,0 +1,134 @@
+package articles
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/shoresh319/firefly/internal/logging"
+	"github.com/shoresh319/firefly/internal/processing"
+)
+
+func TestFetchSkipsURLDisallowedByRobots(t *testing.T) {
+	var robotsHits, privateHits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/robots.txt":
+			robotsHits.Add(1)
+			_, _ = w.Write([]byte("User-agent: *\nDisallow: /private/\n"))
+		case "/private/article":
+			privateHits.Add(1)
+			_, _ = w.Write([]byte(testHTML))
+		default:
+			_, _ = w.Write([]byte(testHTML))
+		}
+	}))
+	defer server.Close()
+
+	source := NewSource(SourceConfig{Logger: logging.Nop(), RespectRobotsTxt: true})
+
+	_, err := source.Fetch(context.Background(), server.URL+"/private/article")
+	if !errors.Is(err, ErrDisallowedByRobots) {
+		t.Fatalf("expected ErrDisallowedByRobots, got %v", err)
+	}
+	if !errors.Is(err, processing.ErrSkipped) {
+		t.Fatalf("expected error to wrap processing.ErrSkipped, got %v", err)
+	}
+	var fetchErr *FetchError
+	if !errors.As(err, &fetchErr) || fetchErr.Op != OpRobots {
+		t.Fatalf("expected FetchError with Op %q, got %v", OpRobots, err)
+	}
+	if privateHits.Load() != 0 {
+		t.Fatalf("expected disallowed URL not to be requested, got %d requests", privateHits.Load())
+	}
+
+	text, err := source.Fetch(context.Background(), server.URL+"/public/article")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(text, "Quick brown fox") {
+		t.Fatalf("expected article text, got %q", text)
+	}
+	if robotsHits.Load() != 1 {
+		t.Fatalf("expected robots.txt to be fetched once, got %d", robotsHits.Load())
+	}
+}
+
+func TestFetchIgnoresRobotsByDefault(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			t.Errorf("unexpected robots.txt request")
+		}
+		_, _ = w.Write([]byte(testHTML))
+	}))
+	defer server.Close()
+
+	source := NewSource(SourceConfig{Logger: logging.Nop()})
+	if _, err := source.Fetch(context.Background(), server.URL+"/private/article"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestFetchAllowsAllWhenRobotsMissing(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(testHTML))
+	}))
+	defer server.Close()
+
+	source := NewSource(SourceConfig{Logger: logging.Nop(), RespectRobotsTxt: true})
+	if _, err := source.Fetch(context.Background(), server.URL+"/private/article"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestParseRobots(t *testing.T) {
+	const robots = `
+# comments are ignored
+User-agent: *
+Disallow: /
+
+User-agent: firefly
+User-agent: other
+Disallow: /drafts/
+Allow: /drafts/public
+Disallow: /*.pdf$
+Disallow: /tmp*/cache
+`
+	rules, err := parseRobots(strings.NewReader(robots), "Firefly/1.0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cases := map[string]bool{
+		"/":                    true,
+		"/articles/1":          true,
+		"/drafts/secret":       false,
+		"/drafts/public/1":     true,
+		"/papers/a.pdf":        false,
+		"/papers/a.pdf?page=2": true,
+		"/tmp-1/cache/x":       false,
+		"/tmp/other":           true,
+		"/drafts":              true,
+	}
+	for path, want := range cases {
+		if got := rules.allowed(path); got != want {
+			t.Fatalf("allowed(%q): expected %v, got %v", path, want, got)
+		}
+	}
+
+	wildcard, err := parseRobots(strings.NewReader(robots), "SomeBot/2.0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if wildcard.allowed("/articles/1") {
+		t.Fatalf("expected * group to disallow everything for other agents")
+	}
+}
@@ -0,0 +1,132 @@
+package articles
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/shoresh319/firefly/internal/logging"
+)
+
+// MaxSitemapDepth bounds how many levels of nested sitemap indexes
+// ListFromSitemap follows below the root sitemap.
+const MaxSitemapDepth = 3
+
+// Fetcher retrieves the raw body stored at a URL. *Source implements it.
+type Fetcher interface {
+	FetchRaw(ctx context.Context, url string) ([]byte, error)
+}
+
+// sitemapDoc covers both <urlset> sitemaps and <sitemapindex> indexes.
+type sitemapDoc struct {
+	URLs     []sitemapLoc `xml:"url"`
+	Sitemaps []sitemapLoc `xml:"sitemap"`
+}
+
+type sitemapLoc struct {
+	Loc string `xml:"loc"`
+}
+
+// ListFromSitemap streams the page URLs listed in the sitemap at sitemapURL.
+// Sitemap indexes are followed recursively up to MaxSitemapDepth levels, and
+// gzip-compressed sitemaps such as sitemap.xml.gz are decompressed. The root
+// sitemap is fetched before returning so that an unreachable or malformed
+// root is reported as an error; failures in nested sitemaps are logged and
+// skipped.
+func ListFromSitemap(ctx context.Context, sitemapURL string, fetcher Fetcher) (<-chan string, error) {
+	return ListFromSitemapWithOptions(ctx, sitemapURL, fetcher, ListOptions{})
+}
+
+// ListFromSitemapWithOptions behaves like ListFromSitemap, logging nested
+// sitemap failures to opts.Logger and buffering opts.BufferSize URLs. The
+// other options apply only to article list files.
+func ListFromSitemapWithOptions(ctx context.Context, sitemapURL string, fetcher Fetcher, opts ListOptions) (<-chan string, error) {
+	root, err := fetchSitemap(ctx, sitemapURL, fetcher)
+	if err != nil {
+		return nil, err
+	}
+
+	bufferSize := opts.BufferSize
+	if bufferSize <= 0 {
+		bufferSize = DefaultListBufferSize
+	}
+	out := make(chan string, bufferSize)
+	go func() {
+		defer close(out)
+		walkSitemap(ctx, root, fetcher, opts.logger(), 0, out)
+	}()
+
+	return out, nil
+}
+
+// walkSitemap sends doc's page URLs on out and then descends into the
+// sitemaps it references, logging those it skips to logger. It reports false
+// once ctx is cancelled.
+func walkSitemap(ctx context.Context, doc sitemapDoc, fetcher Fetcher, logger logging.Logger, depth int, out chan<- string) bool {
+	for _, entry := range doc.URLs {
+		loc := strings.TrimSpace(entry.Loc)
+		if loc == "" {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return false
+		case out <- loc:
+		}
+	}
+
+	for _, entry := range doc.Sitemaps {
+		loc := strings.TrimSpace(entry.Loc)
+		if loc == "" {
+			continue
+		}
+		if depth >= MaxSitemapDepth {
+			logger.Warn("sitemap depth limit reached", "url", loc, "max_depth", MaxSitemapDepth)
+			continue
+		}
+		child, err := fetchSitemap(ctx, loc, fetcher)
+		if err != nil {
+			if ctx.Err() != nil {
+				return false
+			}
+			logger.Error("error reading sitemap", "url", loc, "error", err)
+			continue
+		}
+		if !walkSitemap(ctx, child, fetcher, logger, depth+1, out) {
+			return false
+		}
+	}
+	return true
+}
+
+// fetchSitemap downloads and parses the sitemap at url, transparently
+// decompressing gzip payloads.
+func fetchSitemap(ctx context.Context, url string, fetcher Fetcher) (sitemapDoc, error) {
+	body, err := fetcher.FetchRaw(ctx, url)
+	if err != nil {
+		return sitemapDoc{}, fmt.Errorf("fetch sitemap %s: %w", url, err)
+	}
+
+	// Compressed sitemaps are usually served as application/gzip rather than
+	// with a Content-Encoding, so sniff the gzip magic number.
+	if bytes.HasPrefix(body, []byte{0x1f, 0x8b}) {
+		zr, err := gzip.NewReader(bytes.NewReader(body))
+		if err != nil {
+			return sitemapDoc{}, fmt.Errorf("decompress sitemap %s: %w", url, err)
+		}
+		body, err = io.ReadAll(zr)
+		if err != nil {
+			return sitemapDoc{}, fmt.Errorf("decompress sitemap %s: %w", url, err)
+		}
+	}
+
+	var doc sitemapDoc
+	if err := xml.Unmarshal(body, &doc); err != nil {
+		return sitemapDoc{}, fmt.Errorf("parse sitemap %s: %w", url, err)
+	}
+	return doc, nil
+}
@@ -0,0 +1,109 @@
+package articles
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/shoresh319/firefly/internal/logging"
+)
+
+// mapFetcher serves canned bodies keyed by URL.
+type mapFetcher map[string][]byte
+
+func (f mapFetcher) FetchRaw(_ context.Context, url string) ([]byte, error) {
+	body, ok := f[url]
+	if !ok {
+		return nil, fmt.Errorf("no body for %s", url)
+	}
+	return body, nil
+}
+
+func readFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("read fixture: %v", err)
+	}
+	return data
+}
+
+var sitemapURLs = []string{
+	"https://example.com/articles/fireflies",
+	"https://example.com/articles/glow-worms",
+	"https://example.com/articles/bioluminescence",
+}
+
+func TestListFromSitemap(t *testing.T) {
+	fetcher := mapFetcher{"https://example.com/sitemap.xml": readFixture(t, "sitemap.xml")}
+
+	ch, err := ListFromSitemap(context.Background(), "https://example.com/sitemap.xml", fetcher)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertURLs(t, drain(ch), sitemapURLs)
+}
+
+func TestListFromSitemapIndex(t *testing.T) {
+	news := `<urlset><url><loc>https://example.com/news/1</loc></url></urlset>`
+	fetcher := mapFetcher{
+		"https://example.com/index.xml":   readFixture(t, "sitemap_index.xml"),
+		"https://example.com/sitemap.xml": readFixture(t, "sitemap.xml"),
+		"https://example.com/news.xml.gz": gzipBytes(t, []byte(news)),
+	}
+
+	ch, err := ListFromSitemap(context.Background(), "https://example.com/index.xml", fetcher)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertURLs(t, drain(ch), append(append([]string(nil), sitemapURLs...), "https://example.com/news/1"))
+}
+
+func TestListFromSitemapDepthLimit(t *testing.T) {
+	// An index that lists itself would recurse forever without the limit.
+	loop := `<sitemapindex><sitemap><loc>https://example.com/loop.xml</loc></sitemap></sitemapindex>`
+	fetcher := mapFetcher{"https://example.com/loop.xml": []byte(loop)}
+
+	logger := &recordingLogger{}
+	ch, err := ListFromSitemapWithOptions(context.Background(), "https://example.com/loop.xml", fetcher, ListOptions{Logger: logger})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := drain(ch); len(got) != 0 {
+		t.Fatalf("expected no URLs, got %v", got)
+	}
+	if _, ok := logger.find("sitemap depth limit reached"); !ok {
+		t.Fatalf("expected the depth limit to be logged to the given logger, got %v", logger.records)
+	}
+}
+
+func TestListFromSitemapRootErrors(t *testing.T) {
+	fetcher := mapFetcher{"https://example.com/broken.xml": []byte("<urlset><url>")}
+
+	if _, err := ListFromSitemap(context.Background(), "https://example.com/missing.xml", fetcher); err == nil {
+		t.Fatal("expected error for unreachable sitemap")
+	}
+	if _, err := ListFromSitemap(context.Background(), "https://example.com/broken.xml", fetcher); err == nil {
+		t.Fatal("expected error for malformed sitemap")
+	}
+}
+
+func TestListFromSitemapWithSource(t *testing.T) {
+	compressed := gzipBytes(t, readFixture(t, "sitemap.xml"))
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/gzip")
+		_, _ = w.Write(compressed)
+	}))
+	defer srv.Close()
+
+	source := NewSource(SourceConfig{Logger: logging.Nop()})
+	ch, err := ListFromSitemap(context.Background(), srv.URL+"/sitemap.xml.gz", source)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertURLs(t, drain(ch), sitemapURLs)
+}
@@ -1,36 +1,201 @@
 package articles
 
 import (
-	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"math"
+	"math/rand/v2"
 	"net/http"
 	"net/url"
+	"os"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/hashicorp/go-retryablehttp"
-	"golang.org/x/net/html"
+	"golang.org/x/time/rate"
+
+	"github.com/shoresh319/firefly/internal/logging"
+	"github.com/shoresh319/firefly/internal/metrics"
 )
 
 // SourceConfig holds configuration for the Source.
 type SourceConfig struct {
-	HTTPClient           *http.Client
-	RetryMax             int
-	RetryWaitMin         time.Duration
-	RetryWaitMax         time.Duration
+	HTTPClient   *http.Client
+	RetryMax     int
+	RetryWaitMin time.Duration
+	RetryWaitMax time.Duration
+	// RetryableStatusCodes lists the response codes that are retried, honoring
+	// Retry-After when present and otherwise backing off exponentially with
+	// jitter. Empty retries 429 and every 5xx except 501, as retryablehttp's
+	// default policy does. Transport errors are retried regardless.
+	RetryableStatusCodes []int
+	// Backoff picks the wait before each retry when the server sends no usable
+	// Retry-After, for transport errors and retryable statuses alike. Nil keeps
+	// the built-in policy; FullJitterBackoff decorrelates retries further.
+	Backoff              BackoffStrategy
 	ConcurrencyPerDomain int // Maximum concurrent requests per domain (default: 3)
+	// MaxTotalConcurrency caps in-flight requests across all domains. The global
+	// slot is acquired before the per-domain one. Zero means unlimited.
+	MaxTotalConcurrency int
+	// AdaptiveConcurrency turns ConcurrencyPerDomain into a ceiling: a domain's
+	// limit is halved on every 429 response, including ones that are retried,
+	// and grows back by about one slot per limit other responses. Source.
+	// DomainConcurrency reports the current limits.
+	AdaptiveConcurrency bool
+	// RequestsPerSecondPerDomain throttles throughput to each domain, allowing
+	// bursts of up to ceil(rate) requests. Fetches wait for a token rather than
+	// failing. Zero disables rate limiting.
+	RequestsPerSecondPerDomain float64
+	// JitterMax delays each fetch by a random duration up to this value before
+	// it competes for a concurrency slot, so workers that pick up URLs for the
+	// same domain together don't fire at once. Zero disables it.
+	JitterMax time.Duration
+	// CircuitBreakerThreshold is the number of consecutive failed fetches
+	// (transport errors, 429 or 5xx after retries) after which a domain is
+	// failed fast with ErrCircuitOpen for CircuitBreakerCooldown. Zero disables it.
+	CircuitBreakerThreshold int
+	CircuitBreakerCooldown  time.Duration
+	// UserAgent is sent with every request. When UserAgents is non-empty it takes
+	// precedence and its entries are rotated round-robin per fetch.
+	UserAgent  string
+	UserAgents []string
+	// AcceptEncoding is sent as the Accept-Encoding header of every request and
+	// the response is decompressed by Source itself, so Go's transparent gzip
+	// handling stays out of the way and body sizes are those on the wire.
+	// Empty uses DefaultAcceptEncoding. TransparentDecompression instead
+	// leaves the header to the HTTP transport, which asks for gzip only.
+	AcceptEncoding           string
+	TransparentDecompression bool
+	// Headers are added to every request, after the User-Agent, so they may
+	// override it. BasicAuth, when set, takes precedence over any
+	// Authorization header given here. Neither is ever logged.
+	Headers   http.Header
+	BasicAuth *BasicAuth
+	// CredentialProvider, when set, supplies a bearer token sent in the
+	// Authorization header, taking precedence over BasicAuth and Headers. It
+	// is called when a response is 401, and the request is retried once with
+	// the new token, which is then reused until another 401.
+	CredentialProvider CredentialProvider
+	// Cache enables conditional GETs: responses carrying an ETag or
+	// Last-Modified header are remembered, revalidated with If-None-Match or
+	// If-Modified-Since, and a 304 reply returns the cached text. Responses
+	// fresh per Cache-Control max-age or Expires are served from the cache
+	// until they expire, and no-store responses are not cached. Nil disables
+	// caching; NewMemoryCache provides an in-memory implementation.
+	Cache ResponseCache
+	// ContentOnly restricts extraction to text nested in ContentTags, ignoring
+	// anything inside SkipTags, to drop navigation and other boilerplate.
+	// Empty lists fall back to DefaultContentTags and DefaultSkipTags.
+	ContentOnly bool
+	ContentTags []string
+	SkipTags    []string
+	// DisableParseFallback makes documents the HTML parser rejects, such as
+	// ones nested more than 512 elements deep, fail the fetch. By default
+	// their tags are stripped crudely instead so their words still count.
+	DisableParseFallback bool
+	// NormalizePlainText collapses runs of whitespace in text/plain documents,
+	// which are otherwise counted exactly as served, without HTML parsing.
+	NormalizePlainText bool
+	// Extractor converts fetched bodies to text. Nil uses the built-in HTML
+	// extractor, which passes text/plain bodies through unparsed; when set,
+	// ContentOnly, the tag lists and NormalizePlainText are ignored.
+	Extractor TextExtractor
+	// AcceptedContentTypes lists the media type prefixes, such as "text/" or
+	// "application/xhtml+xml", whose HTTP responses are extracted. Others fail
+	// with ErrUnsupportedContentType without reading the body; responses with
+	// no Content-Type are accepted. Add types here when a custom Extractor
+	// handles them. Empty uses DefaultAcceptedContentTypes.
+	AcceptedContentTypes []string
+	// MaxIdleConns, MaxIdleConnsPerHost and IdleConnTimeout size the pool of
+	// reusable connections, and DisableKeepAlives turns reuse off entirely.
+	// When HTTPClient or its Transport is nil, NewSource builds a transport
+	// using DefaultMaxIdleConns, DefaultMaxIdleConnsPerHost and
+	// DefaultIdleConnTimeout for unset values. A caller's *http.Transport is
+	// copied with only the set values applied; other RoundTrippers are left
+	// alone.
+	MaxIdleConns        int
+	MaxIdleConnsPerHost int
+	IdleConnTimeout     time.Duration
+	DisableKeepAlives   bool
+	// ProxyURL routes requests through an http://, https:// or socks5:// proxy.
+	// It is applied to a copy of HTTPClient's *http.Transport, or of
+	// http.DefaultTransport when none is set. When empty, the HTTP_PROXY,
+	// HTTPS_PROXY and NO_PROXY environment variables are honored as usual.
+	ProxyURL string
+	// MaxRedirects fails a fetch with ErrTooManyRedirects once it has followed
+	// this many redirects. Zero keeps the HTTP client's own policy.
+	MaxRedirects int
+	// AcceptStatusCodes lists the HTTP statuses whose bodies are extracted,
+	// such as 203 or 206; other statuses fail with ErrUnexpectedStatus. Listed
+	// redirect statuses are not followed: the redirect itself is accepted, so
+	// its usually empty body is recorded instead of the target page. Empty
+	// uses DefaultAcceptStatusCodes.
+	AcceptStatusCodes []int
+	// RespectRobotsTxt downloads each origin's /robots.txt before fetching from
+	// it and skips URLs disallowed for UserAgent (the first of UserAgents when
+	// rotating), failing them with ErrDisallowedByRobots. Parsed files are
+	// cached for RobotsCacheTTL, or DefaultRobotsCacheTTL when zero.
+	RespectRobotsTxt bool
+	RobotsCacheTTL   time.Duration
+	// AllowLocalFiles reads file:// URLs and absolute paths from disk instead
+	// of failing them with ErrUnsupportedScheme. Leave it off when URLs come from untrusted callers,
+	// such as the HTTP service, or they could read any file the process can.
+	AllowLocalFiles bool
+	// AllowedLanguages, when set, skips articles whose detected language, an
+	// ISO 639-1 code such as "en", is not listed, failing them with
+	// ErrLanguageNotAllowed. Articles whose language cannot be told are kept.
+	// LanguageDetector replaces the built-in DetectLanguage.
+	AllowedLanguages []string
+	LanguageDetector func(text string) string
+	// PerRequestTimeout bounds each individual attempt, including reading the
+	// body. An attempt that times out is retried like any other transport error,
+	// so a fetch may take up to (RetryMax+1)*PerRequestTimeout plus backoff,
+	// still subject to HTTPClient.Timeout and the caller's context. Zero disables it.
+	PerRequestTimeout time.Duration
+	Logger            logging.Logger   // Structured logger (default: logging.Default())
+	Metrics           *metrics.Metrics // Optional fetch latency and retry instrumentation
+}
+
+// BasicAuth holds HTTP basic authentication credentials.
+type BasicAuth struct {
+	User string
+	Pass string
 }
 
 // Source fetches article content via HTTP with retry support for 429 errors.
 type Source struct {
 	client               *retryablehttp.Client
 	domainSemaphores     map[string]chan struct{} // Semaphore per domain for concurrency control
+	globalSemaphore      chan struct{}            // Shared semaphore across domains, nil when unlimited
+	domainLimiters       map[string]*rate.Limiter // Rate limiter per domain, guarded by mu
+	requestsPerSecond    float64
+	jitterMax            time.Duration
+	breaker              *circuitBreaker // Per-domain circuit breaker, nil when disabled
+	adaptive             *concurrencyController
+	userAgents           []string
+	acceptEncoding       string // Empty when the transport negotiates compression
+	headers              http.Header
+	basicAuth            *BasicAuth
+	credentials          *credentialCache
+	cache                ResponseCache
+	extractor            TextExtractor
+	acceptedContentTypes []string
+	acceptStatus         map[int]struct{}
+	robots               *robotsCache // Parsed robots.txt per origin, nil when not respected
+	robotsAgent          string
+	allowedLanguages     map[string]bool
+	allowLocalFiles      bool
+	detectLanguage       func(text string) string
+	nextUserAgent        atomic.Uint64
 	mu                   sync.RWMutex
 	concurrencyPerDomain int
+	logger               logging.Logger
+	metrics              *metrics.Metrics
 }
 
 // NewSource constructs a Source with retryable HTTP client configured for 429 handling.
@@ -45,57 +210,304 @@ func NewSource(cfg SourceConfig) *Source {
 		cfg.ConcurrencyPerDomain = 3 // Default: 3 concurrent requests per domain
 	}
 
+	if cfg.Logger == nil {
+		cfg.Logger = logging.Default()
+	}
+
+	httpClient := cfg.HTTPClient
+	tuning := transportTuning{
+		maxIdleConns:        cfg.MaxIdleConns,
+		maxIdleConnsPerHost: cfg.MaxIdleConnsPerHost,
+		idleConnTimeout:     cfg.IdleConnTimeout,
+		disableKeepAlives:   cfg.DisableKeepAlives,
+	}
+	if base, ok := httpClient.Transport.(*http.Transport); httpClient.Transport == nil || (ok && !tuning.isZero()) {
+		// Copy the client so the caller's transport isn't modified in place.
+		clone := *httpClient
+		clone.Transport = tunedTransport(base, tuning)
+		httpClient = &clone
+	} else if !ok && !tuning.isZero() {
+		cfg.Logger.Warn("connection pool settings ignored for custom HTTP transport")
+	}
+	if cfg.ProxyURL != "" {
+		base, ok := httpClient.Transport.(*http.Transport)
+		if ok || httpClient.Transport == nil {
+			// Copy the client so the caller's transport isn't modified in place.
+			clone := *httpClient
+			clone.Transport = proxyTransport(base, cfg.ProxyURL)
+			httpClient = &clone
+		} else {
+			cfg.Logger.Warn("proxy ignored for custom HTTP transport")
+		}
+	}
+	if cfg.MaxRedirects > 0 {
+		clone := *httpClient
+		clone.CheckRedirect = limitRedirects(cfg.MaxRedirects, httpClient.CheckRedirect)
+		httpClient = &clone
+	}
+	acceptStatus := statusSet(DefaultAcceptStatusCodes)
+	if len(cfg.AcceptStatusCodes) > 0 {
+		acceptStatus = statusSet(cfg.AcceptStatusCodes)
+	}
+	if acceptsRedirect(acceptStatus) {
+		clone := *httpClient
+		clone.CheckRedirect = stopAtAcceptedRedirects(acceptStatus, httpClient.CheckRedirect)
+		httpClient = &clone
+	}
+	if cfg.PerRequestTimeout > 0 {
+		// Copy the client so the caller's transport isn't modified in place.
+		clone := *httpClient
+		clone.Transport = &attemptTimeoutTransport{
+			next:    httpClient.Transport,
+			timeout: cfg.PerRequestTimeout,
+		}
+		httpClient = &clone
+	}
+
 	retryClient := retryablehttp.NewClient()
-	retryClient.HTTPClient = cfg.HTTPClient
+	retryClient.HTTPClient = httpClient
 	retryClient.RetryMax = cfg.RetryMax
 	retryClient.RetryWaitMin = cfg.RetryWaitMin
 	retryClient.RetryWaitMax = cfg.RetryWaitMax
-	retryClient.CheckRetry = func(ctx context.Context, resp *http.Response, err error) (bool, error) {
-		// Retry on 429 (Too Many Requests) errors
-		if resp != nil && resp.StatusCode == http.StatusTooManyRequests {
-			return true, nil
-		}
-		// Use default retry logic for other retryable errors
-		return retryablehttp.DefaultRetryPolicy(ctx, resp, err)
-	}
-	retryClient.Backoff = func(min, max time.Duration, attemptNum int, resp *http.Response) time.Duration {
-		// For 429 errors, use exponential backoff with jitter
-		if resp != nil && resp.StatusCode == http.StatusTooManyRequests {
-			// Check for Retry-After header (value is in seconds)
-			if retryAfter := resp.Header.Get("Retry-After"); retryAfter != "" {
-				if seconds, err := strconv.Atoi(retryAfter); err == nil {
-					duration := time.Duration(seconds) * time.Second
-					// Cap at max, but don't go below min
-					if duration > max {
-						return max
-					}
-					if duration < min {
-						return min
-					}
-					return duration
-				}
-			}
-			// Exponential backoff: 2^attemptNum seconds, capped at max
-			backoff := time.Duration(1<<uint(attemptNum)) * time.Second
-			if backoff > max {
-				backoff = max
-			}
-			if backoff < min {
-				backoff = min
-			}
-			return backoff
+	retryClient.Logger = cfg.Logger
+	retryClient.RequestLogHook = func(_ retryablehttp.Logger, req *http.Request, attempt int) {
+		if attempt > 0 {
+			logging.FromContext(req.Context(), cfg.Logger).Info("retrying fetch", "url", req.URL.String(), "attempt", attempt)
+			cfg.Metrics.RetryTriggered()
 		}
-		// Default exponential backoff for other errors
-		return retryablehttp.DefaultBackoff(min, max, attemptNum, resp)
+	}
+	adaptive := newConcurrencyController(cfg.AdaptiveConcurrency, cfg.ConcurrencyPerDomain)
+	if adaptive != nil {
+		// Every attempt counts, so 429s that are retried still slow the domain down.
+		retryClient.ResponseLogHook = func(_ retryablehttp.Logger, resp *http.Response) {
+			domain := resp.Request.URL.Hostname()
+			cfg.Metrics.SetDomainConcurrency(domain, adaptive.record(domain, resp.StatusCode))
+		}
+	}
+	retryable := defaultRetryableStatus
+	if len(cfg.RetryableStatusCodes) > 0 {
+		retryable = listedStatus(statusSet(cfg.RetryableStatusCodes))
+	}
+	retryClient.CheckRetry = checkRetry(retryable)
+	retryClient.Backoff = retryBackoff(retryable, cfg.Backoff)
+
+	var globalSemaphore chan struct{}
+	if cfg.MaxTotalConcurrency > 0 {
+		globalSemaphore = newSemaphore(cfg.MaxTotalConcurrency)
+	}
+
+	userAgents := append([]string(nil), cfg.UserAgents...)
+	if len(userAgents) == 0 && cfg.UserAgent != "" {
+		userAgents = []string{cfg.UserAgent}
+	}
+
+	extractor := cfg.Extractor
+	if extractor == nil {
+		var opts extractOptions
+		if cfg.ContentOnly {
+			opts = newContentOnlyOptions(cfg.ContentTags, cfg.SkipTags)
+		}
+		extractor = htmlExtractor{
+			opts:           opts,
+			noFallback:     cfg.DisableParseFallback,
+			normalizeSpace: cfg.NormalizePlainText,
+			logger:         cfg.Logger,
+		}
+	}
+
+	acceptedContentTypes := DefaultAcceptedContentTypes
+	if len(cfg.AcceptedContentTypes) > 0 {
+		acceptedContentTypes = cfg.AcceptedContentTypes
+	}
+
+	acceptEncoding := cfg.AcceptEncoding
+	if acceptEncoding == "" {
+		acceptEncoding = DefaultAcceptEncoding
+	}
+	if cfg.TransparentDecompression {
+		acceptEncoding = ""
+	}
+
+	var robots *robotsCache
+	if cfg.RespectRobotsTxt {
+		robots = newRobotsCache(cfg.RobotsCacheTTL)
+	}
+	var robotsAgent string
+	if len(userAgents) > 0 {
+		robotsAgent = userAgents[0]
+	}
+
+	var allowedLanguages map[string]bool
+	if len(cfg.AllowedLanguages) > 0 {
+		allowedLanguages = make(map[string]bool, len(cfg.AllowedLanguages))
+		for _, lang := range cfg.AllowedLanguages {
+			allowedLanguages[strings.ToLower(strings.TrimSpace(lang))] = true
+		}
+	}
+	detectLanguage := cfg.LanguageDetector
+	if detectLanguage == nil {
+		detectLanguage = DetectLanguage
 	}
 
 	return &Source{
 		client:               retryClient,
 		domainSemaphores:     make(map[string]chan struct{}),
+		globalSemaphore:      globalSemaphore,
+		domainLimiters:       make(map[string]*rate.Limiter),
+		requestsPerSecond:    cfg.RequestsPerSecondPerDomain,
+		jitterMax:            cfg.JitterMax,
+		breaker:              newCircuitBreaker(cfg.CircuitBreakerThreshold, cfg.CircuitBreakerCooldown),
+		adaptive:             adaptive,
+		userAgents:           userAgents,
+		acceptEncoding:       acceptEncoding,
+		headers:              cfg.Headers.Clone(),
+		basicAuth:            cfg.BasicAuth,
+		credentials:          newCredentialCache(cfg.CredentialProvider),
+		cache:                cfg.Cache,
+		extractor:            extractor,
+		acceptedContentTypes: acceptedContentTypes,
+		acceptStatus:         acceptStatus,
+		robots:               robots,
+		robotsAgent:          robotsAgent,
+		allowedLanguages:     allowedLanguages,
+		allowLocalFiles:      cfg.AllowLocalFiles,
+		detectLanguage:       detectLanguage,
 		concurrencyPerDomain: cfg.ConcurrencyPerDomain,
+		logger:               cfg.Logger,
+		metrics:              cfg.Metrics,
 	}
 }
 
+// DefaultAcceptEncoding lists the content codings requested when
+// SourceConfig.AcceptEncoding is empty: those decodeContent understands.
+const DefaultAcceptEncoding = "gzip, deflate"
+
+// DefaultAcceptStatusCodes are the statuses whose bodies are extracted when
+// SourceConfig.AcceptStatusCodes is empty.
+var DefaultAcceptStatusCodes = []int{http.StatusOK}
+
+func statusSet(codes []int) map[int]struct{} {
+	set := make(map[int]struct{}, len(codes))
+	for _, code := range codes {
+		set[code] = struct{}{}
+	}
+	return set
+}
+
+// defaultRetryableStatus reports whether code is retried when
+// SourceConfig.RetryableStatusCodes is empty: 429 and every 5xx except 501.
+func defaultRetryableStatus(code int) bool {
+	return code == http.StatusTooManyRequests || (code >= 500 && code != http.StatusNotImplemented)
+}
+
+// listedStatus reports whether a code is in set.
+func listedStatus(set map[int]struct{}) func(int) bool {
+	return func(code int) bool {
+		_, ok := set[code]
+		return ok
+	}
+}
+
+// checkRetry retries responses whose status retryable accepts. Transport
+// errors and cancellation are left to the default policy.
+func checkRetry(retryable func(int) bool) retryablehttp.CheckRetry {
+	return func(ctx context.Context, resp *http.Response, err error) (bool, error) {
+		if errors.Is(err, ErrTooManyRedirects) {
+			return false, nil
+		}
+		if err != nil || ctx.Err() != nil || resp == nil {
+			return retryablehttp.DefaultRetryPolicy(ctx, resp, err)
+		}
+		return retryable(resp.StatusCode), nil
+	}
+}
+
+// retryBackoff computes the wait before the next retry. For retryable status
+// codes it honors Retry-After, given either as seconds or as an HTTP date.
+// Otherwise strategy decides when set; without one, retryable statuses use
+// exponential backoff with jitter so that many workers hitting the same flaky
+// origin spread out. The result is kept within [min, max] and then cut short
+// by capToDeadline so no retry outlives the fetch's context.
+func retryBackoff(retryable func(int) bool, strategy BackoffStrategy) retryablehttp.Backoff {
+	backoff := func(min, max time.Duration, attemptNum int, resp *http.Response) time.Duration {
+		retryableStatus := retryable(statusCode(resp))
+		if retryableStatus {
+			if duration, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+				return clampDuration(duration, min, max)
+			}
+		}
+		if strategy != nil {
+			return clampDuration(strategy.Backoff(min, max, attemptNum), min, max)
+		}
+		if !retryableStatus {
+			return retryablehttp.DefaultBackoff(min, max, attemptNum, resp)
+		}
+		// Exponential backoff of 2^attemptNum seconds, capped at max before it
+		// can overflow and jittered down by up to half.
+		base := exponentialCap(time.Second, max, attemptNum)
+		jittered := base/2 + rand.N(base/2+1)
+		return clampDuration(jittered, min, max)
+	}
+	return func(min, max time.Duration, attemptNum int, resp *http.Response) time.Duration {
+		wait := backoff(min, max, attemptNum, resp)
+		if resp != nil && resp.Request != nil {
+			wait = capToDeadline(resp.Request.Context(), wait)
+		}
+		return wait
+	}
+}
+
+// parseRetryAfter interprets a Retry-After header value, which RFC 7231 allows
+// to be either a number of seconds or an HTTP date.
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if when, err := http.ParseTime(value); err == nil {
+		return time.Until(when), true
+	}
+	return 0, false
+}
+
+func clampDuration(d, min, max time.Duration) time.Duration {
+	if d > max {
+		return max
+	}
+	if d < min {
+		return min
+	}
+	return d
+}
+
+// userAgent returns the User-Agent for the next request, rotating through the
+// configured values. An empty string keeps the HTTP client's default.
+func (s *Source) userAgent() string {
+	if len(s.userAgents) == 0 {
+		return ""
+	}
+	n := s.nextUserAgent.Add(1) - 1
+	return s.userAgents[n%uint64(len(s.userAgents))]
+}
+
+// DomainConcurrency reports how many concurrent requests each domain fetched
+// from so far is allowed: the adaptive limit with AdaptiveConcurrency, or
+// ConcurrencyPerDomain otherwise.
+func (s *Source) DomainConcurrency() map[string]int {
+	if s.adaptive != nil {
+		return s.adaptive.limits()
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	limits := make(map[string]int, len(s.domainSemaphores))
+	for domain := range s.domainSemaphores {
+		limits[domain] = s.concurrencyPerDomain
+	}
+	return limits
+}
+
 // getDomainSemaphore returns a semaphore for the given domain to limit concurrent requests.
 func (s *Source) getDomainSemaphore(domain string) chan struct{} {
 	s.mu.RLock()
@@ -114,14 +526,48 @@ func (s *Source) getDomainSemaphore(domain string) chan struct{} {
 		return sem
 	}
 
-	// Create a buffered channel as a semaphore
-	// The channel capacity limits concurrent requests
-	sem = make(chan struct{}, s.concurrencyPerDomain)
+	sem = newSemaphore(s.concurrencyPerDomain)
+	s.domainSemaphores[domain] = sem
+	return sem
+}
+
+// getDomainLimiter returns the rate limiter for the given domain, or nil when
+// rate limiting is disabled.
+func (s *Source) getDomainLimiter(domain string) *rate.Limiter {
+	if s.requestsPerSecond <= 0 {
+		return nil
+	}
+
+	s.mu.RLock()
+	limiter, exists := s.domainLimiters[domain]
+	s.mu.RUnlock()
+
+	if exists {
+		return limiter
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Double-check after acquiring write lock
+	if limiter, exists := s.domainLimiters[domain]; exists {
+		return limiter
+	}
+
+	burst := int(math.Ceil(s.requestsPerSecond))
+	limiter = rate.NewLimiter(rate.Limit(s.requestsPerSecond), burst)
+	s.domainLimiters[domain] = limiter
+	return limiter
+}
+
+// newSemaphore creates a buffered channel used as a semaphore. The channel
+// capacity limits concurrent holders.
+func newSemaphore(size int) chan struct{} {
+	sem := make(chan struct{}, size)
 	// Pre-fill the semaphore with tokens to allow initial concurrent requests
-	for i := 0; i < s.concurrencyPerDomain; i++ {
+	for i := 0; i < size; i++ {
 		sem <- struct{}{}
 	}
-	s.domainSemaphores[domain] = sem
 	return sem
 }
 
@@ -137,61 +583,336 @@ func extractDomain(rawURL string) (string, error) {
 // Fetch retrieves the textual content of the article located at url.
 // It handles 429 errors with retries, using per-domain semaphores to limit
 // concurrent requests while allowing multiple workers per domain.
+// file:// URLs and absolute paths are read from disk instead when
+// SourceConfig.AllowLocalFiles is set.
 func (s *Source) Fetch(ctx context.Context, urlStr string) (string, error) {
-	domain, err := extractDomain(urlStr)
+	result, err := s.FetchWithMeta(ctx, urlStr)
+	return result.Text, err
+}
+
+// FetchResult describes a completed fetch.
+type FetchResult struct {
+	Text string
+	// FinalURL is the URL that produced the response, after any redirects.
+	FinalURL string
+	// StatusCode is the final HTTP status, or zero for local files.
+	StatusCode int
+}
+
+// FetchWithMeta behaves like Fetch but also reports where the content was
+// finally served from and with which status. When the fetch fails after a
+// response was received, the returned result still carries those fields.
+func (s *Source) FetchWithMeta(ctx context.Context, urlStr string) (FetchResult, error) {
+	result, err := s.fetchWithMeta(ctx, urlStr)
+	if err == nil {
+		if err = s.checkLanguage(ctx, urlStr, result.Text); err != nil {
+			result.Text = ""
+		}
+	}
+	return result, err
+}
+
+func (s *Source) fetchWithMeta(ctx context.Context, urlStr string) (FetchResult, error) {
+	path, local, err := s.localPath(urlStr)
 	if err != nil {
-		return "", err
+		return FetchResult{}, err
+	}
+	if local {
+		text, err := s.fetchFile(ctx, path)
+		return FetchResult{Text: text, FinalURL: urlStr}, err
 	}
 
-	// Acquire semaphore slot for this domain (allows N concurrent requests)
-	sem := s.getDomainSemaphore(domain)
-	select {
-	case <-ctx.Done():
-		return "", ctx.Err()
-	case <-sem: // Acquire semaphore
-		defer func() { sem <- struct{}{} }() // Release semaphore when done
+	if err := s.checkRobots(ctx, urlStr); err != nil {
+		return FetchResult{}, err
 	}
 
-	req, err := retryablehttp.NewRequestWithContext(ctx, http.MethodGet, urlStr, nil)
+	header := make(http.Header)
+	var cached CacheEntry
+	var haveCached bool
+	if s.cache != nil {
+		if cached, haveCached = s.cache.Get(urlStr); haveCached {
+			if cached.fresh(time.Now()) {
+				return FetchResult{Text: cached.Text, FinalURL: urlStr, StatusCode: http.StatusOK}, nil
+			}
+			if cached.ETag != "" {
+				header.Set("If-None-Match", cached.ETag)
+			}
+			if cached.LastModified != "" {
+				header.Set("If-Modified-Since", cached.LastModified)
+			}
+		}
+	}
+
+	resp, err := s.get(ctx, urlStr, header)
 	if err != nil {
-		return "", fmt.Errorf("create request: %w", err)
+		return FetchResult{}, err
 	}
+	defer resp.Body.Close()
 
-	resp, err := s.client.Do(req)
+	result := FetchResult{FinalURL: resp.Request.URL.String(), StatusCode: resp.StatusCode}
+
+	if resp.StatusCode == http.StatusNotModified && haveCached {
+		if store, expires := cachePolicy(resp.Header, time.Now()); store && !expires.Equal(cached.Expires) {
+			cached.Expires = expires
+			s.cache.Set(urlStr, cached)
+		}
+		result.Text = cached.Text
+		return result, nil
+	}
+
+	if _, ok := s.acceptStatus[resp.StatusCode]; !ok {
+		logging.FromContext(ctx, s.logger).Warn("unexpected fetch status", "url", urlStr, "status", resp.StatusCode)
+		return result, statusError(urlStr, resp.StatusCode)
+	}
+
+	if mediaType, ok := acceptContentType(resp.Header.Get("Content-Type"), s.acceptedContentTypes); !ok {
+		return result, contentTypeError(urlStr, resp.StatusCode, mediaType)
+	}
+
+	reader, err := decodeContent(resp.Body, resp.Header.Values("Content-Encoding"))
 	if err != nil {
-		return "", fmt.Errorf("execute request: %w", err)
+		return result, err
+	}
+
+	body, err := io.ReadAll(reader)
+	if err != nil {
+		return result, fmt.Errorf("read body: %w", err)
+	}
+
+	body, err = decodeCharset(body, resp.Header.Get("Content-Type"))
+	if err != nil {
+		return result, err
+	}
+
+	text, err := s.extractor.Extract(resp.Header.Get("Content-Type"), body)
+	if err != nil {
+		return result, fmt.Errorf("extract text: %w", err)
+	}
+
+	if s.cache != nil {
+		etag, lastModified := resp.Header.Get("ETag"), resp.Header.Get("Last-Modified")
+		now := time.Now()
+		store, expires := cachePolicy(resp.Header, now)
+		if store && (etag != "" || lastModified != "" || expires.After(now)) {
+			s.cache.Set(urlStr, CacheEntry{ETag: etag, LastModified: lastModified, Text: text, Expires: expires})
+		}
+	}
+
+	result.Text = text
+	return result, nil
+}
+
+// FetchRaw retrieves the body at url with any Content-Encoding removed but
+// otherwise untouched, subject to the same retries, limits and circuit
+// breaker as Fetch. Responses are never cached. file:// URLs and absolute
+// paths are read from disk when SourceConfig.AllowLocalFiles is set.
+func (s *Source) FetchRaw(ctx context.Context, urlStr string) ([]byte, error) {
+	path, local, err := s.localPath(urlStr)
+	if err != nil {
+		return nil, err
+	}
+	if local {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		body, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read file: %w", err)
+		}
+		return body, nil
+	}
+
+	if err := s.checkRobots(ctx, urlStr); err != nil {
+		return nil, err
+	}
+
+	resp, err := s.get(ctx, urlStr, nil)
+	if err != nil {
+		return nil, err
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("unexpected status: %d", resp.StatusCode)
+	if _, ok := s.acceptStatus[resp.StatusCode]; !ok {
+		logging.FromContext(ctx, s.logger).Warn("unexpected fetch status", "url", urlStr, "status", resp.StatusCode)
+		return nil, statusError(urlStr, resp.StatusCode)
 	}
 
-	body, err := io.ReadAll(resp.Body)
+	reader, err := decodeContent(resp.Body, resp.Header.Values("Content-Encoding"))
 	if err != nil {
-		return "", fmt.Errorf("read body: %w", err)
+		return nil, err
 	}
 
-	doc, err := html.Parse(bytes.NewReader(body))
+	body, err := io.ReadAll(reader)
 	if err != nil {
-		return "", fmt.Errorf("parse HTML: %w", err)
+		return nil, fmt.Errorf("read body: %w", err)
 	}
+	return body, nil
+}
 
-	var textBuilder strings.Builder
-	var crawler func(*html.Node)
-	crawler = func(n *html.Node) {
-		if n.Type == html.TextNode {
-			trimmed := strings.TrimSpace(n.Data)
-			if trimmed != "" {
-				textBuilder.WriteString(trimmed)
-				textBuilder.WriteByte('\n')
-			}
+// get issues a GET for urlStr with the extra header fields, once the domain's
+// circuit breaker, concurrency slots and rate limiter allow it. The slots stay
+// held until the caller closes the response body.
+func (s *Source) get(ctx context.Context, urlStr string, header http.Header) (*http.Response, error) {
+	domain, err := extractDomain(urlStr)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.breaker.allow(domain); err != nil {
+		return nil, fmt.Errorf("fetch %s: %w", domain, err)
+	}
+
+	if err := s.jitter(ctx); err != nil {
+		return nil, err
+	}
+
+	release, err := s.acquire(ctx, domain)
+	if err != nil {
+		return nil, err
+	}
+
+	fetchCtx, abort := withAbort(ctx)
+	done := func() {
+		abort(nil)
+		release()
+	}
+
+	req, err := retryablehttp.NewRequestWithContext(fetchCtx, http.MethodGet, urlStr, nil)
+	if err != nil {
+		done()
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	if ua := s.userAgent(); ua != "" {
+		req.Header.Set("User-Agent", ua)
+	}
+	if s.acceptEncoding != "" {
+		req.Header.Set("Accept-Encoding", s.acceptEncoding)
+	}
+	for key, values := range s.headers {
+		req.Header[key] = values
+	}
+	if id := logging.RequestID(ctx); id != "" {
+		req.Header.Set(logging.RequestIDHeader, id)
+	}
+	if s.basicAuth != nil {
+		req.SetBasicAuth(s.basicAuth.User, s.basicAuth.Pass)
+	}
+	token := s.credentials.current()
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+
+	start := time.Now()
+	resp, err := s.client.Do(req)
+	if err == nil && resp.StatusCode == http.StatusUnauthorized && s.credentials != nil {
+		resp, err = s.reauthorize(fetchCtx, req, resp, token)
+	}
+	if err != nil {
+		// A cancelled caller, or one whose deadline the next retry could not
+		// have met, says nothing about the domain's health.
+		cancelled := fetchCtx.Err() != nil
+		if cancelled && ctx.Err() == nil {
+			err = context.Cause(fetchCtx)
 		}
-		for c := n.FirstChild; c != nil; c = c.NextSibling {
-			crawler(c)
+		done()
+		s.metrics.ObserveFetch(domain, 0, time.Since(start))
+		if !cancelled {
+			s.breaker.record(domain, true)
+		}
+		return nil, &FetchError{URL: urlStr, Op: OpRequest, Err: err}
+	}
+	s.metrics.ObserveFetch(domain, resp.StatusCode, time.Since(start))
+	s.breaker.record(domain, resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError)
+
+	resp.Body = &releaseOnClose{ReadCloser: resp.Body, release: done}
+	return resp, nil
+}
+
+// reauthorize retries req, rejected with the 401 resp while sending token,
+// once with a token refreshed from the CredentialProvider. When the refresh
+// fails, resp is returned as it is.
+func (s *Source) reauthorize(ctx context.Context, req *retryablehttp.Request, resp *http.Response, token string) (*http.Response, error) {
+	logger := logging.FromContext(ctx, s.logger)
+	fresh, err := s.credentials.refresh(ctx, token)
+	if err != nil {
+		logger.Warn("credential refresh failed", "url", req.URL.String(), "error", err)
+		return resp, nil
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	logger.Info("retrying fetch with refreshed credentials", "url", req.URL.String())
+	req.Header.Set("Authorization", "Bearer "+fresh)
+	return s.client.Do(req)
+}
+
+// jitter sleeps for a random duration up to JitterMax, returning early with
+// the context's error if it is cancelled first.
+func (s *Source) jitter(ctx context.Context) error {
+	if s.jitterMax <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(rand.N(s.jitterMax))
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// acquire takes the global and per-domain concurrency slots for domain and
+// then waits for its rate limiter. The returned func gives the slots back.
+func (s *Source) acquire(ctx context.Context, domain string) (func(), error) {
+	var releases []func()
+	release := func() {
+		for i := len(releases) - 1; i >= 0; i-- {
+			releases[i]()
+		}
+	}
+
+	// Always take the global slot before the domain slot so that two fetches
+	// can never hold one each while waiting on the other.
+	if s.globalSemaphore != nil {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-s.globalSemaphore:
+			releases = append(releases, func() { s.globalSemaphore <- struct{}{} })
+		}
+	}
+
+	// Acquire a slot for this domain (allows N concurrent requests, or the
+	// adaptive limit when enabled)
+	if s.adaptive != nil {
+		releaseDomain, err := s.adaptive.acquire(ctx, domain)
+		if err != nil {
+			release()
+			return nil, err
+		}
+		releases = append(releases, releaseDomain)
+	} else {
+		sem := s.getDomainSemaphore(domain)
+		select {
+		case <-ctx.Done():
+			release()
+			return nil, ctx.Err()
+		case <-sem:
+			releases = append(releases, func() { sem <- struct{}{} })
+		}
+	}
+
+	// Wait for the domain's rate limiter while holding the slot
+	if limiter := s.getDomainLimiter(domain); limiter != nil {
+		if err := limiter.Wait(ctx); err != nil {
+			release()
+			return nil, fmt.Errorf("rate limit wait: %w", err)
 		}
 	}
-	crawler(doc)
 
-	return textBuilder.String(), nil
+	return release, nil
 }
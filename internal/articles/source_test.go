@@ -0,0 +1,945 @@
+package articles
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/shoresh319/firefly/internal/logging"
+)
+
+const testHTML = `<html><head><title>Firefly</title></head><body><p>Quick brown fox</p><p>jumps over the lazy dog</p></body></html>`
+
+// newTestSource returns a Source whose transport leaves compressed bodies
+// untouched so Fetch sees the raw Content-Encoding.
+func newTestSource() *Source {
+	return NewSource(SourceConfig{
+		HTTPClient: &http.Client{Transport: &http.Transport{DisableCompression: true}},
+		Logger:     logging.Nop(),
+	})
+}
+
+func gzipBytes(t *testing.T, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(data); err != nil {
+		t.Fatalf("gzip write: %v", err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("gzip close: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func zlibBytes(t *testing.T, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zlib.NewWriter(&buf)
+	if _, err := zw.Write(data); err != nil {
+		t.Fatalf("zlib write: %v", err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("zlib close: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func serveBody(encoding string, body []byte) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		if encoding != "" {
+			w.Header().Set("Content-Encoding", encoding)
+		}
+		_, _ = w.Write(body)
+	}))
+}
+
+func TestFetchDecodesCompressedContent(t *testing.T) {
+	plain := serveBody("", []byte(testHTML))
+	defer plain.Close()
+
+	want, err := newTestSource().Fetch(context.Background(), plain.URL)
+	if err != nil {
+		t.Fatalf("fetch plain: %v", err)
+	}
+	if !strings.Contains(want, "lazy dog") {
+		t.Fatalf("expected plain text to contain article words, got %q", want)
+	}
+
+	tests := []struct {
+		name     string
+		encoding string
+		body     []byte
+	}{
+		{name: "gzip", encoding: "gzip", body: gzipBytes(t, []byte(testHTML))},
+		{name: "deflate", encoding: "deflate", body: zlibBytes(t, []byte(testHTML))},
+		{name: "multiple", encoding: "deflate, gzip", body: gzipBytes(t, zlibBytes(t, []byte(testHTML)))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := serveBody(tt.encoding, tt.body)
+			defer srv.Close()
+
+			got, err := newTestSource().Fetch(context.Background(), srv.URL)
+			if err != nil {
+				t.Fatalf("fetch: %v", err)
+			}
+			if got != want {
+				t.Fatalf("expected %q, got %q", want, got)
+			}
+		})
+	}
+}
+
+func TestFetchEmptyCompressedBody(t *testing.T) {
+	srv := serveBody("gzip", nil)
+	defer srv.Close()
+
+	got, err := newTestSource().Fetch(context.Background(), srv.URL)
+	if err != nil {
+		t.Fatalf("fetch: %v", err)
+	}
+	if got != "" {
+		t.Fatalf("expected empty text, got %q", got)
+	}
+}
+
+func TestFetchCorruptCompressedBody(t *testing.T) {
+	srv := serveBody("gzip", []byte(testHTML))
+	defer srv.Close()
+
+	_, err := newTestSource().Fetch(context.Background(), srv.URL)
+	if err == nil || !strings.Contains(err.Error(), "gzip") {
+		t.Fatalf("expected gzip decode error, got %v", err)
+	}
+}
+
+// serveNegotiated gzips the body only when the request accepts gzip, and
+// records the Accept-Encoding it received.
+func serveNegotiated(t *testing.T, received *atomic.Value) *httptest.Server {
+	compressed := gzipBytes(t, []byte(testHTML))
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received.Store(r.Header.Get("Accept-Encoding"))
+		w.Header().Set("Content-Type", "text/html")
+		if strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
+			w.Header().Set("Content-Encoding", "gzip")
+			_, _ = w.Write(compressed)
+			return
+		}
+		_, _ = w.Write([]byte(testHTML))
+	}))
+}
+
+func TestFetchRequestsCompressionExplicitly(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  SourceConfig
+		want string
+	}{
+		{name: "default", want: DefaultAcceptEncoding},
+		{name: "identity", cfg: SourceConfig{AcceptEncoding: "identity"}, want: "identity"},
+		{name: "transparent", cfg: SourceConfig{TransparentDecompression: true}, want: "gzip"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var received atomic.Value
+			srv := serveNegotiated(t, &received)
+			defer srv.Close()
+
+			cfg := tt.cfg
+			cfg.Logger = logging.Nop()
+			got, err := NewSource(cfg).Fetch(context.Background(), srv.URL)
+			if err != nil {
+				t.Fatalf("fetch: %v", err)
+			}
+			if !strings.Contains(got, "lazy dog") {
+				t.Fatalf("expected article text, got %q", got)
+			}
+			if header, _ := received.Load().(string); header != tt.want {
+				t.Fatalf("expected Accept-Encoding %q, got %q", tt.want, header)
+			}
+		})
+	}
+}
+
+func TestFetchHeadersOverrideAcceptEncoding(t *testing.T) {
+	var received atomic.Value
+	srv := serveNegotiated(t, &received)
+	defer srv.Close()
+
+	source := NewSource(SourceConfig{
+		Logger:  logging.Nop(),
+		Headers: http.Header{"Accept-Encoding": []string{"identity"}},
+	})
+	if _, err := source.Fetch(context.Background(), srv.URL); err != nil {
+		t.Fatalf("fetch: %v", err)
+	}
+	if header, _ := received.Load().(string); header != "identity" {
+		t.Fatalf("expected Accept-Encoding %q, got %q", "identity", header)
+	}
+}
+
+func TestFetchPerRequestTimeoutRetries(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			select {
+			case <-time.After(500 * time.Millisecond):
+			case <-r.Context().Done():
+				return
+			}
+		}
+		_, _ = w.Write([]byte(testHTML))
+	}))
+	defer srv.Close()
+
+	source := NewSource(SourceConfig{
+		RetryMax:          2,
+		RetryWaitMin:      time.Millisecond,
+		RetryWaitMax:      10 * time.Millisecond,
+		PerRequestTimeout: 50 * time.Millisecond,
+		Logger:            logging.Nop(),
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	got, err := source.Fetch(ctx, srv.URL)
+	if err != nil {
+		t.Fatalf("expected retry to succeed, got %v", err)
+	}
+	if !strings.Contains(got, "lazy dog") {
+		t.Fatalf("unexpected text %q", got)
+	}
+	if n := atomic.LoadInt32(&calls); n != 2 {
+		t.Fatalf("expected 2 attempts, got %d", n)
+	}
+}
+
+func TestFetchPropagatesRequestID(t *testing.T) {
+	var calls int32
+	var received atomic.Value
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received.Store(r.Header.Get("X-Request-Id"))
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	logger := &recordingLogger{}
+	source := NewSource(SourceConfig{
+		RetryMax:     1,
+		RetryWaitMin: time.Millisecond,
+		RetryWaitMax: time.Millisecond,
+		Logger:       logger,
+	})
+
+	ctx := logging.WithRequestID(context.Background(), "req-7")
+	if _, err := source.Fetch(ctx, srv.URL); err == nil {
+		t.Fatal("expected error for 404 response")
+	}
+	if id, _ := received.Load().(string); id != "req-7" {
+		t.Fatalf("expected X-Request-Id %q, got %q", "req-7", id)
+	}
+	for _, msg := range []string{"retrying fetch", "unexpected fetch status"} {
+		record, ok := logger.find(msg)
+		if !ok {
+			t.Fatalf("expected %q to be logged", msg)
+		}
+		if record.value("request_id") != "req-7" {
+			t.Fatalf("expected %q to carry request_id, got %v", msg, record.kv)
+		}
+	}
+}
+
+type logRecord struct {
+	level string
+	msg   string
+	kv    []any
+}
+
+// recordingLogger captures log records for assertions.
+type recordingLogger struct {
+	mu      sync.Mutex
+	records []logRecord
+}
+
+func (l *recordingLogger) add(level, msg string, kv []any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.records = append(l.records, logRecord{level: level, msg: msg, kv: kv})
+}
+
+func (l *recordingLogger) Debug(msg string, kv ...any) { l.add("debug", msg, kv) }
+func (l *recordingLogger) Info(msg string, kv ...any)  { l.add("info", msg, kv) }
+func (l *recordingLogger) Warn(msg string, kv ...any)  { l.add("warn", msg, kv) }
+func (l *recordingLogger) Error(msg string, kv ...any) { l.add("error", msg, kv) }
+
+func (l *recordingLogger) find(msg string) (logRecord, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, r := range l.records {
+		if r.msg == msg {
+			return r, true
+		}
+	}
+	return logRecord{}, false
+}
+
+func (r logRecord) value(key string) any {
+	for i := 0; i+1 < len(r.kv); i += 2 {
+		if r.kv[i] == key {
+			return r.kv[i+1]
+		}
+	}
+	return nil
+}
+
+func TestFetchLogsStructuredFields(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	logger := &recordingLogger{}
+	source := NewSource(SourceConfig{
+		RetryMax:     1,
+		RetryWaitMin: time.Millisecond,
+		RetryWaitMax: time.Millisecond,
+		Logger:       logger,
+	})
+
+	if _, err := source.Fetch(context.Background(), srv.URL); err == nil {
+		t.Fatal("expected error for 404 response")
+	}
+
+	retry, ok := logger.find("retrying fetch")
+	if !ok {
+		t.Fatal("expected retry to be logged")
+	}
+	if retry.value("url") != srv.URL || retry.value("attempt") != 1 {
+		t.Fatalf("unexpected retry fields: %v", retry.kv)
+	}
+
+	status, ok := logger.find("unexpected fetch status")
+	if !ok {
+		t.Fatal("expected unexpected status to be logged")
+	}
+	if status.value("status") != http.StatusNotFound {
+		t.Fatalf("expected status=404, got %v", status.value("status"))
+	}
+}
+
+func TestBackoffRetryAfter(t *testing.T) {
+	const (
+		min = time.Second
+		max = time.Minute
+	)
+	backoff := retryBackoff(defaultRetryableStatus, nil)
+	resp429 := func(retryAfter string) *http.Response {
+		resp := &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{}}
+		if retryAfter != "" {
+			resp.Header.Set("Retry-After", retryAfter)
+		}
+		return resp
+	}
+
+	t.Run("seconds", func(t *testing.T) {
+		if got := backoff(min, max, 0, resp429("7")); got != 7*time.Second {
+			t.Fatalf("expected 7s, got %v", got)
+		}
+	})
+
+	t.Run("http date", func(t *testing.T) {
+		when := time.Now().Add(20 * time.Second).UTC().Format(http.TimeFormat)
+		got := backoff(min, max, 0, resp429(when))
+		// HTTP dates have second precision, so allow for truncation.
+		if got < 18*time.Second || got > 20*time.Second {
+			t.Fatalf("expected roughly 20s, got %v", got)
+		}
+	})
+
+	t.Run("http date capped", func(t *testing.T) {
+		when := time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)
+		if got := backoff(min, max, 0, resp429(when)); got != max {
+			t.Fatalf("expected cap %v, got %v", max, got)
+		}
+	})
+
+	t.Run("http date in past", func(t *testing.T) {
+		when := time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat)
+		if got := backoff(min, max, 0, resp429(when)); got != min {
+			t.Fatalf("expected floor %v, got %v", min, got)
+		}
+	})
+
+	t.Run("unparseable falls back to jittered exponential", func(t *testing.T) {
+		for i := 0; i < 20; i++ {
+			if got := backoff(min, max, 3, resp429("soon")); got < 4*time.Second || got > 8*time.Second {
+				t.Fatalf("expected between 4s and 8s, got %v", got)
+			}
+		}
+	})
+
+	t.Run("service unavailable", func(t *testing.T) {
+		resp := &http.Response{StatusCode: http.StatusServiceUnavailable, Header: http.Header{"Retry-After": {"9"}}}
+		if got := backoff(min, max, 0, resp); got != 9*time.Second {
+			t.Fatalf("expected 9s, got %v", got)
+		}
+	})
+
+	t.Run("large attempt numbers stay within the cap", func(t *testing.T) {
+		resp := &http.Response{StatusCode: http.StatusServiceUnavailable, Header: http.Header{}}
+		for _, attempt := range []int{34, 40, 63} {
+			if got := backoff(min, max, attempt, resp); got < max/2 || got > max {
+				t.Fatalf("attempt %d: expected between %v and %v, got %v", attempt, max/2, max, got)
+			}
+		}
+	})
+}
+
+func TestFetchRetriesServiceUnavailable(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) <= 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(testHTML))
+	}))
+	defer srv.Close()
+
+	source := NewSource(SourceConfig{
+		RetryMax:     3,
+		RetryWaitMin: time.Millisecond,
+		RetryWaitMax: time.Millisecond,
+		Logger:       logging.Nop(),
+	})
+
+	got, err := source.Fetch(context.Background(), srv.URL)
+	if err != nil {
+		t.Fatalf("expected retries to succeed, got %v", err)
+	}
+	if !strings.Contains(got, "lazy dog") {
+		t.Fatalf("unexpected text %q", got)
+	}
+	if n := atomic.LoadInt32(&calls); n != 3 {
+		t.Fatalf("expected 3 attempts, got %d", n)
+	}
+}
+
+func TestFetchRetriesEvery5xxByDefault(t *testing.T) {
+	for _, status := range []int{http.StatusInsufficientStorage, 520, http.StatusNotImplemented} {
+		var calls int32
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&calls, 1)
+			w.WriteHeader(status)
+		}))
+
+		source := NewSource(SourceConfig{
+			RetryMax:     2,
+			RetryWaitMin: time.Millisecond,
+			RetryWaitMax: time.Millisecond,
+			Logger:       logging.Nop(),
+		})
+		if _, err := source.Fetch(context.Background(), srv.URL); err == nil {
+			t.Fatalf("expected error for %d response", status)
+		}
+		srv.Close()
+
+		want := int32(3)
+		if status == http.StatusNotImplemented {
+			want = 1
+		}
+		if n := atomic.LoadInt32(&calls); n != want {
+			t.Fatalf("expected %d attempts for %d, got %d", want, status, n)
+		}
+	}
+}
+
+func TestFetchRetryableStatusCodesConfigurable(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	source := NewSource(SourceConfig{
+		RetryMax:             3,
+		RetryWaitMin:         time.Millisecond,
+		RetryWaitMax:         time.Millisecond,
+		RetryableStatusCodes: []int{http.StatusTooManyRequests},
+		Logger:               logging.Nop(),
+	})
+
+	if _, err := source.Fetch(context.Background(), srv.URL); err == nil {
+		t.Fatal("expected error for 503 response")
+	}
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Fatalf("expected 503 not to be retried, got %d attempts", n)
+	}
+}
+
+func TestFetchHonorsMaxTotalConcurrency(t *testing.T) {
+	var inFlight, peak int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			old := atomic.LoadInt32(&peak)
+			if current <= old || atomic.CompareAndSwapInt32(&peak, old, current) {
+				break
+			}
+		}
+		time.Sleep(30 * time.Millisecond)
+		_, _ = w.Write([]byte(testHTML))
+	}))
+	defer srv.Close()
+
+	source := NewSource(SourceConfig{
+		ConcurrencyPerDomain: 10,
+		MaxTotalConcurrency:  2,
+		Logger:               logging.Nop(),
+	})
+
+	// Alternate hostnames so the per-domain semaphores are not the bottleneck.
+	localhostURL := strings.Replace(srv.URL, "127.0.0.1", "localhost", 1)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		target := srv.URL
+		if i%2 == 1 {
+			target = localhostURL
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := source.Fetch(context.Background(), target); err != nil {
+				t.Errorf("fetch %s: %v", target, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := atomic.LoadInt32(&peak); got > 2 {
+		t.Fatalf("expected at most 2 concurrent requests, observed %d", got)
+	}
+}
+
+func TestFetchDecodesDeclaredCharset(t *testing.T) {
+	// "café" encoded as ISO-8859-1: é is the single byte 0xE9.
+	latin1 := []byte("<html><body><p>un caf\xe9 noir</p></body></html>")
+	latin1Meta := []byte("<html><head><meta charset=\"iso-8859-1\"></head><body><p>un caf\xe9 noir</p></body></html>")
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        []byte
+	}{
+		{name: "content-type header", contentType: "text/html; charset=ISO-8859-1", body: latin1},
+		{name: "meta tag", contentType: "text/html", body: latin1Meta},
+		{name: "utf-8 default", contentType: "text/html", body: []byte("<p>un café noir</p>")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", tt.contentType)
+				_, _ = w.Write(tt.body)
+			}))
+			defer srv.Close()
+
+			got, err := newTestSource().Fetch(context.Background(), srv.URL)
+			if err != nil {
+				t.Fatalf("fetch: %v", err)
+			}
+			if !strings.Contains(got, "café") {
+				t.Fatalf("expected UTF-8 \"café\", got %q", got)
+			}
+		})
+	}
+}
+
+func TestFetchCircuitBreakerFailsFast(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	source := NewSource(SourceConfig{
+		CircuitBreakerThreshold: 2,
+		CircuitBreakerCooldown:  time.Minute,
+		Logger:                  logging.Nop(),
+	})
+
+	for i := 0; i < 2; i++ {
+		_, err := source.Fetch(context.Background(), srv.URL)
+		if err == nil || errors.Is(err, ErrCircuitOpen) {
+			t.Fatalf("attempt %d: expected upstream failure, got %v", i, err)
+		}
+	}
+
+	for i := 0; i < 3; i++ {
+		_, err := source.Fetch(context.Background(), srv.URL)
+		if !errors.Is(err, ErrCircuitOpen) {
+			t.Fatalf("expected ErrCircuitOpen, got %v", err)
+		}
+	}
+
+	if n := atomic.LoadInt32(&calls); n != 2 {
+		t.Fatalf("expected open circuit to stop requests after 2 calls, got %d", n)
+	}
+}
+
+func TestCircuitBreakerRecovers(t *testing.T) {
+	now := time.Now()
+	b := newCircuitBreaker(1, time.Minute)
+	b.now = func() time.Time { return now }
+
+	b.record("example.com", true)
+	if err := b.allow("example.com"); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected open circuit, got %v", err)
+	}
+	if err := b.allow("other.com"); err != nil {
+		t.Fatalf("expected other domains unaffected, got %v", err)
+	}
+
+	now = now.Add(time.Minute)
+	if err := b.allow("example.com"); err != nil {
+		t.Fatalf("expected circuit to allow a trial after cooldown, got %v", err)
+	}
+	b.record("example.com", false)
+	b.record("example.com", false)
+	if err := b.allow("example.com"); err != nil {
+		t.Fatalf("expected closed circuit after success, got %v", err)
+	}
+}
+
+func TestFetchSendsUserAgent(t *testing.T) {
+	var mu sync.Mutex
+	var received []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		received = append(received, r.UserAgent())
+		mu.Unlock()
+		_, _ = w.Write([]byte(testHTML))
+	}))
+	defer srv.Close()
+
+	fetchN := func(source *Source, n int) []string {
+		mu.Lock()
+		received = nil
+		mu.Unlock()
+		for i := 0; i < n; i++ {
+			if _, err := source.Fetch(context.Background(), srv.URL); err != nil {
+				t.Fatalf("fetch: %v", err)
+			}
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), received...)
+	}
+
+	fixed := NewSource(SourceConfig{UserAgent: "firefly/1.0", Logger: logging.Nop()})
+	for _, ua := range fetchN(fixed, 2) {
+		if ua != "firefly/1.0" {
+			t.Fatalf("expected configured user agent, got %q", ua)
+		}
+	}
+
+	rotating := NewSource(SourceConfig{
+		UserAgent:  "ignored",
+		UserAgents: []string{"agent-a", "agent-b", "agent-c"},
+		Logger:     logging.Nop(),
+	})
+	got := fetchN(rotating, 4)
+	want := []string{"agent-a", "agent-b", "agent-c", "agent-a"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected rotation %v, got %v", want, got)
+		}
+	}
+}
+
+func TestFetchRateLimitsPerDomain(t *testing.T) {
+	srv := serveBody("", []byte(testHTML))
+	defer srv.Close()
+
+	source := NewSource(SourceConfig{RequestsPerSecondPerDomain: 2, Logger: logging.Nop()})
+
+	start := time.Now()
+	var elapsed []time.Duration
+	for i := 0; i < 3; i++ {
+		if _, err := source.Fetch(context.Background(), srv.URL); err != nil {
+			t.Fatalf("fetch %d: %v", i, err)
+		}
+		elapsed = append(elapsed, time.Since(start))
+	}
+
+	if elapsed[1] > 200*time.Millisecond {
+		t.Fatalf("expected first two requests to burst, second finished after %v", elapsed[1])
+	}
+	if elapsed[2] < 400*time.Millisecond {
+		t.Fatalf("expected third request to be delayed, finished after %v", elapsed[2])
+	}
+}
+
+func TestFetchRateLimitRespectsContext(t *testing.T) {
+	srv := serveBody("", []byte(testHTML))
+	defer srv.Close()
+
+	source := NewSource(SourceConfig{RequestsPerSecondPerDomain: 0.1, Logger: logging.Nop()})
+	if _, err := source.Fetch(context.Background(), srv.URL); err != nil {
+		t.Fatalf("first fetch: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := source.Fetch(ctx, srv.URL); err == nil {
+		t.Fatal("expected rate-limited fetch to fail once the context expires")
+	}
+}
+
+func TestFetchJitterSpreadsRequests(t *testing.T) {
+	var mu sync.Mutex
+	var starts []time.Time
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		starts = append(starts, time.Now())
+		mu.Unlock()
+		_, _ = w.Write([]byte(testHTML))
+	}))
+	defer srv.Close()
+
+	const fetches = 10
+	source := NewSource(SourceConfig{ConcurrencyPerDomain: fetches, JitterMax: 300 * time.Millisecond, Logger: logging.Nop()})
+
+	var wg sync.WaitGroup
+	for i := 0; i < fetches; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := source.Fetch(context.Background(), srv.URL); err != nil {
+				t.Errorf("fetch: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	first, last := starts[0], starts[0]
+	for _, s := range starts {
+		if s.Before(first) {
+			first = s
+		}
+		if s.After(last) {
+			last = s
+		}
+	}
+	if spread := last.Sub(first); spread < 50*time.Millisecond {
+		t.Fatalf("expected request starts to be spread out, got spread of %v", spread)
+	}
+}
+
+func TestFetchJitterRespectsContext(t *testing.T) {
+	srv := serveBody("", []byte(testHTML))
+	defer srv.Close()
+
+	source := NewSource(SourceConfig{JitterMax: time.Hour, Logger: logging.Nop()})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := source.Fetch(ctx, srv.URL); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("expected jitter to stop at the deadline, took %v", elapsed)
+	}
+}
+
+func TestFetchSendsConfiguredAuthorization(t *testing.T) {
+	const token = "Bearer s3cret"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != token {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.Header.Get("X-Archive") != "firefly" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_, _ = w.Write([]byte(testHTML))
+	}))
+	defer srv.Close()
+
+	_, err := newTestSource().Fetch(context.Background(), srv.URL)
+	var fetchErr *FetchError
+	if !errors.As(err, &fetchErr) || fetchErr.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected 401 without credentials, got %v", err)
+	}
+
+	source := NewSource(SourceConfig{
+		Headers: http.Header{"Authorization": {token}, "X-Archive": {"firefly"}},
+		Logger:  logging.Nop(),
+	})
+	if _, err := source.Fetch(context.Background(), srv.URL); err != nil {
+		t.Fatalf("expected configured headers to authorize the fetch, got %v", err)
+	}
+}
+
+func TestFetchSendsBasicAuth(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		if !ok || user != "reader" || pass != "hunter2" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(testHTML))
+	}))
+	defer srv.Close()
+
+	wrong := NewSource(SourceConfig{BasicAuth: &BasicAuth{User: "reader", Pass: "wrong"}, Logger: logging.Nop()})
+	_, err := wrong.Fetch(context.Background(), srv.URL)
+	if err == nil {
+		t.Fatal("expected wrong credentials to be rejected")
+	}
+	if strings.Contains(err.Error(), "wrong") {
+		t.Fatalf("expected error not to mention the password, got %v", err)
+	}
+
+	source := NewSource(SourceConfig{
+		Headers:   http.Header{"Authorization": {"Bearer overridden"}},
+		BasicAuth: &BasicAuth{User: "reader", Pass: "hunter2"},
+		Logger:    logging.Nop(),
+	})
+	if _, err := source.Fetch(context.Background(), srv.URL); err != nil {
+		t.Fatalf("expected basic auth to authorize the fetch, got %v", err)
+	}
+}
+
+func TestFetchRefreshesCredentialsOn401(t *testing.T) {
+	var valid atomic.Value
+	valid.Store("token-1")
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.Header.Get("Authorization") != "Bearer "+valid.Load().(string) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(testHTML))
+	}))
+	defer srv.Close()
+
+	var calls atomic.Int32
+	source := NewSource(SourceConfig{
+		CredentialProvider: func(context.Context) (string, error) {
+			return fmt.Sprintf("token-%d", calls.Add(1)), nil
+		},
+		Logger: logging.Nop(),
+	})
+
+	if _, err := source.Fetch(context.Background(), srv.URL); err != nil {
+		t.Fatalf("expected the retry with a fresh token to succeed, got %v", err)
+	}
+	if calls.Load() != 1 || requests.Load() != 2 {
+		t.Fatalf("expected 1 refresh and 2 requests, got %d and %d", calls.Load(), requests.Load())
+	}
+
+	if _, err := source.Fetch(context.Background(), srv.URL); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls.Load() != 1 || requests.Load() != 3 {
+		t.Fatalf("expected the cached token to be reused, got %d refreshes and %d requests", calls.Load(), requests.Load())
+	}
+
+	// The token expires: the next 401 triggers exactly one more refresh.
+	valid.Store("token-2")
+	if _, err := source.Fetch(context.Background(), srv.URL); err != nil {
+		t.Fatalf("expected the expired token to be refreshed, got %v", err)
+	}
+	if calls.Load() != 2 || requests.Load() != 5 {
+		t.Fatalf("expected 2 refreshes and 5 requests, got %d and %d", calls.Load(), requests.Load())
+	}
+}
+
+func TestFetchRetriesOnlyOnceOn401(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+
+	source := NewSource(SourceConfig{
+		CredentialProvider: func(context.Context) (string, error) { return "rejected", nil },
+		Logger:             logging.Nop(),
+	})
+	_, err := source.Fetch(context.Background(), srv.URL)
+	var fetchErr *FetchError
+	if !errors.As(err, &fetchErr) || fetchErr.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected a 401 FetchError, got %v", err)
+	}
+	if requests.Load() != 2 {
+		t.Fatalf("expected one retry, got %d requests", requests.Load())
+	}
+
+	failing := NewSource(SourceConfig{
+		CredentialProvider: func(context.Context) (string, error) { return "", errors.New("token service down") },
+		Logger:             logging.Nop(),
+	})
+	if _, err := failing.Fetch(context.Background(), srv.URL); !errors.As(err, &fetchErr) || fetchErr.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected the 401 to stand when the refresh fails, got %v", err)
+	}
+}
+
+func TestAcceptStatusCodesExtractsListedStatuses(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		code, _ := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/"))
+		w.Header().Set("Content-Type", "text/html")
+		w.WriteHeader(code)
+		_, _ = w.Write([]byte(testHTML))
+	}))
+	defer srv.Close()
+
+	source := NewSource(SourceConfig{AcceptStatusCodes: []int{200, 203, 206}, Logger: logging.Nop()})
+	for _, code := range []int{203, 206} {
+		text, err := source.Fetch(context.Background(), fmt.Sprintf("%s/%d", srv.URL, code))
+		if err != nil {
+			t.Fatalf("unexpected error for %d: %v", code, err)
+		}
+		if !strings.Contains(text, "Quick brown fox") {
+			t.Fatalf("expected the %d body to be extracted, got %q", code, text)
+		}
+	}
+
+	_, err := NewSource(SourceConfig{Logger: logging.Nop()}).Fetch(context.Background(), srv.URL+"/206")
+	if !errors.Is(err, ErrUnexpectedStatus) {
+		t.Fatalf("expected ErrUnexpectedStatus for 206 by default, got %v", err)
+	}
+}
@@ -0,0 +1,16 @@
+package articles
+
+import "context"
+
+// StaticFetcher returns its own text for every URL without touching the
+// network or disk, so benchmarks and tests of the counting pipeline measure
+// tokenizing and merging rather than fetch latency.
+type StaticFetcher string
+
+// Fetch returns the fixed text, or the context's error once it is cancelled.
+func (f StaticFetcher) Fetch(ctx context.Context, _ string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	return string(f), nil
+}
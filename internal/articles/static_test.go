@@ -0,0 +1,23 @@
+package articles
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestStaticFetcherReturnsTextForAnyURL(t *testing.T) {
+	f := StaticFetcher("the quick brown fox")
+	for _, url := range []string{"https://a.example/1", "article-2", ""} {
+		text, err := f.Fetch(context.Background(), url)
+		if err != nil || text != "the quick brown fox" {
+			t.Fatalf("expected fixed text for %q, got %q (err %v)", url, text, err)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := f.Fetch(ctx, "article"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
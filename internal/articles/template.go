@@ -0,0 +1,96 @@
+package articles
+
+import (
+	"fmt"
+	"iter"
+	"regexp"
+	"strconv"
+)
+
+// MaxURLTemplateExpansion is the most URLs a single article list line may
+// expand to; lines over it are logged and skipped.
+const MaxURLTemplateExpansion = 100_000
+
+// urlRangePattern matches a {start..end} range in a URL template.
+var urlRangePattern = regexp.MustCompile(`\{(\d{1,9})\.\.(\d{1,9})\}`)
+
+// urlRange is one {start..end} range of a template.
+type urlRange struct {
+	start, end, width int
+}
+
+// ExpandURLTemplate yields the URLs described by template, in which every
+// {start..end} range stands for each integer from start to end inclusive,
+// counting down when end is below start, as in
+// "https://example.com/articles?page={1..50}". A bound written with leading
+// zeros pads the numbers to its width, so {01..12} yields 01 to 12. Several
+// ranges expand to every combination, the last varying fastest. A template
+// without ranges yields itself unchanged. URLs are generated as they are
+// consumed, so large ranges cost no memory.
+func ExpandURLTemplate(template string) iter.Seq[string] {
+	return func(yield func(string) bool) {
+		matches := urlRangePattern.FindAllStringSubmatchIndex(template, -1)
+		if len(matches) == 0 {
+			yield(template)
+			return
+		}
+
+		literals := make([]string, 0, len(matches)+1)
+		ranges := make([]urlRange, 0, len(matches))
+		prev := 0
+		for _, m := range matches {
+			literals = append(literals, template[prev:m[0]])
+			ranges = append(ranges, parseURLRange(template[m[2]:m[3]], template[m[4]:m[5]]))
+			prev = m[1]
+		}
+		literals = append(literals, template[prev:])
+
+		expandRanges(literals, ranges, literals[0], yield)
+	}
+}
+
+// URLTemplateSize reports how many URLs ExpandURLTemplate yields for
+// template. Sizes above MaxURLTemplateExpansion are reported as
+// MaxURLTemplateExpansion+1, so huge products cannot overflow.
+func URLTemplateSize(template string) int {
+	size := 1
+	for _, m := range urlRangePattern.FindAllStringSubmatch(template, -1) {
+		r := parseURLRange(m[1], m[2])
+		size *= max(r.start, r.end) - min(r.start, r.end) + 1
+		if size > MaxURLTemplateExpansion {
+			return MaxURLTemplateExpansion + 1
+		}
+	}
+	return size
+}
+
+func parseURLRange(startStr, endStr string) urlRange {
+	start, _ := strconv.Atoi(startStr)
+	end, _ := strconv.Atoi(endStr)
+	width := 0
+	if (len(startStr) > 1 && startStr[0] == '0') || (len(endStr) > 1 && endStr[0] == '0') {
+		width = max(len(startStr), len(endStr))
+	}
+	return urlRange{start: start, end: end, width: width}
+}
+
+// expandRanges yields prefix completed by every combination of ranges, each
+// followed by the literal after it. It reports whether to keep going.
+func expandRanges(literals []string, ranges []urlRange, prefix string, yield func(string) bool) bool {
+	if len(ranges) == 0 {
+		return yield(prefix)
+	}
+	r := ranges[0]
+	step := 1
+	if r.end < r.start {
+		step = -1
+	}
+	for n := r.start; ; n += step {
+		if !expandRanges(literals[1:], ranges[1:], prefix+fmt.Sprintf("%0*d", r.width, n)+literals[1], yield) {
+			return false
+		}
+		if n == r.end {
+			return true
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package articles
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestExpandURLTemplate(t *testing.T) {
+	cases := []struct {
+		template string
+		want     []string
+	}{
+		{"https://example.com/articles?page={1..3}", []string{
+			"https://example.com/articles?page=1",
+			"https://example.com/articles?page=2",
+			"https://example.com/articles?page=3",
+		}},
+		{"https://example.com/{3..1}", []string{"https://example.com/3", "https://example.com/2", "https://example.com/1"}},
+		{"https://example.com/{08..10}.html", []string{"https://example.com/08.html", "https://example.com/09.html", "https://example.com/10.html"}},
+		{"https://example.com/{1..2}/{a..b}/{1..2}", []string{
+			"https://example.com/1/{a..b}/1",
+			"https://example.com/1/{a..b}/2",
+			"https://example.com/2/{a..b}/1",
+			"https://example.com/2/{a..b}/2",
+		}},
+		{"https://example.com/plain", []string{"https://example.com/plain"}},
+	}
+	for _, tc := range cases {
+		if got := slices.Collect(ExpandURLTemplate(tc.template)); !slices.Equal(got, tc.want) {
+			t.Fatalf("expected %v for %q, got %v", tc.want, tc.template, got)
+		}
+	}
+}
+
+func TestURLTemplateSize(t *testing.T) {
+	cases := map[string]int{
+		"https://example.com/plain":                                        1,
+		"https://example.com/{1..50}":                                      50,
+		"https://example.com/{50..1}/{01..12}":                             600,
+		"https://example.com/{1..1000}/{1..100}":                           MaxURLTemplateExpansion,
+		"https://example.com/{1..1000}/{1..101}":                           MaxURLTemplateExpansion + 1,
+		"https://example.com/{0..999999999}/{0..999999999}/{0..999999999}": MaxURLTemplateExpansion + 1,
+	}
+	for template, want := range cases {
+		if got := URLTemplateSize(template); got != want {
+			t.Fatalf("expected size %d for %q, got %d", want, template, got)
+		}
+	}
+}
+
+func TestListSkipsOversizedTemplates(t *testing.T) {
+	logger := &recordingLogger{}
+	list := "https://example.com/{1..1000}/{1..1000}\nhttps://example.com/{1..2}\n"
+	ch := ListFromReaderWithOptions(context.Background(), strings.NewReader(list), ListOptions{Logger: logger})
+
+	assertURLs(t, drain(ch), []string{"https://example.com/1", "https://example.com/2"})
+	if _, ok := logger.find("skipping article list line over the template expansion limit"); !ok {
+		t.Fatalf("expected the oversized line to be logged, got %v", logger.records)
+	}
+}
+
+func TestListFromFileExpandsTemplates(t *testing.T) {
+	path := writeList(t, "https://example.com/articles?page={1..3}\nhttps://example.com/about\n")
+	ch, err := ListFromFile(context.Background(), path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	assertURLs(t, drain(ch), []string{
+		"https://example.com/articles?page=1",
+		"https://example.com/articles?page=2",
+		"https://example.com/articles?page=3",
+		"https://example.com/about",
+	})
+
+	n, err := CountList(context.Background(), path, ListOptions{})
+	if err != nil || n != 4 {
+		t.Fatalf("expected 4 URLs, got %d (err %v)", n, err)
+	}
+}
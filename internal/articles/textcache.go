@@ -0,0 +1,142 @@
+package articles
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/shoresh319/firefly/internal/processing"
+)
+
+const (
+	// DefaultTextCacheTTL is how long NewCachingFetcher keeps an article's
+	// text when ttl is zero.
+	DefaultTextCacheTTL = 10 * time.Minute
+	// DefaultTextCacheSize is the number of articles NewCachingFetcher keeps
+	// when maxEntries is zero.
+	DefaultTextCacheSize = 1000
+)
+
+// TextCacheStats counts how a CachingFetcher's lookups went.
+type TextCacheStats struct {
+	Hits      int64 // Fetches served from the cache
+	Misses    int64 // Fetches passed to the wrapped fetcher, including expired entries
+	Evictions int64 // Entries dropped to stay within the size cap
+	Expired   int64 // Entries dropped because their TTL had passed
+	Entries   int   // Entries currently cached
+}
+
+// CachingFetcher is a processing.ArticleFetcher that remembers the text
+// another fetcher returned for each URL, so repeated requests for the same
+// articles, such as overlapping /count jobs, skip the network. Entries expire
+// after a TTL and the least recently used one is evicted once the cache is
+// full. Failed fetches are not cached. Expired entries are dropped as they are
+// looked up, so there is no background goroutine to stop. It is safe for
+// concurrent use.
+type CachingFetcher struct {
+	inner      processing.ArticleFetcher
+	ttl        time.Duration
+	maxEntries int
+	now        func() time.Time
+
+	mu      sync.Mutex
+	order   *list.List // Front is the most recently used
+	entries map[string]*list.Element
+	stats   TextCacheStats
+}
+
+type textCacheEntry struct {
+	url     string
+	text    string
+	expires time.Time
+}
+
+// NewCachingFetcher wraps inner with a cache of up to maxEntries articles kept
+// for ttl. Zero values pick DefaultTextCacheTTL and DefaultTextCacheSize.
+func NewCachingFetcher(inner processing.ArticleFetcher, ttl time.Duration, maxEntries int) *CachingFetcher {
+	if ttl <= 0 {
+		ttl = DefaultTextCacheTTL
+	}
+	if maxEntries <= 0 {
+		maxEntries = DefaultTextCacheSize
+	}
+	return &CachingFetcher{
+		inner:      inner,
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		now:        time.Now,
+		order:      list.New(),
+		entries:    make(map[string]*list.Element),
+	}
+}
+
+// Fetch returns the cached text for url, or fetches and caches it.
+func (c *CachingFetcher) Fetch(ctx context.Context, url string) (string, error) {
+	if text, ok := c.get(url); ok {
+		return text, nil
+	}
+
+	text, err := c.inner.Fetch(ctx, url)
+	if err != nil {
+		return "", err
+	}
+	c.put(url, text)
+	return text, nil
+}
+
+// Stats returns the cache's counters.
+func (c *CachingFetcher) Stats() TextCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stats := c.stats
+	stats.Entries = c.order.Len()
+	return stats
+}
+
+func (c *CachingFetcher) get(url string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[url]
+	if !ok {
+		c.stats.Misses++
+		return "", false
+	}
+	entry := elem.Value.(*textCacheEntry)
+	if !c.now().Before(entry.expires) {
+		c.remove(elem)
+		c.stats.Expired++
+		c.stats.Misses++
+		return "", false
+	}
+	c.order.MoveToFront(elem)
+	c.stats.Hits++
+	return entry.text, true
+}
+
+func (c *CachingFetcher) put(url, text string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expires := c.now().Add(c.ttl)
+	if elem, ok := c.entries[url]; ok {
+		// Another fetch of the same URL finished first; keep the newer text.
+		entry := elem.Value.(*textCacheEntry)
+		entry.text, entry.expires = text, expires
+		c.order.MoveToFront(elem)
+		return
+	}
+
+	c.entries[url] = c.order.PushFront(&textCacheEntry{url: url, text: text, expires: expires})
+	for c.order.Len() > c.maxEntries {
+		c.remove(c.order.Back())
+		c.stats.Evictions++
+	}
+}
+
+// remove drops elem from the cache. Callers hold c.mu.
+func (c *CachingFetcher) remove(elem *list.Element) {
+	c.order.Remove(elem)
+	delete(c.entries, elem.Value.(*textCacheEntry).url)
+}
@@ -0,0 +1,131 @@
+package articles
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+// countingFetcher returns "text of <url>" and counts the fetches of each URL.
+// URLs in fail return an error instead.
+type countingFetcher struct {
+	mu    sync.Mutex
+	calls map[string]int
+	fail  map[string]bool
+}
+
+func (f *countingFetcher) Fetch(_ context.Context, url string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.calls == nil {
+		f.calls = make(map[string]int)
+	}
+	f.calls[url]++
+	if f.fail[url] {
+		return "", errors.New("fetch failed")
+	}
+	return "text of " + url, nil
+}
+
+func (f *countingFetcher) count(url string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls[url]
+}
+
+func TestCachingFetcherHitsWithinTTL(t *testing.T) {
+	inner := &countingFetcher{}
+	cache := NewCachingFetcher(inner, time.Minute, 10)
+	now := time.Unix(0, 0)
+	cache.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		text, err := cache.Fetch(context.Background(), "a")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if text != "text of a" {
+			t.Fatalf("expected %q, got %q", "text of a", text)
+		}
+	}
+	if inner.count("a") != 1 {
+		t.Fatalf("expected the second fetch to hit the cache, got %d inner fetches", inner.count("a"))
+	}
+	if stats := cache.Stats(); stats.Hits != 1 || stats.Misses != 1 || stats.Entries != 1 {
+		t.Fatalf("expected 1 hit, 1 miss and 1 entry, got %+v", stats)
+	}
+
+	now = now.Add(time.Minute)
+	if _, err := cache.Fetch(context.Background(), "a"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if inner.count("a") != 2 {
+		t.Fatalf("expected an expired entry to be refetched, got %d inner fetches", inner.count("a"))
+	}
+	if stats := cache.Stats(); stats.Expired != 1 || stats.Misses != 2 {
+		t.Fatalf("expected 1 expiry and 2 misses, got %+v", stats)
+	}
+}
+
+func TestCachingFetcherEvictsLeastRecentlyUsed(t *testing.T) {
+	inner := &countingFetcher{}
+	cache := NewCachingFetcher(inner, time.Minute, 2)
+	ctx := context.Background()
+
+	for _, url := range []string{"a", "b", "a", "c", "a", "b"} {
+		if _, err := cache.Fetch(ctx, url); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	// "b" was least recently used when "c" arrived, so only it was refetched.
+	if inner.count("a") != 1 || inner.count("b") != 2 || inner.count("c") != 1 {
+		t.Fatalf("expected fetches a=1 b=2 c=1, got %v", inner.calls)
+	}
+	if stats := cache.Stats(); stats.Evictions != 2 || stats.Entries != 2 {
+		t.Fatalf("expected 2 evictions and 2 entries, got %+v", stats)
+	}
+}
+
+func TestCachingFetcherDoesNotCacheFailures(t *testing.T) {
+	inner := &countingFetcher{fail: map[string]bool{"bad": true}}
+	cache := NewCachingFetcher(inner, 0, 0)
+
+	for i := 0; i < 2; i++ {
+		if _, err := cache.Fetch(context.Background(), "bad"); err == nil {
+			t.Fatal("expected the inner error")
+		}
+	}
+	if inner.count("bad") != 2 || cache.Stats().Entries != 0 {
+		t.Fatalf("expected failures to be retried and not cached, got %d fetches and %+v", inner.count("bad"), cache.Stats())
+	}
+}
+
+func TestCachingFetcherConcurrentUse(t *testing.T) {
+	cache := NewCachingFetcher(&countingFetcher{}, time.Minute, 8)
+
+	var wg sync.WaitGroup
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				url := fmt.Sprintf("u%d", i%16)
+				text, err := cache.Fetch(context.Background(), url)
+				if err != nil || text != "text of "+url {
+					t.Errorf("fetch %s: got %q, %v", url, text, err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	stats := cache.Stats()
+	if stats.Entries > 8 || stats.Hits+stats.Misses != 8*200 {
+		t.Fatalf("expected at most 8 entries and 1600 lookups, got %+v", stats)
+	}
+}
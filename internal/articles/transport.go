@@ -0,0 +1,118 @@
+package articles
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Connection pool defaults applied to the transport NewSource builds when
+// SourceConfig.HTTPClient, or its Transport, is nil. The standard library keeps
+// only two idle connections per host, so a crawler fetching several articles
+// from the same site at once keeps reconnecting.
+const (
+	DefaultMaxIdleConns        = 100
+	DefaultMaxIdleConnsPerHost = 16
+	DefaultIdleConnTimeout     = 90 * time.Second
+)
+
+// transportTuning holds the SourceConfig connection pool settings.
+type transportTuning struct {
+	maxIdleConns        int
+	maxIdleConnsPerHost int
+	idleConnTimeout     time.Duration
+	disableKeepAlives   bool
+}
+
+// isZero reports whether no setting was given.
+func (t transportTuning) isZero() bool {
+	return t == transportTuning{}
+}
+
+// tunedTransport returns a copy of base with the connection pool settings
+// applied. A nil base means http.DefaultTransport, and its unset fields fall
+// back to the Default* values; a caller's own transport only has the fields
+// that were set explicitly overridden.
+func tunedTransport(base *http.Transport, tuning transportTuning) *http.Transport {
+	if base == nil {
+		base = http.DefaultTransport.(*http.Transport)
+		if tuning.maxIdleConns == 0 {
+			tuning.maxIdleConns = DefaultMaxIdleConns
+		}
+		if tuning.maxIdleConnsPerHost == 0 {
+			tuning.maxIdleConnsPerHost = DefaultMaxIdleConnsPerHost
+		}
+		if tuning.idleConnTimeout == 0 {
+			tuning.idleConnTimeout = DefaultIdleConnTimeout
+		}
+	}
+	transport := base.Clone()
+
+	if tuning.maxIdleConns > 0 {
+		transport.MaxIdleConns = tuning.maxIdleConns
+	}
+	if tuning.maxIdleConnsPerHost > 0 {
+		transport.MaxIdleConnsPerHost = tuning.maxIdleConnsPerHost
+	}
+	if tuning.idleConnTimeout > 0 {
+		transport.IdleConnTimeout = tuning.idleConnTimeout
+	}
+	if tuning.disableKeepAlives {
+		transport.DisableKeepAlives = true
+	}
+	return transport
+}
+
+// attemptTimeoutTransport applies a deadline to every round trip it performs.
+// Since retryablehttp issues one round trip per attempt, each retry gets a
+// fresh deadline derived from the caller's context.
+type attemptTimeoutTransport struct {
+	next    http.RoundTripper
+	timeout time.Duration
+}
+
+func (t *attemptTimeoutTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	next := t.next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	ctx, cancel := context.WithTimeout(req.Context(), t.timeout)
+	resp, err := next.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	// Keep the deadline alive until the body has been consumed.
+	resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// cancelOnClose releases the attempt context once the body is closed.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c *cancelOnClose) Close() error {
+	err := c.ReadCloser.Close()
+	c.cancel()
+	return err
+}
+
+// releaseOnClose gives back a fetch's concurrency slots once its body is
+// closed. Close may be called more than once.
+type releaseOnClose struct {
+	io.ReadCloser
+	release func()
+	once    sync.Once
+}
+
+func (r *releaseOnClose) Close() error {
+	err := r.ReadCloser.Close()
+	r.once.Do(r.release)
+	return err
+}
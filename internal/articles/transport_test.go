@@ -0,0 +1,117 @@
+package articles
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/shoresh319/firefly/internal/logging"
+)
+
+func sourceTransport(t testing.TB, source *Source) *http.Transport {
+	t.Helper()
+	transport, ok := source.client.HTTPClient.Transport.(*http.Transport)
+	if !ok {
+		t.Fatalf("expected *http.Transport, got %T", source.client.HTTPClient.Transport)
+	}
+	return transport
+}
+
+func TestTransportTuningAppliedAsConfigured(t *testing.T) {
+	source := NewSource(SourceConfig{
+		Logger:              logging.Nop(),
+		MaxIdleConns:        42,
+		MaxIdleConnsPerHost: 7,
+		IdleConnTimeout:     3 * time.Second,
+		DisableKeepAlives:   true,
+	})
+
+	transport := sourceTransport(t, source)
+	if transport.MaxIdleConns != 42 {
+		t.Fatalf("expected MaxIdleConns 42, got %d", transport.MaxIdleConns)
+	}
+	if transport.MaxIdleConnsPerHost != 7 {
+		t.Fatalf("expected MaxIdleConnsPerHost 7, got %d", transport.MaxIdleConnsPerHost)
+	}
+	if transport.IdleConnTimeout != 3*time.Second {
+		t.Fatalf("expected IdleConnTimeout 3s, got %v", transport.IdleConnTimeout)
+	}
+	if !transport.DisableKeepAlives {
+		t.Fatal("expected keep-alives to be disabled")
+	}
+}
+
+func TestTransportTuningDefaults(t *testing.T) {
+	transport := sourceTransport(t, NewSource(SourceConfig{Logger: logging.Nop()}))
+
+	if transport.MaxIdleConns != DefaultMaxIdleConns {
+		t.Fatalf("expected MaxIdleConns %d, got %d", DefaultMaxIdleConns, transport.MaxIdleConns)
+	}
+	if transport.MaxIdleConnsPerHost != DefaultMaxIdleConnsPerHost {
+		t.Fatalf("expected MaxIdleConnsPerHost %d, got %d", DefaultMaxIdleConnsPerHost, transport.MaxIdleConnsPerHost)
+	}
+	if transport.IdleConnTimeout != DefaultIdleConnTimeout {
+		t.Fatalf("expected IdleConnTimeout %v, got %v", DefaultIdleConnTimeout, transport.IdleConnTimeout)
+	}
+	if transport.DisableKeepAlives {
+		t.Fatal("expected keep-alives to be enabled")
+	}
+}
+
+func TestTransportTuningKeepsCallerTransport(t *testing.T) {
+	base := &http.Transport{DisableCompression: true, MaxIdleConns: 5}
+	source := NewSource(SourceConfig{
+		HTTPClient:          &http.Client{Transport: base},
+		Logger:              logging.Nop(),
+		MaxIdleConnsPerHost: 9,
+	})
+
+	transport := sourceTransport(t, source)
+	if transport == base {
+		t.Fatal("expected the caller's transport to be copied, not modified")
+	}
+	if transport.MaxIdleConnsPerHost != 9 || transport.MaxIdleConns != 5 || !transport.DisableCompression {
+		t.Fatalf("expected only MaxIdleConnsPerHost to change, got %+v", transport)
+	}
+	if base.MaxIdleConnsPerHost != 0 {
+		t.Fatalf("expected caller's transport untouched, got MaxIdleConnsPerHost %d", base.MaxIdleConnsPerHost)
+	}
+}
+
+func BenchmarkFetchConnectionReuse(b *testing.B) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(testHTML))
+	}))
+	defer server.Close()
+
+	for _, bc := range []struct {
+		name string
+		cfg  SourceConfig
+	}{
+		{"tuned", SourceConfig{}},
+		{"stdlib", SourceConfig{MaxIdleConns: 100, MaxIdleConnsPerHost: 2}},
+		{"no-keepalive", SourceConfig{DisableKeepAlives: true}},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			cfg := bc.cfg
+			cfg.Logger = logging.Nop()
+			cfg.ConcurrencyPerDomain = 16
+			source := NewSource(cfg)
+			defer sourceTransport(b, source).CloseIdleConnections()
+
+			b.SetParallelism(4)
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					if _, err := source.Fetch(context.Background(), fmt.Sprintf("%s/article", server.URL)); err != nil {
+						b.Errorf("fetch: %v", err)
+						return
+					}
+				}
+			})
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/shoresh319/firefly/internal/app"
+)
+
+// fileConfig mirrors the JSON layout of a firefly configuration file.
+type fileConfig struct {
+	WordBankPath         string   `json:"wordBankPath"`
+	ArticleListPath      string   `json:"articleListPath"`
+	TopN                 int      `json:"topN"`
+	Workers              int      `json:"workers"`
+	RetryMax             int      `json:"retryMax"`
+	RetryWaitMin         Duration `json:"retryWaitMin"`
+	RetryWaitMax         Duration `json:"retryWaitMax"`
+	PerRequestTimeout    Duration `json:"perRequestTimeout"`
+	ConcurrencyPerDomain int      `json:"concurrencyPerDomain"`
+	MaxTotalConcurrency  int      `json:"maxTotalConcurrency"`
+	ProxyURL             string   `json:"proxyURL"`
+	AllowLocalFiles      bool     `json:"allowLocalFiles"`
+	OutputFormat         string   `json:"outputFormat"`
+}
+
+// Duration is a time.Duration that unmarshals from strings such as "2s".
+type Duration time.Duration
+
+// UnmarshalJSON parses a Go duration string.
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("duration must be a string like \"2s\", got %s", data)
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return fmt.Errorf("invalid duration %q: %w", s, err)
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// LoadFile reads a JSON configuration file into an app.Config. Omitted fields
+// are left zero so that app.New applies its defaults.
+func LoadFile(path string) (app.Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return app.Config{}, fmt.Errorf("read config file: %w", err)
+	}
+
+	var fc fileConfig
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&fc); err != nil {
+		return app.Config{}, fmt.Errorf("parse config file %s: %w", path, err)
+	}
+
+	if err := fc.validate(); err != nil {
+		return app.Config{}, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+
+	return app.Config{
+		WordBankPath:         fc.WordBankPath,
+		ArticleListPath:      fc.ArticleListPath,
+		TopWordNum:           fc.TopN,
+		WorkerCount:          fc.Workers,
+		RetryMax:             fc.RetryMax,
+		RetryWaitMin:         time.Duration(fc.RetryWaitMin),
+		RetryWaitMax:         time.Duration(fc.RetryWaitMax),
+		PerRequestTimeout:    time.Duration(fc.PerRequestTimeout),
+		ConcurrencyPerDomain: fc.ConcurrencyPerDomain,
+		MaxTotalConcurrency:  fc.MaxTotalConcurrency,
+		ProxyURL:             fc.ProxyURL,
+		AllowLocalFiles:      fc.AllowLocalFiles,
+		OutputFormat:         fc.OutputFormat,
+	}, nil
+}
+
+func (fc fileConfig) validate() error {
+	var errs []error
+	if fc.WordBankPath == "" {
+		errs = append(errs, errors.New("wordBankPath is required"))
+	}
+	if fc.ArticleListPath == "" {
+		errs = append(errs, errors.New("articleListPath is required"))
+	}
+	for name, value := range map[string]int{
+		"topN":                 fc.TopN,
+		"workers":              fc.Workers,
+		"retryMax":             fc.RetryMax,
+		"concurrencyPerDomain": fc.ConcurrencyPerDomain,
+		"maxTotalConcurrency":  fc.MaxTotalConcurrency,
+	} {
+		if value < 0 {
+			errs = append(errs, fmt.Errorf("%s must not be negative, got %d", name, value))
+		}
+	}
+	if fc.RetryWaitMin > 0 && fc.RetryWaitMax > 0 && fc.RetryWaitMin > fc.RetryWaitMax {
+		errs = append(errs, errors.New("retryWaitMin must not exceed retryWaitMax"))
+	}
+	if fc.ProxyURL != "" {
+		if _, err := url.Parse(fc.ProxyURL); err != nil {
+			errs = append(errs, fmt.Errorf("invalid proxyURL: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,88 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "firefly.json")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	return path
+}
+
+func TestLoadFileFull(t *testing.T) {
+	path := writeConfig(t, `{
+		"wordBankPath": "words.txt",
+		"articleListPath": "urls.txt",
+		"topN": 25,
+		"workers": 4,
+		"retryMax": 5,
+		"retryWaitMin": "2s",
+		"retryWaitMax": "1m30s",
+		"perRequestTimeout": "500ms",
+		"concurrencyPerDomain": 6,
+		"maxTotalConcurrency": 50,
+		"proxyURL": "socks5://proxy.internal:1080",
+		"allowLocalFiles": true,
+		"outputFormat": "csv"
+	}`)
+
+	cfg, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if cfg.WordBankPath != "words.txt" || cfg.ArticleListPath != "urls.txt" {
+		t.Fatalf("unexpected paths: %+v", cfg)
+	}
+	if cfg.TopWordNum != 25 || cfg.WorkerCount != 4 || cfg.RetryMax != 5 {
+		t.Fatalf("unexpected numbers: %+v", cfg)
+	}
+	if cfg.RetryWaitMin != 2*time.Second || cfg.RetryWaitMax != 90*time.Second || cfg.PerRequestTimeout != 500*time.Millisecond {
+		t.Fatalf("unexpected durations: %+v", cfg)
+	}
+	if cfg.ConcurrencyPerDomain != 6 || cfg.MaxTotalConcurrency != 50 || cfg.ProxyURL != "socks5://proxy.internal:1080" || !cfg.AllowLocalFiles || cfg.OutputFormat != "csv" {
+		t.Fatalf("unexpected settings: %+v", cfg)
+	}
+}
+
+func TestLoadFileMinimalLeavesDefaults(t *testing.T) {
+	cfg, err := LoadFile(writeConfig(t, `{"wordBankPath": "words.txt", "articleListPath": "urls.txt"}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Zero values let app.New fill in its defaults.
+	if cfg.TopWordNum != 0 || cfg.RetryMax != 0 || cfg.RetryWaitMin != 0 || cfg.ConcurrencyPerDomain != 0 {
+		t.Fatalf("expected unset fields to stay zero, got %+v", cfg)
+	}
+}
+
+func TestLoadFileErrors(t *testing.T) {
+	tests := map[string]struct {
+		content string
+		want    string
+	}{
+		"missing required": {content: `{"topN": 5}`, want: "wordBankPath is required"},
+		"bad duration":     {content: `{"wordBankPath": "w", "articleListPath": "u", "retryWaitMin": "soon"}`, want: `invalid duration "soon"`},
+		"numeric duration": {content: `{"wordBankPath": "w", "articleListPath": "u", "retryWaitMin": 5}`, want: "duration must be a string"},
+		"unknown field":    {content: `{"wordBankPath": "w", "articleListPath": "u", "topWords": 5}`, want: `unknown field "topWords"`},
+		"negative":         {content: `{"wordBankPath": "w", "articleListPath": "u", "workers": -1}`, want: "workers must not be negative"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := LoadFile(writeConfig(t, tt.content))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
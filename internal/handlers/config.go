@@ -0,0 +1,30 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/shoresh319/firefly/internal/app"
+)
+
+// ConfigHandler reports the configuration the service runs with, for
+// checking what retry, concurrency and timeout settings are in effect.
+// Secrets are left out; see app.PublicConfig.
+type ConfigHandler struct {
+	cfg app.PublicConfig
+}
+
+// NewConfigHandler constructs a handler reporting cfg, typically App.Config
+// so defaults are included.
+func NewConfigHandler(cfg app.Config) *ConfigHandler {
+	return &ConfigHandler{cfg: cfg.Public()}
+}
+
+// ServeHTTP handles GET /config.
+func (h *ConfigHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		writeJSON(w, http.StatusMethodNotAllowed, errorResponse{Error: "method not allowed"})
+		return
+	}
+	writeJSON(w, http.StatusOK, h.cfg)
+}
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/shoresh319/firefly/internal/logging"
+	"github.com/shoresh319/firefly/internal/processing"
+)
+
+const (
+	defaultTopN = 10
+	// maxRequestBodyBytes bounds the JSON body of a count request.
+	maxRequestBodyBytes = 1 << 20
+)
+
+type countRequest struct {
+	URLs []string `json:"urls"`
+	TopN int      `json:"topN"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// CountHandler runs a word-count job over the posted URLs and returns the
+// top-N words as JSON. The fetcher is shared across requests so per-domain
+// concurrency limits apply service-wide.
+type CountHandler struct {
+	fetcher   processing.ArticleFetcher
+	validator processing.WordValidator
+	logger    logging.Logger
+	opts      []processing.Option
+}
+
+// NewCountHandler constructs a CountHandler backed by the given fetcher and
+// validator. A nil logger uses logging.Default(). Additional options are
+// applied to every Counter the handler creates.
+func NewCountHandler(fetcher processing.ArticleFetcher, validator processing.WordValidator, logger logging.Logger, opts ...processing.Option) *CountHandler {
+	if logger == nil {
+		logger = logging.Default()
+	}
+	return &CountHandler{
+		fetcher:   fetcher,
+		validator: validator,
+		logger:    logger,
+		opts:      opts,
+	}
+}
+
+// ServeHTTP handles POST /count.
+func (h *CountHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeJSON(w, http.StatusMethodNotAllowed, errorResponse{Error: "method not allowed"})
+		return
+	}
+
+	var req countRequest
+	if !decodeCountRequest(w, r, &req) {
+		return
+	}
+	if len(req.URLs) == 0 {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: "urls must not be empty"})
+		return
+	}
+	if req.TopN <= 0 {
+		req.TopN = defaultTopN
+	}
+
+	// Tag the run's logs, including those of the counter, with the request ID.
+	logger := logging.FromContext(r.Context(), h.logger)
+	opts := append([]processing.Option{processing.WithLogger(logger)}, h.opts...)
+	counter := processing.NewCounter(h.fetcher, pinValidator(h.validator), opts...)
+	counts, err := counter.CountTopWords(r.Context(), urlChannel(req.URLs), req.TopN)
+	if err != nil {
+		logger.Error("count request failed", "error", err)
+		writeJSON(w, http.StatusInternalServerError, errorResponse{Error: "count failed"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, counts)
+}
+
+// decodeCountRequest reads r's JSON body, of at most maxRequestBodyBytes, into
+// req. On failure it replies 413 or 400 and returns false.
+func decodeCountRequest(w http.ResponseWriter, r *http.Request, req *countRequest) bool {
+	err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBodyBytes)).Decode(req)
+	if err == nil {
+		return true
+	}
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		writeJSON(w, http.StatusRequestEntityTooLarge, errorResponse{Error: "request body too large"})
+		return false
+	}
+	writeJSON(w, http.StatusBadRequest, errorResponse{Error: "malformed JSON body"})
+	return false
+}
+
+// urlChannel returns a closed channel pre-loaded with urls.
+func urlChannel(urls []string) <-chan string {
+	urlCh := make(chan string, len(urls))
+	for _, u := range urls {
+		urlCh <- u
+	}
+	close(urlCh)
+	return urlCh
+}
+
+func writeJSON(w http.ResponseWriter, status int, payload any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(payload)
+}
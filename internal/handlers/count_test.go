@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/shoresh319/firefly/internal/articles"
+	"github.com/shoresh319/firefly/internal/logging"
+	"github.com/shoresh319/firefly/internal/wordbank"
+)
+
+func newTestCountHandler() *CountHandler {
+	bank := map[string]struct{}{"firefly": {}, "glow": {}, "night": {}}
+	source := articles.NewSource(articles.SourceConfig{Logger: logging.Nop()})
+	return NewCountHandler(source, wordbank.NewValidator(wordbank.NewBank(bank)), logging.Nop())
+}
+
+func TestCountHandler(t *testing.T) {
+	pages := map[string]string{
+		"/one": "<html><body><p>firefly glow night</p></body></html>",
+		"/two": "<html><body><p>firefly glow firefly</p></body></html>",
+	}
+	articleSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(pages[r.URL.Path]))
+	}))
+	defer articleSrv.Close()
+
+	body := fmt.Sprintf(`{"urls": [%q, %q], "topN": 2}`, articleSrv.URL+"/one", articleSrv.URL+"/two")
+	req := httptest.NewRequest(http.MethodPost, "/count", strings.NewReader(body))
+	rr := httptest.NewRecorder()
+
+	newTestCountHandler().ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	var counts map[string]int
+	if err := json.Unmarshal(rr.Body.Bytes(), &counts); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+
+	want := map[string]int{"firefly": 3, "glow": 2}
+	if len(counts) != len(want) {
+		t.Fatalf("expected %v, got %v", want, counts)
+	}
+	for word, count := range want {
+		if counts[word] != count {
+			t.Fatalf("expected %s=%d, got %d", word, count, counts[word])
+		}
+	}
+}
+
+func TestCountHandlerMalformedJSON(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/count", strings.NewReader(`{"urls": [`))
+	rr := httptest.NewRecorder()
+
+	newTestCountHandler().ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected status %d, got %d", http.StatusBadRequest, rr.Code)
+	}
+}
+
+func TestCountHandlerRejectsLargeBodies(t *testing.T) {
+	body := `{"urls": ["` + strings.Repeat("a", maxRequestBodyBytes) + `"]}`
+	req := httptest.NewRequest(http.MethodPost, "/count", strings.NewReader(body))
+	rr := httptest.NewRecorder()
+
+	newTestCountHandler().ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected status %d, got %d", http.StatusRequestEntityTooLarge, rr.Code)
+	}
+}
@@ -1,17 +1,85 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
+	"time"
+
+	"github.com/shoresh319/firefly/internal/processing"
 )
 
 type healthResponse struct {
-	Status string `json:"status"`
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
 }
 
-// Health returns a simple ok response for readiness/liveness probes.
+// Health returns a simple ok response for liveness probes.
 func Health(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
 	w.WriteHeader(http.StatusOK)
 	_ = json.NewEncoder(w).Encode(healthResponse{Status: "ok"})
 }
+
+// HealthCheck reports whether a dependency is ready, returning nil when it is.
+type HealthCheck func(ctx context.Context) error
+
+// DefaultHealthCheckTimeout bounds each check run by a HealthHandler.
+const DefaultHealthCheckTimeout = 5 * time.Second
+
+// HealthHandler runs a set of named readiness checks on every request. It
+// responds 200 when all pass and 503 when any fails, with the status of each
+// check in the body.
+type HealthHandler struct {
+	checks  map[string]HealthCheck
+	timeout time.Duration
+}
+
+// NewHealthHandler constructs a handler running checks, keyed by the name
+// reported in the response.
+func NewHealthHandler(checks map[string]HealthCheck) *HealthHandler {
+	return &HealthHandler{checks: checks, timeout: DefaultHealthCheckTimeout}
+}
+
+// ServeHTTP handles GET /healthz.
+func (h *HealthHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	resp := healthResponse{Status: "ok", Checks: make(map[string]string, len(h.checks))}
+	status := http.StatusOK
+	for name, check := range h.checks {
+		if err := h.run(r.Context(), check); err != nil {
+			resp.Checks[name] = err.Error()
+			resp.Status = "unavailable"
+			status = http.StatusServiceUnavailable
+			continue
+		}
+		resp.Checks[name] = "ok"
+	}
+	writeJSON(w, status, resp)
+}
+
+func (h *HealthHandler) run(ctx context.Context, check HealthCheck) error {
+	ctx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+	return check(ctx)
+}
+
+// WordBankCheck fails while v, or the validator held by a ValidatorStore, is
+// nil.
+func WordBankCheck(v processing.WordValidator) HealthCheck {
+	return func(context.Context) error {
+		if v == nil || pinValidator(v) == nil {
+			return errors.New("word bank not loaded")
+		}
+		return nil
+	}
+}
+
+// FetchCheck fetches url through fetcher and fails when the fetch does, for
+// confirming that article sources are reachable.
+func FetchCheck(fetcher processing.ArticleFetcher, url string) HealthCheck {
+	return func(ctx context.Context) error {
+		_, err := fetcher.Fetch(ctx, url)
+		return err
+	}
+}
@@ -1,10 +1,14 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+
+	"github.com/shoresh319/firefly/internal/wordbank"
 )
 
 func TestHealth(t *testing.T) {
@@ -31,4 +35,61 @@ func TestHealth(t *testing.T) {
 	}
 }
 
+type fetcherFunc func(ctx context.Context, url string) (string, error)
+
+func (f fetcherFunc) Fetch(ctx context.Context, url string) (string, error) {
+	return f(ctx, url)
+}
+
+func TestHealthHandlerReportsChecks(t *testing.T) {
+	handler := NewHealthHandler(map[string]HealthCheck{
+		"wordbank": WordBankCheck(wordbank.NewValidator(wordbank.NewBank(map[string]struct{}{"firefly": {}}))),
+	})
 
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+	}
+	var payload healthResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &payload); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if payload.Status != "ok" || payload.Checks["wordbank"] != "ok" {
+		t.Fatalf("expected ok status and wordbank check, got %+v", payload)
+	}
+}
+
+func TestHealthHandlerFailingDependency(t *testing.T) {
+	handler := NewHealthHandler(map[string]HealthCheck{
+		"wordbank": WordBankCheck(NewValidatorStore(nil)),
+		"fetch": FetchCheck(fetcherFunc(func(context.Context, string) (string, error) {
+			return "", errors.New("connection refused")
+		}), "http://example.invalid"),
+		"extra": func(context.Context) error { return nil },
+	})
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	if rr.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected status %d, got %d", http.StatusServiceUnavailable, rr.Code)
+	}
+	var payload healthResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &payload); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if payload.Status != "unavailable" {
+		t.Fatalf("expected status=unavailable, got %q", payload.Status)
+	}
+	if got := payload.Checks["fetch"]; got != "connection refused" {
+		t.Fatalf("expected fetch check to report the error, got %q", got)
+	}
+	if got := payload.Checks["wordbank"]; got != "word bank not loaded" {
+		t.Fatalf("expected wordbank check to fail, got %q", got)
+	}
+	if got := payload.Checks["extra"]; got != "ok" {
+		t.Fatalf("expected extra check to pass, got %q", got)
+	}
+}
@@ -0,0 +1,258 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/shoresh319/firefly/internal/logging"
+	"github.com/shoresh319/firefly/internal/processing"
+)
+
+const (
+	// DefaultMaxRunningJobs is how many async count jobs run at once; later
+	// jobs stay pending until one finishes.
+	DefaultMaxRunningJobs = 4
+	// DefaultMaxActiveJobs caps the jobs pending or running at once; further
+	// submissions are refused with 429 until some finish.
+	DefaultMaxActiveJobs = 100
+	// DefaultJobRetention is how long a finished job's result can be polled
+	// before it is discarded.
+	DefaultJobRetention = 15 * time.Minute
+)
+
+// JobStatus is the state of an async count job.
+type JobStatus string
+
+const (
+	JobPending   JobStatus = "pending"   // Waiting for a free slot
+	JobRunning   JobStatus = "running"   // Counting
+	JobDone      JobStatus = "done"      // Finished; the result is available
+	JobFailed    JobStatus = "failed"    // Stopped by an error
+	JobCancelled JobStatus = "cancelled" // Cancelled with DELETE or on shutdown
+)
+
+type jobResponse struct {
+	JobID  string         `json:"jobID"`
+	Status JobStatus      `json:"status"`
+	Result map[string]int `json:"result,omitempty"`
+	Error  string         `json:"error,omitempty"`
+}
+
+type countJob struct {
+	id       string
+	cancel   context.CancelFunc
+	status   JobStatus
+	result   map[string]int
+	err      string
+	finished time.Time
+}
+
+// CountJobs runs word-count jobs in the background for clients whose lists
+// take longer than an HTTP request should. Jobs are kept in memory: finished
+// ones are discarded DefaultJobRetention after they end, and Close cancels
+// those still pending or running.
+type CountJobs struct {
+	fetcher   processing.ArticleFetcher
+	validator processing.WordValidator
+	logger    logging.Logger
+	opts      []processing.Option
+	slots     chan struct{}
+	maxActive int
+	retention time.Duration
+	now       func() time.Time
+
+	mu     sync.Mutex
+	jobs   map[string]*countJob
+	active int // Jobs pending or running
+	closed bool
+}
+
+// NewCountJobs constructs a CountJobs backed by the given fetcher and
+// validator, as NewCountHandler does.
+func NewCountJobs(fetcher processing.ArticleFetcher, validator processing.WordValidator, logger logging.Logger, opts ...processing.Option) *CountJobs {
+	if logger == nil {
+		logger = logging.Default()
+	}
+	return &CountJobs{
+		fetcher:   fetcher,
+		validator: validator,
+		logger:    logger,
+		opts:      opts,
+		slots:     make(chan struct{}, DefaultMaxRunningJobs),
+		maxActive: DefaultMaxActiveJobs,
+		retention: DefaultJobRetention,
+		now:       time.Now,
+		jobs:      make(map[string]*countJob),
+	}
+}
+
+// ServeSubmit handles POST /count/async. It takes the same JSON body as
+// /count and replies 202 with {"jobID": "..."}, and the job's result URL in
+// the Location header. Once DefaultMaxActiveJobs jobs are pending or running
+// it replies 429, and bodies over 1 MiB are rejected with 413.
+func (j *CountJobs) ServeSubmit(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeJSON(w, http.StatusMethodNotAllowed, errorResponse{Error: "method not allowed"})
+		return
+	}
+
+	var req countRequest
+	if !decodeCountRequest(w, r, &req) {
+		return
+	}
+	if len(req.URLs) == 0 {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: "urls must not be empty"})
+		return
+	}
+	if req.TopN <= 0 {
+		req.TopN = defaultTopN
+	}
+
+	// The job outlives the request but keeps its values, such as the request ID.
+	ctx, cancel := context.WithCancel(context.WithoutCancel(r.Context()))
+	job := &countJob{id: newID(), cancel: cancel, status: JobPending}
+
+	j.mu.Lock()
+	if j.closed {
+		j.mu.Unlock()
+		cancel()
+		writeJSON(w, http.StatusServiceUnavailable, errorResponse{Error: "shutting down"})
+		return
+	}
+	if j.active >= j.maxActive {
+		j.mu.Unlock()
+		cancel()
+		writeJSON(w, http.StatusTooManyRequests, errorResponse{Error: "too many active jobs"})
+		return
+	}
+	j.sweep()
+	j.jobs[job.id] = job
+	j.active++
+	j.mu.Unlock()
+
+	go j.run(ctx, job, req)
+
+	w.Header().Set("Location", "/count/result/"+job.id)
+	writeJSON(w, http.StatusAccepted, jobResponse{JobID: job.id, Status: JobPending})
+}
+
+// ServeResult handles GET /count/result/{jobID}, reporting the job's status
+// and, once it is done, its top words.
+func (j *CountJobs) ServeResult(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		writeJSON(w, http.StatusMethodNotAllowed, errorResponse{Error: "method not allowed"})
+		return
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.sweep()
+	job, ok := j.jobs[r.PathValue("jobID")]
+	if !ok {
+		writeJSON(w, http.StatusNotFound, errorResponse{Error: "job not found"})
+		return
+	}
+	writeJSON(w, http.StatusOK, job.response())
+}
+
+// ServeCancel handles DELETE /count/{jobID}. A pending or running job is
+// cancelled; cancelling a finished job fails with 409.
+func (j *CountJobs) ServeCancel(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		w.Header().Set("Allow", http.MethodDelete)
+		writeJSON(w, http.StatusMethodNotAllowed, errorResponse{Error: "method not allowed"})
+		return
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	job, ok := j.jobs[r.PathValue("jobID")]
+	if !ok {
+		writeJSON(w, http.StatusNotFound, errorResponse{Error: "job not found"})
+		return
+	}
+	if !j.finish(job, JobCancelled, nil, "") {
+		writeJSON(w, http.StatusConflict, errorResponse{Error: "job already finished"})
+		return
+	}
+	writeJSON(w, http.StatusOK, job.response())
+}
+
+// Close cancels every pending or running job and rejects new ones. It suits
+// http.Server.RegisterOnShutdown.
+func (j *CountJobs) Close() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.closed = true
+	for _, job := range j.jobs {
+		j.finish(job, JobCancelled, nil, "")
+	}
+}
+
+// run waits for a slot and counts req.URLs, recording the outcome on job.
+func (j *CountJobs) run(ctx context.Context, job *countJob, req countRequest) {
+	select {
+	case <-ctx.Done():
+		return
+	case j.slots <- struct{}{}:
+	}
+	defer func() { <-j.slots }()
+
+	j.mu.Lock()
+	if job.status != JobPending {
+		j.mu.Unlock()
+		return
+	}
+	job.status = JobRunning
+	j.mu.Unlock()
+
+	logger := logging.FromContext(ctx, j.logger)
+	opts := append([]processing.Option{processing.WithLogger(logger)}, j.opts...)
+	counter := processing.NewCounter(j.fetcher, pinValidator(j.validator), opts...)
+	counts, err := counter.CountTopWords(ctx, urlChannel(req.URLs), req.TopN)
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	switch {
+	case errors.Is(err, context.Canceled):
+		j.finish(job, JobCancelled, nil, "")
+	case err != nil:
+		logger.Error("count job failed", "job_id", job.id, "error", err)
+		j.finish(job, JobFailed, nil, "count failed")
+	default:
+		j.finish(job, JobDone, counts, "")
+	}
+}
+
+// finish moves an unfinished job to status and releases its context,
+// reporting whether it did. Callers hold j.mu.
+func (j *CountJobs) finish(job *countJob, status JobStatus, result map[string]int, errMsg string) bool {
+	if job.status != JobPending && job.status != JobRunning {
+		return false
+	}
+	job.cancel()
+	j.active--
+	job.status, job.result, job.err = status, result, errMsg
+	job.finished = j.now()
+	return true
+}
+
+// sweep discards jobs that finished more than the retention period ago.
+// Callers hold j.mu.
+func (j *CountJobs) sweep() {
+	cutoff := j.now().Add(-j.retention)
+	for id, job := range j.jobs {
+		if !job.finished.IsZero() && job.finished.Before(cutoff) {
+			delete(j.jobs, id)
+		}
+	}
+}
+
+func (job *countJob) response() jobResponse {
+	return jobResponse{JobID: job.id, Status: job.status, Result: job.result, Error: job.err}
+}
@@ -0,0 +1,201 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shoresh319/firefly/internal/logging"
+	"github.com/shoresh319/firefly/internal/wordbank"
+)
+
+// gateFetcher returns "firefly glow firefly" once release is closed, or the
+// context's error if it ends first.
+type gateFetcher struct {
+	release chan struct{}
+}
+
+func (f gateFetcher) Fetch(ctx context.Context, _ string) (string, error) {
+	select {
+	case <-f.release:
+		return "firefly glow firefly", nil
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+// newTestJobsMux serves jobs on the routes server.New registers.
+func newTestJobsMux(jobs *CountJobs) *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/count/async", jobs.ServeSubmit)
+	mux.HandleFunc("/count/result/{jobID}", jobs.ServeResult)
+	mux.HandleFunc("/count/{jobID}", jobs.ServeCancel)
+	return mux
+}
+
+func newTestJobs(fetcher gateFetcher) *CountJobs {
+	bank := map[string]struct{}{"firefly": {}, "glow": {}}
+	return NewCountJobs(fetcher, wordbank.NewValidator(wordbank.NewBank(bank)), logging.Nop())
+}
+
+func doJobRequest(t *testing.T, mux http.Handler, method, path, body string) (int, jobResponse) {
+	t.Helper()
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest(method, path, strings.NewReader(body)))
+	var resp jobResponse
+	if rr.Code < 300 {
+		if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("failed to unmarshal response: %v", err)
+		}
+	}
+	return rr.Code, resp
+}
+
+// pollJob polls the job until it leaves the pending and running states.
+func pollJob(t *testing.T, mux http.Handler, id string) jobResponse {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		code, resp := doJobRequest(t, mux, http.MethodGet, "/count/result/"+id, "")
+		if code != http.StatusOK {
+			t.Fatalf("expected poll status %d, got %d", http.StatusOK, code)
+		}
+		if resp.Status != JobPending && resp.Status != JobRunning {
+			return resp
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("job %s did not finish", id)
+	return jobResponse{}
+}
+
+func TestCountJobsSubmitAndPoll(t *testing.T) {
+	fetcher := gateFetcher{release: make(chan struct{})}
+	mux := newTestJobsMux(newTestJobs(fetcher))
+
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/count/async", strings.NewReader(`{"urls": ["a", "b"], "topN": 5}`)))
+	if rr.Code != http.StatusAccepted {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusAccepted, rr.Code, rr.Body.String())
+	}
+	var submitted jobResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &submitted); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if submitted.JobID == "" {
+		t.Fatal("expected a job ID")
+	}
+	if loc := rr.Header().Get("Location"); loc != "/count/result/"+submitted.JobID {
+		t.Fatalf("expected Location of the result, got %q", loc)
+	}
+
+	if _, resp := doJobRequest(t, mux, http.MethodGet, "/count/result/"+submitted.JobID, ""); resp.Status != JobPending && resp.Status != JobRunning {
+		t.Fatalf("expected an unfinished job before the fetches complete, got %q", resp.Status)
+	}
+
+	close(fetcher.release)
+	done := pollJob(t, mux, submitted.JobID)
+	if done.Status != JobDone {
+		t.Fatalf("expected status %q, got %q", JobDone, done.Status)
+	}
+	if done.Result["firefly"] != 4 || done.Result["glow"] != 2 {
+		t.Fatalf("expected firefly=4 glow=2, got %v", done.Result)
+	}
+
+	if code, _ := doJobRequest(t, mux, http.MethodDelete, "/count/"+submitted.JobID, ""); code != http.StatusConflict {
+		t.Fatalf("expected cancelling a finished job to fail with %d, got %d", http.StatusConflict, code)
+	}
+}
+
+func TestCountJobsCancel(t *testing.T) {
+	fetcher := gateFetcher{release: make(chan struct{})}
+	mux := newTestJobsMux(newTestJobs(fetcher))
+
+	code, submitted := doJobRequest(t, mux, http.MethodPost, "/count/async", `{"urls": ["a"]}`)
+	if code != http.StatusAccepted {
+		t.Fatalf("expected status %d, got %d", http.StatusAccepted, code)
+	}
+
+	code, cancelled := doJobRequest(t, mux, http.MethodDelete, "/count/"+submitted.JobID, "")
+	if code != http.StatusOK || cancelled.Status != JobCancelled {
+		t.Fatalf("expected cancellation, got %d with %+v", code, cancelled)
+	}
+	if resp := pollJob(t, mux, submitted.JobID); resp.Status != JobCancelled || resp.Result != nil {
+		t.Fatalf("expected a cancelled job without result, got %+v", resp)
+	}
+}
+
+func TestCountJobsUnknownAndInvalid(t *testing.T) {
+	jobs := newTestJobs(gateFetcher{release: make(chan struct{})})
+	mux := newTestJobsMux(jobs)
+
+	if code, _ := doJobRequest(t, mux, http.MethodGet, "/count/result/missing", ""); code != http.StatusNotFound {
+		t.Fatalf("expected status %d for an unknown job, got %d", http.StatusNotFound, code)
+	}
+	if code, _ := doJobRequest(t, mux, http.MethodPost, "/count/async", `{"urls": []}`); code != http.StatusBadRequest {
+		t.Fatalf("expected status %d for an empty list, got %d", http.StatusBadRequest, code)
+	}
+
+	jobs.Close()
+	if code, _ := doJobRequest(t, mux, http.MethodPost, "/count/async", `{"urls": ["a"]}`); code != http.StatusServiceUnavailable {
+		t.Fatalf("expected status %d after Close, got %d", http.StatusServiceUnavailable, code)
+	}
+}
+
+func TestCountJobsCapsActiveJobs(t *testing.T) {
+	fetcher := gateFetcher{release: make(chan struct{})}
+	jobs := newTestJobs(fetcher)
+	jobs.maxActive = 2
+	defer jobs.Close()
+	mux := newTestJobsMux(jobs)
+
+	var ids []string
+	for range 2 {
+		code, resp := doJobRequest(t, mux, http.MethodPost, "/count/async", `{"urls": ["a"]}`)
+		if code != http.StatusAccepted {
+			t.Fatalf("expected status %d, got %d", http.StatusAccepted, code)
+		}
+		ids = append(ids, resp.JobID)
+	}
+	if code, _ := doJobRequest(t, mux, http.MethodPost, "/count/async", `{"urls": ["a"]}`); code != http.StatusTooManyRequests {
+		t.Fatalf("expected status %d over the cap, got %d", http.StatusTooManyRequests, code)
+	}
+
+	// Cancelling a job frees its place.
+	if code, _ := doJobRequest(t, mux, http.MethodDelete, "/count/"+ids[0], ""); code != http.StatusOK {
+		t.Fatalf("expected cancel status %d, got %d", http.StatusOK, code)
+	}
+	if code, _ := doJobRequest(t, mux, http.MethodPost, "/count/async", `{"urls": ["a"]}`); code != http.StatusAccepted {
+		t.Fatalf("expected status %d after a cancel, got %d", http.StatusAccepted, code)
+	}
+}
+
+func TestCountJobsRejectsLargeBodies(t *testing.T) {
+	mux := newTestJobsMux(newTestJobs(gateFetcher{release: make(chan struct{})}))
+
+	body := `{"urls": ["` + strings.Repeat("a", maxRequestBodyBytes) + `"]}`
+	if code, _ := doJobRequest(t, mux, http.MethodPost, "/count/async", body); code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected status %d, got %d", http.StatusRequestEntityTooLarge, code)
+	}
+}
+
+func TestCountJobsDiscardsOldResults(t *testing.T) {
+	fetcher := gateFetcher{release: make(chan struct{})}
+	close(fetcher.release)
+	jobs := newTestJobs(fetcher)
+	mux := newTestJobsMux(jobs)
+
+	_, submitted := doJobRequest(t, mux, http.MethodPost, "/count/async", `{"urls": ["a"]}`)
+	pollJob(t, mux, submitted.JobID)
+
+	jobs.mu.Lock()
+	jobs.now = func() time.Time { return time.Now().Add(DefaultJobRetention + time.Minute) }
+	jobs.mu.Unlock()
+	if code, _ := doJobRequest(t, mux, http.MethodGet, "/count/result/"+submitted.JobID, ""); code != http.StatusNotFound {
+		t.Fatalf("expected an expired job to be discarded, got status %d", code)
+	}
+}
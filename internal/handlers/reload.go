@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/shoresh319/firefly/internal/logging"
+	"github.com/shoresh319/firefly/internal/processing"
+	"github.com/shoresh319/firefly/internal/wordbank"
+)
+
+// ValidatorStore holds a word validator that can be replaced while the service
+// runs. It satisfies processing.WordValidator, so it can be passed to the count
+// handlers in place of a fixed validator; they pin the current validator when a
+// request starts, so a reload never changes the rules of a running count.
+type ValidatorStore struct {
+	mu        sync.RWMutex
+	validator processing.WordValidator
+}
+
+// NewValidatorStore returns a store holding v.
+func NewValidatorStore(v processing.WordValidator) *ValidatorStore {
+	return &ValidatorStore{validator: v}
+}
+
+// Load returns the current validator.
+func (s *ValidatorStore) Load() processing.WordValidator {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.validator
+}
+
+// Store replaces the current validator.
+func (s *ValidatorStore) Store(v processing.WordValidator) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.validator = v
+}
+
+// Validate checks word against the current validator.
+func (s *ValidatorStore) Validate(word string) bool {
+	return s.Load().Validate(word)
+}
+
+// pinValidator returns the validator a single count should use for its whole
+// run, resolving a ValidatorStore to its current contents.
+func pinValidator(v processing.WordValidator) processing.WordValidator {
+	if store, ok := v.(*ValidatorStore); ok {
+		return store.Load()
+	}
+	return v
+}
+
+type reloadResponse struct {
+	Words int `json:"words"`
+}
+
+// WordBankReloadHandler reloads the word bank from disk and swaps it into a
+// ValidatorStore.
+type WordBankReloadHandler struct {
+	store  *ValidatorStore
+	path   string
+	logger logging.Logger
+	opts   []wordbank.ValidatorOption
+}
+
+// NewWordBankReloadHandler constructs a handler that reads the bank at path
+// and stores a validator built with opts. A nil logger uses logging.Default().
+func NewWordBankReloadHandler(store *ValidatorStore, path string, logger logging.Logger, opts ...wordbank.ValidatorOption) *WordBankReloadHandler {
+	if logger == nil {
+		logger = logging.Default()
+	}
+	return &WordBankReloadHandler{
+		store:  store,
+		path:   path,
+		logger: logger,
+		opts:   opts,
+	}
+}
+
+// ServeHTTP handles POST /wordbank/reload. The previous word bank stays in
+// use when loading fails.
+func (h *WordBankReloadHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeJSON(w, http.StatusMethodNotAllowed, errorResponse{Error: "method not allowed"})
+		return
+	}
+
+	words, err := wordbank.LoadWeighted(r.Context(), h.path)
+	if err != nil {
+		h.logger.Error("word bank reload failed", "path", h.path, "error", err)
+		writeJSON(w, http.StatusInternalServerError, errorResponse{Error: "reload failed"})
+		return
+	}
+
+	h.store.Store(wordbank.NewValidator(wordbank.NewWeightedBank(words), h.opts...))
+	h.logger.Info("word bank reloaded", "path", h.path, "words", len(words))
+	writeJSON(w, http.StatusOK, reloadResponse{Words: len(words)})
+}
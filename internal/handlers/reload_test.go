@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/shoresh319/firefly/internal/logging"
+	"github.com/shoresh319/firefly/internal/wordbank"
+)
+
+func TestWordBankReloadHandler(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "words.txt")
+	if err := os.WriteFile(path, []byte("firefly\n"), 0o644); err != nil {
+		t.Fatalf("write word bank: %v", err)
+	}
+	words, err := wordbank.Load(t.Context(), path)
+	if err != nil {
+		t.Fatalf("load word bank: %v", err)
+	}
+	store := NewValidatorStore(wordbank.NewValidator(wordbank.NewBank(words)))
+	pinned := pinValidator(store)
+
+	if store.Validate("lantern") {
+		t.Fatalf("expected %q to be rejected before reload", "lantern")
+	}
+
+	if err := os.WriteFile(path, []byte("firefly\nlantern\nmeadow\n"), 0o644); err != nil {
+		t.Fatalf("write word bank: %v", err)
+	}
+
+	rr := httptest.NewRecorder()
+	NewWordBankReloadHandler(store, path, logging.Nop()).ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/wordbank/reload", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	var resp reloadResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if resp.Words != 3 {
+		t.Fatalf("expected 3 words, got %d", resp.Words)
+	}
+
+	if !store.Validate("lantern") || !store.Validate("meadow") {
+		t.Fatalf("expected reloaded words to be accepted")
+	}
+	if pinned.Validate("lantern") {
+		t.Fatalf("expected a pinned validator to keep the old word bank")
+	}
+}
+
+func TestWordBankReloadHandlerKeepsBankOnError(t *testing.T) {
+	store := NewValidatorStore(wordbank.NewValidator(wordbank.NewBank(map[string]struct{}{"firefly": {}})))
+	missing := filepath.Join(t.TempDir(), "missing.txt")
+
+	rr := httptest.NewRecorder()
+	NewWordBankReloadHandler(store, missing, logging.Nop()).ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/wordbank/reload", nil))
+	if rr.Code != http.StatusInternalServerError {
+		t.Fatalf("expected status %d, got %d", http.StatusInternalServerError, rr.Code)
+	}
+	if !store.Validate("firefly") {
+		t.Fatalf("expected the previous word bank to stay in use")
+	}
+}
+
+func TestWordBankReloadHandlerRejectsGet(t *testing.T) {
+	store := NewValidatorStore(wordbank.NewValidator(wordbank.NewBank(map[string]struct{}{"firefly": {}})))
+
+	rr := httptest.NewRecorder()
+	NewWordBankReloadHandler(store, "unused", logging.Nop()).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/wordbank/reload", nil))
+	if rr.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("expected status %d, got %d", http.StatusMethodNotAllowed, rr.Code)
+	}
+}
@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/shoresh319/firefly/internal/logging"
+)
+
+func TestRequestIDMiddleware(t *testing.T) {
+	var seen string
+	handler := RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = logging.RequestID(r.Context())
+	}))
+
+	tests := []struct {
+		name   string
+		header string
+		reuse  bool
+	}{
+		{name: "absent"},
+		{name: "provided", header: "abc-123", reuse: true},
+		{name: "spaces", header: "abc 123"},
+		{name: "too long", header: strings.Repeat("a", maxRequestIDLength+1)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.header != "" {
+				req.Header.Set(logging.RequestIDHeader, tt.header)
+			}
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			got := rr.Header().Get(logging.RequestIDHeader)
+			if got == "" || got != seen {
+				t.Fatalf("expected response ID to match context ID %q, got %q", seen, got)
+			}
+			if tt.reuse != (got == tt.header) {
+				t.Fatalf("expected reuse=%v of %q, got %q", tt.reuse, tt.header, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/shoresh319/firefly/internal/logging"
+	"github.com/shoresh319/firefly/internal/processing"
+)
+
+// CountStreamHandler runs a word-count job and reports progress as
+// Server-Sent Events. Each snapshot of the evolving top-N is sent as a data
+// event, and the final result is repeated in an event named "done".
+type CountStreamHandler struct {
+	fetcher   processing.ArticleFetcher
+	validator processing.WordValidator
+	logger    logging.Logger
+	opts      []processing.Option
+}
+
+// NewCountStreamHandler constructs a CountStreamHandler. Its arguments behave
+// as for NewCountHandler; use processing.WithSnapshotInterval to control how
+// often progress events are sent.
+func NewCountStreamHandler(fetcher processing.ArticleFetcher, validator processing.WordValidator, logger logging.Logger, opts ...processing.Option) *CountStreamHandler {
+	if logger == nil {
+		logger = logging.Default()
+	}
+	return &CountStreamHandler{
+		fetcher:   fetcher,
+		validator: validator,
+		logger:    logger,
+		opts:      opts,
+	}
+}
+
+// ServeHTTP handles /count/stream. GET takes repeated url parameters and an
+// optional topN; POST takes the same JSON body as /count.
+func (h *CountStreamHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req countRequest
+	switch r.Method {
+	case http.MethodGet:
+		req.URLs = r.URL.Query()["url"]
+		if topN := r.URL.Query().Get("topN"); topN != "" {
+			n, err := strconv.Atoi(topN)
+			if err != nil {
+				writeJSON(w, http.StatusBadRequest, errorResponse{Error: "topN must be an integer"})
+				return
+			}
+			req.TopN = n
+		}
+	case http.MethodPost:
+		if !decodeCountRequest(w, r, &req) {
+			return
+		}
+	default:
+		w.Header().Set("Allow", strings.Join([]string{http.MethodGet, http.MethodPost}, ", "))
+		writeJSON(w, http.StatusMethodNotAllowed, errorResponse{Error: "method not allowed"})
+		return
+	}
+	if len(req.URLs) == 0 {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: "urls must not be empty"})
+		return
+	}
+	if req.TopN <= 0 {
+		req.TopN = defaultTopN
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeJSON(w, http.StatusInternalServerError, errorResponse{Error: "streaming unsupported"})
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	logger := logging.FromContext(r.Context(), h.logger)
+	opts := append([]processing.Option{processing.WithLogger(logger)}, h.opts...)
+	counter := processing.NewCounter(h.fetcher, pinValidator(h.validator), opts...)
+	snapshots, errCh := counter.CountTopWordsStream(r.Context(), urlChannel(req.URLs), req.TopN)
+
+	var last map[string]int
+	for snapshot := range snapshots {
+		last = snapshot
+		if err := writeEvent(w, "", snapshot); err != nil {
+			logger.Warn("stream write failed", "error", err)
+		}
+		flusher.Flush()
+	}
+
+	if err := <-errCh; err != nil {
+		// The client went away; there is nobody left to tell.
+		logger.Info("count stream cancelled", "error", err)
+		return
+	}
+
+	if err := writeEvent(w, "done", last); err != nil {
+		logger.Warn("stream write failed", "error", err)
+	}
+	flusher.Flush()
+}
+
+// writeEvent writes payload as a single SSE event. An empty name sends an
+// unnamed data event.
+func writeEvent(w http.ResponseWriter, name string, payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("encode event: %w", err)
+	}
+	if name != "" {
+		if _, err := fmt.Fprintf(w, "event: %s\n", name); err != nil {
+			return err
+		}
+	}
+	_, err = fmt.Fprintf(w, "data: %s\n\n", data)
+	return err
+}
@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/shoresh319/firefly/internal/articles"
+	"github.com/shoresh319/firefly/internal/logging"
+	"github.com/shoresh319/firefly/internal/processing"
+	"github.com/shoresh319/firefly/internal/wordbank"
+)
+
+type sseEvent struct {
+	name string
+	data string
+}
+
+func readEvents(t *testing.T, body string) []sseEvent {
+	t.Helper()
+	var events []sseEvent
+	var current sseEvent
+	scanner := bufio.NewScanner(strings.NewReader(body))
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			if current.data != "" {
+				events = append(events, current)
+			}
+			current = sseEvent{}
+		case strings.HasPrefix(line, "event: "):
+			current.name = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			current.data = strings.TrimPrefix(line, "data: ")
+		default:
+			t.Fatalf("unexpected event stream line %q", line)
+		}
+	}
+	return events
+}
+
+func TestCountStreamHandler(t *testing.T) {
+	pages := map[string]string{
+		"/one": "<html><body><p>firefly glow night</p></body></html>",
+		"/two": "<html><body><p>firefly glow firefly</p></body></html>",
+	}
+	articleSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(pages[r.URL.Path]))
+	}))
+	defer articleSrv.Close()
+
+	bank := map[string]struct{}{"firefly": {}, "glow": {}, "night": {}}
+	source := articles.NewSource(articles.SourceConfig{Logger: logging.Nop()})
+	handler := NewCountStreamHandler(source, wordbank.NewValidator(wordbank.NewBank(bank)), logging.Nop(), processing.WithSnapshotInterval(1))
+
+	query := url.Values{"url": {articleSrv.URL + "/one", articleSrv.URL + "/two"}, "topN": {"2"}}
+	req := httptest.NewRequest(http.MethodGet, "/count/stream?"+query.Encode(), nil)
+	rr := httptest.NewRecorder()
+
+	handler.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	if ct := rr.Header().Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("expected event stream content type, got %q", ct)
+	}
+
+	events := readEvents(t, rr.Body.String())
+	if len(events) < 2 {
+		t.Fatalf("expected progress and done events, got %v", events)
+	}
+	for _, event := range events[:len(events)-1] {
+		if event.name != "" {
+			t.Fatalf("expected unnamed progress event, got %q", event.name)
+		}
+	}
+
+	done := events[len(events)-1]
+	if done.name != "done" {
+		t.Fatalf("expected final done event, got %q", done.name)
+	}
+	var counts map[string]int
+	if err := json.Unmarshal([]byte(done.data), &counts); err != nil {
+		t.Fatalf("failed to unmarshal done event: %v", err)
+	}
+	if counts["firefly"] != 3 || counts["glow"] != 2 || len(counts) != 2 {
+		t.Fatalf("expected map[firefly:3 glow:2], got %v", counts)
+	}
+}
+
+func TestCountStreamHandlerRejectsLargeBodies(t *testing.T) {
+	body := `{"urls": ["` + strings.Repeat("a", maxRequestBodyBytes) + `"]}`
+	req := httptest.NewRequest(http.MethodPost, "/count/stream", strings.NewReader(body))
+	rr := httptest.NewRecorder()
+
+	NewCountStreamHandler(nil, nil, logging.Nop()).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected status %d, got %d", http.StatusRequestEntityTooLarge, rr.Code)
+	}
+}
+
+func TestCountStreamHandlerRequiresURLs(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/count/stream", nil)
+	rr := httptest.NewRecorder()
+
+	NewCountStreamHandler(nil, nil, logging.Nop()).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected status %d, got %d", http.StatusBadRequest, rr.Code)
+	}
+}
@@ -0,0 +1,44 @@
+package logging
+
+import "context"
+
+// RequestIDHeader carries a request ID into the service and on to the article
+// fetches made on its behalf.
+const RequestIDHeader = "X-Request-Id"
+
+type requestIDKey struct{}
+
+// WithRequestID returns a copy of ctx carrying the request ID id.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID returns the request ID carried by ctx, or "" if there is none.
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// FromContext returns l, adding the request ID carried by ctx to every record
+// as "request_id". l is returned as is when ctx carries no ID.
+func FromContext(ctx context.Context, l Logger) Logger {
+	id := RequestID(ctx)
+	if id == "" {
+		return l
+	}
+	return requestLogger{Logger: l, id: id}
+}
+
+type requestLogger struct {
+	Logger
+	id string
+}
+
+func (l requestLogger) Debug(msg string, kv ...any) { l.Logger.Debug(msg, l.with(kv)...) }
+func (l requestLogger) Info(msg string, kv ...any)  { l.Logger.Info(msg, l.with(kv)...) }
+func (l requestLogger) Warn(msg string, kv ...any)  { l.Logger.Warn(msg, l.with(kv)...) }
+func (l requestLogger) Error(msg string, kv ...any) { l.Logger.Error(msg, l.with(kv)...) }
+
+func (l requestLogger) with(kv []any) []any {
+	return append([]any{"request_id", l.id}, kv...)
+}
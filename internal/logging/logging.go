@@ -0,0 +1,26 @@
+package logging
+
+import (
+	"log/slog"
+	"os"
+)
+
+// Logger emits structured log records as a message followed by alternating
+// key/value pairs. *slog.Logger satisfies it, as does retryablehttp's
+// LeveledLogger contract.
+type Logger interface {
+	Debug(msg string, kv ...any)
+	Info(msg string, kv ...any)
+	Warn(msg string, kv ...any)
+	Error(msg string, kv ...any)
+}
+
+// Default returns a slog-backed logger writing JSON records to stderr.
+func Default() Logger {
+	return slog.New(slog.NewJSONHandler(os.Stderr, nil))
+}
+
+// Nop returns a logger that discards everything, useful for silencing tests.
+func Nop() Logger {
+	return slog.New(slog.DiscardHandler)
+}
@@ -0,0 +1,121 @@
+package metrics
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Metrics groups the Prometheus collectors describing fetch outcomes. A nil
+// *Metrics is valid and records nothing, so instrumentation stays optional.
+type Metrics struct {
+	articlesFetched prometheus.Counter
+	articlesFailed  prometheus.Counter
+	retries         prometheus.Counter
+	mergeStalls     prometheus.Counter
+	fetchLatency    *prometheus.HistogramVec
+	concurrency     *prometheus.GaugeVec
+}
+
+// New constructs the collectors. They must be registered via Register before
+// they are exposed.
+func New() *Metrics {
+	return &Metrics{
+		articlesFetched: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "firefly",
+			Name:      "articles_fetched_total",
+			Help:      "Number of articles fetched and processed successfully.",
+		}),
+		articlesFailed: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "firefly",
+			Name:      "articles_failed_total",
+			Help:      "Number of articles that could not be fetched.",
+		}),
+		retries: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "firefly",
+			Name:      "fetch_retries_total",
+			Help:      "Number of HTTP retries triggered while fetching articles.",
+		}),
+		mergeStalls: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "firefly",
+			Name:      "merge_stalls_total",
+			Help:      "Number of articles whose counts waited for room in the full merge channel.",
+		}),
+		fetchLatency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "firefly",
+			Name:      "fetch_duration_seconds",
+			Help:      "Latency of article fetches, including retries.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"domain", "status"}),
+		concurrency: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "firefly",
+			Name:      "domain_concurrency",
+			Help:      "Concurrent requests currently allowed per domain by adaptive concurrency.",
+		}, []string{"domain"}),
+	}
+}
+
+// Register adds all collectors to reg.
+func (m *Metrics) Register(reg prometheus.Registerer) error {
+	for _, c := range []prometheus.Collector{m.articlesFetched, m.articlesFailed, m.retries, m.mergeStalls, m.fetchLatency, m.concurrency} {
+		if err := reg.Register(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ArticleFetched records a successfully processed article.
+func (m *Metrics) ArticleFetched() {
+	if m == nil {
+		return
+	}
+	m.articlesFetched.Inc()
+}
+
+// ArticleFailed records an article that could not be processed.
+func (m *Metrics) ArticleFailed() {
+	if m == nil {
+		return
+	}
+	m.articlesFailed.Inc()
+}
+
+// RetryTriggered records a single HTTP retry.
+func (m *Metrics) RetryTriggered() {
+	if m == nil {
+		return
+	}
+	m.retries.Inc()
+}
+
+// MergeStalled records an article whose counts waited for the merge channel.
+func (m *Metrics) MergeStalled() {
+	if m == nil {
+		return
+	}
+	m.mergeStalls.Inc()
+}
+
+// ObserveFetch records the latency of a fetch against domain. A status of 0
+// means no response was received and is reported as "error".
+func (m *Metrics) ObserveFetch(domain string, status int, elapsed time.Duration) {
+	if m == nil {
+		return
+	}
+	label := "error"
+	if status > 0 {
+		label = strconv.Itoa(status)
+	}
+	m.fetchLatency.WithLabelValues(domain, label).Observe(elapsed.Seconds())
+}
+
+// SetDomainConcurrency records the concurrency adaptive limiting currently
+// allows domain.
+func (m *Metrics) SetDomainConcurrency(domain string, limit int) {
+	if m == nil {
+		return
+	}
+	m.concurrency.WithLabelValues(domain).Set(float64(limit))
+}
@@ -0,0 +1,110 @@
+package processing
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// checkpoint is the on-disk state of a partially completed run.
+type checkpoint struct {
+	// Processed lists every successfully counted URL, repeated if the URL
+	// appeared more than once. Failed URLs are omitted so a resumed run
+	// retries them.
+	Processed []string       `json:"processed"`
+	Counts    map[string]int `json:"counts"`
+}
+
+// WithCheckpoint makes the counter save its progress to path after every
+// everyN counted articles and once more when the run ends, so that an
+// interrupted run can be continued with ResumeFrom. Writes replace the file
+// atomically. Values of everyN below 1 save after every article.
+func WithCheckpoint(path string, everyN int) Option {
+	return func(c *Counter) {
+		if everyN < 1 {
+			everyN = 1
+		}
+		c.checkpointPath = path
+		c.checkpointEvery = everyN
+	}
+}
+
+// ResumeFrom loads a checkpoint written by WithCheckpoint and returns an
+// option that seeds the counter with its counts and skips the URLs it already
+// processed, so the final result matches an uninterrupted run over the same
+// list. A missing file yields an option that starts from scratch.
+func ResumeFrom(path string) (Option, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return func(*Counter) {}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read checkpoint: %w", err)
+	}
+
+	var cp checkpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return nil, fmt.Errorf("parse checkpoint %s: %w", path, err)
+	}
+
+	return func(c *Counter) {
+		c.resume = &cp
+	}, nil
+}
+
+// checkpointWriter accumulates progress in the merge goroutine and persists
+// it periodically.
+type checkpointWriter struct {
+	path       string
+	everyN     int
+	processed  []string
+	sinceWrite int
+}
+
+// record notes that url has been merged into counts and saves a checkpoint
+// when one is due.
+func (w *checkpointWriter) record(url string, counts map[string]int) error {
+	w.processed = append(w.processed, url)
+	w.sinceWrite++
+	if w.sinceWrite < w.everyN {
+		return nil
+	}
+	return w.write(counts)
+}
+
+// write saves the current progress, replacing any previous checkpoint.
+func (w *checkpointWriter) write(counts map[string]int) error {
+	w.sinceWrite = 0
+
+	data, err := json.Marshal(checkpoint{Processed: w.processed, Counts: counts})
+	if err != nil {
+		return fmt.Errorf("encode checkpoint: %w", err)
+	}
+
+	// Write to a temporary file in the same directory and rename it into
+	// place, so a crash mid-write never leaves a truncated checkpoint.
+	tmp, err := os.CreateTemp(filepath.Dir(w.path), filepath.Base(w.path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("create checkpoint: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write checkpoint: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("sync checkpoint: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("close checkpoint: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), w.path); err != nil {
+		return fmt.Errorf("replace checkpoint: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package processing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync/atomic"
+	"testing"
+)
+
+// stoppingFetcher cancels the run once it has served limit articles,
+// simulating a crash partway through the list.
+type stoppingFetcher struct {
+	staticFetcher
+	limit  int32
+	served *int32
+	cancel context.CancelFunc
+}
+
+func (f stoppingFetcher) Fetch(ctx context.Context, url string) (string, error) {
+	if atomic.AddInt32(f.served, 1) > f.limit {
+		f.cancel()
+		return "", ctx.Err()
+	}
+	return f.staticFetcher.Fetch(ctx, url)
+}
+
+func TestCheckpointResumeMatchesUninterruptedRun(t *testing.T) {
+	fetcher := staticFetcher{
+		"a": "apple banana",
+		"b": "banana cherry",
+		"c": "nothing valid",
+		"d": "apple apple",
+		"e": "cherry date",
+	}
+	validator := newSetValidator("apple", "banana", "cherry", "date")
+	urls := []string{"a", "b", "c", "a", "d", "missing", "e"}
+
+	want, err := newTestCounter(fetcher, validator).CountAllWords(context.Background(), urlChan(urls...))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "progress.json")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var served int32
+	interrupted := stoppingFetcher{staticFetcher: fetcher, limit: 4, served: &served, cancel: cancel}
+	partial, err := newTestCounter(interrupted, validator, WithWorkerCount(1), WithCheckpoint(path, 2)).
+		CountAllWords(ctx, urlChan(urls...))
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if reflect.DeepEqual(partial, want) {
+		t.Fatal("expected the interrupted run to be incomplete")
+	}
+
+	var saved checkpoint
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read checkpoint: %v", err)
+	}
+	if err := json.Unmarshal(data, &saved); err != nil {
+		t.Fatalf("parse checkpoint: %v", err)
+	}
+	if !reflect.DeepEqual(saved.Processed, []string{"a", "b", "c", "a"}) {
+		t.Fatalf("expected first four URLs to be processed, got %v", saved.Processed)
+	}
+
+	resume, err := ResumeFrom(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got, err := newTestCounter(fetcher, validator, resume).CountAllWords(context.Background(), urlChan(urls...))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected resumed counts %v, got %v", want, got)
+	}
+
+	matches, _ := filepath.Glob(path + ".tmp-*")
+	if len(matches) != 0 {
+		t.Fatalf("expected temporary checkpoint files to be cleaned up, got %v", matches)
+	}
+}
+
+func TestResumeFromMissingFileStartsFresh(t *testing.T) {
+	resume, err := ResumeFrom(filepath.Join(t.TempDir(), "absent.json"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	counts, err := newTestCounter(staticFetcher{"a": "apple"}, newSetValidator("apple"), resume).
+		CountAllWords(context.Background(), urlChan("a"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if counts["apple"] != 1 {
+		t.Fatalf("expected apple=1, got %v", counts)
+	}
+}
+
+func TestResumeFromMalformedFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "progress.json")
+	if err := os.WriteFile(path, []byte("{"), 0o644); err != nil {
+		t.Fatalf("write checkpoint: %v", err)
+	}
+
+	if _, err := ResumeFrom(path); err == nil {
+		t.Fatal("expected error for malformed checkpoint")
+	}
+}
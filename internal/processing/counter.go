@@ -2,12 +2,17 @@ package processing
 
 import (
 	"context"
-	"log"
+	"errors"
 	"regexp"
 	"runtime"
 	"sort"
+	"strings"
 	"sync"
 	"sync/atomic"
+	"time"
+
+	"github.com/shoresh319/firefly/internal/logging"
+	"github.com/shoresh319/firefly/internal/metrics"
 )
 
 // ArticleFetcher returns the textual content for a given article URL.
@@ -15,17 +20,73 @@ type ArticleFetcher interface {
 	Fetch(ctx context.Context, url string) (string, error)
 }
 
+// ErrSkipped may be wrapped by ArticleFetcher errors for URLs deliberately not
+// fetched, such as ones a site's robots.txt disallows. Such articles are
+// counted in Stats.Skipped rather than as failures.
+var ErrSkipped = errors.New("article skipped")
+
+// errArticleLimit is the cancellation cause of a run that reached its
+// WithMaxArticles limit.
+var errArticleLimit = errors.New("article limit reached")
+
 // WordValidator determines if a token should be counted.
 type WordValidator interface {
 	Validate(word string) bool
 }
 
+// WordWeigher may be implemented by a WordValidator whose words are worth more
+// than one occurrence each. Weight is only called for words that passed
+// Validate, and each occurrence of the word adds its weight to the word's count
+// and to Stats.TotalTokens. Weights apply to single words, not to n-grams.
+type WordWeigher interface {
+	Weight(word string) int
+}
+
 // Counter orchestrates concurrent word counting for a series of articles.
 type Counter struct {
 	fetcher   ArticleFetcher
 	validator WordValidator
-	wordRegex *regexp.Regexp
+	wordRegex *regexp.Regexp // nil until NewCounter picks the default
 	workers   int
+	logger    logging.Logger
+	metrics   *metrics.Metrics
+	ngramSize int
+	// articleTimeout bounds each Fetch call; zero leaves only the caller's deadline.
+	articleTimeout   time.Duration
+	maxRuntime       time.Duration
+	maxArticles      int
+	snapshotInterval int
+	apostrophes      bool
+	hyphens          bool
+	lowercase        bool
+	trimPunctuation  bool
+	numericPolicy    NumericPolicy
+	minCount         int
+	approxTopK       int
+	progress         func(done, total int)
+	progressTotal    int
+	checkpointPath   string
+	checkpointEvery  int
+	resume           *checkpoint
+	// channelMerge forces every article through the merge goroutine even when
+	// sharded accumulation would do; benchmarks use it for comparison.
+	channelMerge bool
+	// deterministic pins the run to one worker; see WithDeterministic.
+	deterministic bool
+	// weigher is the validator when it implements WordWeigher.
+	weigher WordWeigher
+	// resultBuffer is the capacity of the merge channel; zero picks workers*2.
+	resultBuffer int
+	// spillDir enables spilling; see WithSpillDir.
+	spillDir       string
+	spillThreshold int
+	// stemLang is the WithStemming language; stem is its stemmer, if known.
+	stemLang string
+	stem     func(string) string
+	// segmentWords selects UAX #29 segmentation; see WithWordSegmentation.
+	segmentWords bool
+	// rejectedTopN enables Stats.Rejected; see WithRejectedTopN.
+	rejectedTopN int
 }
 
 // Option configures a Counter.
@@ -40,7 +101,18 @@ func WithWorkerCount(workers int) Option {
 	}
 }
 
-// WithWordRegex overrides the default token extraction expression.
+// WithDeterministic runs a single worker that fetches and merges articles one
+// at a time, in the order the URLs arrive, so logs and intermediate totals
+// repeat exactly from run to run. It overrides WithWorkerCount and is meant
+// for debugging, not for production runs.
+func WithDeterministic(enabled bool) Option {
+	return func(c *Counter) {
+		c.deterministic = enabled
+	}
+}
+
+// WithWordRegex overrides the default token extraction expression. It takes
+// precedence over WithApostrophes and WithHyphens.
 func WithWordRegex(expr *regexp.Regexp) Option {
 	return func(c *Counter) {
 		if expr != nil {
@@ -49,28 +121,337 @@ func WithWordRegex(expr *regexp.Regexp) Option {
 	}
 }
 
-// NewCounter constructs a Counter with optional configuration.
+// WithApostrophes keeps intra-word apostrophes, straight or curly, in tokens
+// so contractions such as "don't" are counted as one word.
+func WithApostrophes(enabled bool) Option {
+	return func(c *Counter) {
+		c.apostrophes = enabled
+	}
+}
+
+// WithHyphens keeps intra-word hyphens in tokens so compounds such as
+// "well-known" are counted as one word.
+func WithHyphens(enabled bool) Option {
+	return func(c *Counter) {
+		c.hyphens = enabled
+	}
+}
+
+// WithLowercaseTokens lowercases tokens after validation so "Apple" and
+// "apple" merge into one count. The validator still sees the original token,
+// so a case-sensitive validator must accept each spelling for it to count;
+// pair this with wordbank.WithCaseInsensitive to fold case end to end.
+func WithLowercaseTokens(enabled bool) Option {
+	return func(c *Counter) {
+		c.lowercase = enabled
+	}
+}
+
+// WithTrimPunctuation strips leading and trailing runes that are not letters,
+// marks, digits or underscores from each token before validation, so "word.",
+// "(word)" and “word” count as "word". Joiners inside a token, such as the
+// apostrophe kept by WithApostrophes, are left alone. It matters mostly with a
+// WithWordRegex that splits on whitespace; the default pattern never includes
+// surrounding punctuation.
+func WithTrimPunctuation(enabled bool) Option {
+	return func(c *Counter) {
+		c.trimPunctuation = enabled
+	}
+}
+
+// WithNumericPolicy decides whether tokens containing digits can count as
+// words. Tokens the policy rejects are dropped before validation and, like
+// invalid tokens, break n-gram sequences. The default is NumericInclude.
+func WithNumericPolicy(policy NumericPolicy) Option {
+	return func(c *Counter) {
+		c.numericPolicy = policy
+	}
+}
+
+// WithNGramSize counts sequences of n consecutive valid words, joined by a
+// single space, instead of individual words. Words separated by an invalid
+// token are not considered consecutive. Values below 2 keep single-word counting.
+func WithNGramSize(n int) Option {
+	return func(c *Counter) {
+		if n > 0 {
+			c.ngramSize = n
+		}
+	}
+}
+
+// WithApproxTopK bounds memory on very large corpora by replacing the exact
+// frequency map with a count-min sketch plus a heap of the k most frequent
+// words seen so far. Memory then stays constant regardless of how many
+// distinct words occur, at the cost of accuracy: counts are estimates that
+// may exceed, but never undercount, the true frequency, and a word whose
+// frequency is close to the k-th most frequent may be swapped with it.
+// CountAllWords, snapshots and checkpoints only report the k tracked words,
+// and Stats.DistinctWords is at most k. Keep k comfortably above the topN
+// requested.
+func WithApproxTopK(k int) Option {
+	return func(c *Counter) {
+		if k > 0 {
+			c.approxTopK = k
+		}
+	}
+}
+
+// WithMinCount drops words seen fewer than n times from the final counts and
+// from stream snapshots, before the top-N selection. A topN larger than the
+// number of words meeting the threshold therefore returns fewer than topN
+// entries. Checkpoints keep every word so a resumed run can still reach the
+// threshold. Values below 2 keep every word.
+func WithMinCount(n int) Option {
+	return func(c *Counter) {
+		if n > 0 {
+			c.minCount = n
+		}
+	}
+}
+
+// WithPerArticleTimeout bounds how long a single article fetch may take. An
+// article that times out counts as a failure and the worker moves on.
+func WithPerArticleTimeout(d time.Duration) Option {
+	return func(c *Counter) {
+		if d > 0 {
+			c.articleTimeout = d
+		}
+	}
+}
+
+// WithMaxRuntime sets a soft budget for a run. Once d has elapsed, workers
+// stop taking URLs from the channel, articles already being fetched finish and
+// are counted, and the run returns the counts gathered so far without error.
+// Unlike cancelling the context, nothing in flight is discarded;
+// Stats.RuntimeExceeded tells the two apart.
+func WithMaxRuntime(d time.Duration) Option {
+	return func(c *Counter) {
+		if d > 0 {
+			c.maxRuntime = d
+		}
+	}
+}
+
+// WithMaxArticles stops a run once n articles have been fetched and counted.
+// Workers stop taking URLs, fetches in flight are cancelled and discarded, and
+// the run returns the counts of exactly n articles without error. Failed and
+// skipped articles do not count toward n; Stats.ArticleLimitReached reports
+// that the limit cut the run short.
+func WithMaxArticles(n int) Option {
+	return func(c *Counter) {
+		if n > 0 {
+			c.maxArticles = n
+		}
+	}
+}
+
+// WithResultBuffer sets the capacity of the channel that carries each
+// article's counts to the merge goroutine (default: twice the worker count).
+// A larger buffer lets workers run ahead of a slow merge, such as one writing
+// checkpoints, at the cost of holding more per-article maps in memory.
+// Stats.MergeStalls reports how often workers found it full. The channel is
+// only used when totals are checkpointed, streamed, resumed or approximate.
+func WithResultBuffer(n int) Option {
+	return func(c *Counter) {
+		if n > 0 {
+			c.resultBuffer = n
+		}
+	}
+}
+
+// WithSnapshotInterval sets how many merged articles CountTopWordsStream waits
+// for between snapshots (default: 50).
+func WithSnapshotInterval(n int) Option {
+	return func(c *Counter) {
+		if n > 0 {
+			c.snapshotInterval = n
+		}
+	}
+}
+
+// WithRejectedTopN tracks the tokens the validator rejects, such as words
+// missing from the word bank, and reports the n most frequent of them in
+// Stats.Rejected to reveal gaps in the vocabulary. Tokens dropped by the
+// numeric policy are not included, and only articles whose counts were merged
+// contribute. Every distinct rejected token is held in memory until the run
+// ends. Values below 1 disable tracking.
+func WithRejectedTopN(n int) Option {
+	return func(c *Counter) {
+		c.rejectedTopN = max(n, 0)
+	}
+}
+
+// WithLogger overrides the default structured logger.
+func WithLogger(logger logging.Logger) Option {
+	return func(c *Counter) {
+		if logger != nil {
+			c.logger = logger
+		}
+	}
+}
+
+// WithMetrics records article outcomes in the supplied collectors.
+func WithMetrics(m *metrics.Metrics) Option {
+	return func(c *Counter) {
+		c.metrics = m
+	}
+}
+
+// NewCounter constructs a Counter with optional configuration. By default a
+// token is a run of Unicode letters, marks, digits and underscores.
 func NewCounter(fetcher ArticleFetcher, validator WordValidator, opts ...Option) *Counter {
 	counter := &Counter{
-		fetcher:   fetcher,
-		validator: validator,
-		wordRegex: regexp.MustCompile(`\w+`),
-		workers:   runtime.NumCPU(),
+		fetcher:          fetcher,
+		validator:        validator,
+		workers:          runtime.NumCPU(),
+		logger:           logging.Default(),
+		ngramSize:        1,
+		snapshotInterval: defaultSnapshotInterval,
+		spillThreshold:   defaultSpillThreshold,
 	}
 
 	for _, opt := range opts {
 		opt(counter)
 	}
 
+	counter.weigher, _ = validator.(WordWeigher)
+	if counter.wordRegex == nil {
+		counter.wordRegex = wordPattern(counter.apostrophes, counter.hyphens)
+	}
+	if counter.deterministic {
+		counter.workers = 1
+	}
+	if counter.stemLang != "" {
+		counter.stem = stemmers[counter.stemLang]
+		if counter.stem == nil {
+			counter.logger.Warn("no stemmer for language, counting words unstemmed", "language", counter.stemLang)
+		}
+	}
+
 	return counter
 }
 
 // CountTopWords loads articles from the provided URL channel and returns a map
-// containing the topN tokens by frequency.
+// containing the topN tokens by frequency. When ctx is cancelled or its
+// deadline passes before the run completes, the top words among the articles
+// merged so far are returned together with ctx.Err(); the map is usable, but
+// covers only part of the input.
 func (c *Counter) CountTopWords(ctx context.Context, urlCh <-chan string, topN int) (map[string]int, error) {
-	countsCh := make(chan map[string]int, c.workers*2)
+	globalCounts, _ := c.count(ctx, urlCh, topN, nil)
+
+	topCounts := pickTop(globalCounts, topN)
+	c.logger.Info("kept top words", "top_n", topN, "distinct", len(topCounts))
+
+	return topCounts, ctx.Err()
+}
+
+// CountAllWords loads articles from the provided URL channel and returns the
+// complete frequency map of every valid token. Like CountTopWords, a run cut
+// short by ctx returns the partial counts along with ctx.Err().
+func (c *Counter) CountAllWords(ctx context.Context, urlCh <-chan string) (map[string]int, error) {
+	globalCounts, _ := c.count(ctx, urlCh, 0, nil)
+	return globalCounts, ctx.Err()
+}
+
+// count runs the worker pool over urlCh and merges the results. When onMerge is
+// non-nil it is invoked from the merge goroutine after each article's counts
+// are merged, with the running totals and the number of merged articles; it
+// must not retain or modify the map. A positive topN lets a spilled run return
+// only the words the caller will keep.
+func (c *Counter) count(ctx context.Context, urlCh <-chan string, topN int, onMerge func(counts map[string]int, merged int)) (map[string]int, Stats) {
+	totals := c.newTally()
+	var totalTokens int
+	var processed []string
+	if c.resume != nil {
+		for token, count := range c.resume.Counts {
+			totals.add(token, count)
+			totalTokens += count
+		}
+		processed = append(processed, c.resume.Processed...)
+		urlCh = skipProcessed(ctx, urlCh, c.resume.Processed)
+	}
+
+	var checkpoints *checkpointWriter
+	if c.checkpointPath != "" {
+		checkpoints = &checkpointWriter{path: c.checkpointPath, everyN: c.checkpointEvery, processed: processed}
+	}
+
+	// Runs that need no per-article view of the totals let workers merge into
+	// sharded maps directly rather than queueing behind the merge goroutine.
+	var shards *shardedCounts
+	if onMerge == nil && checkpoints == nil && c.resume == nil && c.approxTopK == 0 && !c.channelMerge {
+		shards = newShardedCounts()
+	}
+	// With a spill directory such runs instead merge through a tally that
+	// moves its counts to disk as they grow.
+	var spill *spillTally
+	if shards != nil && c.spillDir != "" {
+		var err error
+		if spill, err = newSpillTally(c.spillDir, c.spillThreshold); err != nil {
+			c.logger.Error("failed to set up spilling, counting in memory", "dir", c.spillDir, "error", err)
+		} else {
+			defer spill.close()
+			shards, totals = nil, spill
+		}
+	}
+
+	// runCtx additionally ends once the WithMaxArticles limit is reached. Only
+	// workers and fetches use it: merges of articles that claimed a slot under
+	// the limit must still go through.
+	runCtx, stopRun := context.WithCancelCause(ctx)
+	defer stopRun(nil)
+	var claimed atomic.Int64
+	var limitReached atomic.Bool
+
+	countsCh := make(chan articleCounts, c.resultBufferSize())
+	var sends, stalls atomic.Int64
+	merge := func(result articleCounts) bool {
+		if c.maxArticles > 0 {
+			n := claimed.Add(1)
+			if n > int64(c.maxArticles) {
+				return false
+			}
+			if n == int64(c.maxArticles) {
+				limitReached.Store(true)
+				defer stopRun(errArticleLimit)
+			}
+		}
+		if shards != nil {
+			shards.add(result.counts)
+			return true
+		}
+		sends.Add(1)
+		select {
+		case countsCh <- result:
+			return true
+		default:
+		}
+		// The merge goroutine is behind; wait for it.
+		stalls.Add(1)
+		c.metrics.MergeStalled()
+		select {
+		case <-ctx.Done():
+			return false
+		case countsCh <- result:
+			return true
+		}
+	}
 	var wg sync.WaitGroup
-	var successes, failures int64
+	var successes, failures, skipped int64
+	domains := newDomainTracker()
+	rejected := newRejectedTracker(c.rejectedTopN)
+	progress := &progressReporter{fn: c.progress, total: c.progressTotal}
+
+	// budgetSpent is closed once the WithMaxRuntime budget runs out.
+	budgetSpent := make(chan struct{})
+	var overBudget atomic.Bool
+	var budget *time.Timer
+	if c.maxRuntime > 0 {
+		budget = time.AfterFunc(c.maxRuntime, func() {
+			overBudget.Store(true)
+			close(budgetSpent)
+		})
+	}
 
 	for i := 0; i < c.workers; i++ {
 		wg.Add(1)
@@ -78,70 +459,355 @@ func (c *Counter) CountTopWords(ctx context.Context, urlCh <-chan string, topN i
 			defer wg.Done()
 			for {
 				select {
-				case <-ctx.Done():
+				case <-runCtx.Done():
+					return
+				case <-budgetSpent:
 					return
 				case url, ok := <-urlCh:
-					if !ok {
+					if !ok || runCtx.Err() != nil || overBudget.Load() {
 						return
 					}
-					if c.processURL(ctx, url, countsCh) {
+					outcome, words := c.processURL(runCtx, url, merge, rejected)
+					switch outcome {
+					case articleSucceeded:
 						atomic.AddInt64(&successes, 1)
-					} else {
+						c.metrics.ArticleFetched()
+					case articleFailed:
 						atomic.AddInt64(&failures, 1)
+						c.metrics.ArticleFailed()
+					case articleSkipped:
+						atomic.AddInt64(&skipped, 1)
+					case articleCancelled:
+						return
 					}
+					domains.record(url, outcome, words)
+					progress.articleDone()
 				}
 			}
 		}()
 	}
 
-	globalCounts := make(map[string]int)
 	doneMerge := make(chan struct{})
 	go func() {
-		for partial := range countsCh {
-			for token, count := range partial {
-				globalCounts[token] += count
+		defer close(doneMerge)
+		merged := 0
+		for result := range countsCh {
+			for token, count := range result.counts {
+				totals.add(token, count)
+				totalTokens += count
+			}
+			if checkpoints != nil {
+				if err := checkpoints.record(result.url, totals.counts()); err != nil {
+					c.logger.Error("failed to write checkpoint", "path", c.checkpointPath, "error", err)
+				}
+			}
+			if len(result.counts) == 0 {
+				continue
+			}
+			merged++
+			if onMerge != nil {
+				onMerge(c.dropRare(totals.counts()), merged)
+			}
+		}
+		if checkpoints != nil {
+			if err := checkpoints.write(totals.counts()); err != nil {
+				c.logger.Error("failed to write checkpoint", "path", c.checkpointPath, "error", err)
 			}
 		}
-		close(doneMerge)
 	}()
 
 	wg.Wait()
+	if budget != nil {
+		budget.Stop()
+	}
 	close(countsCh)
 	<-doneMerge
+	if shards != nil {
+		merged := exactTally(shards.merge())
+		for _, n := range merged {
+			totalTokens += n
+		}
+		totals = merged
+	}
 
-	log.Printf("processed articles: %d successes, %d failures", atomic.LoadInt64(&successes), atomic.LoadInt64(&failures))
-	log.Printf("counted %d distinct valid words", len(globalCounts))
+	var globalCounts map[string]int
+	var distinct int
+	if spill != nil && topN > 0 {
+		globalCounts, distinct = spill.top(topN, c.minCount)
+	} else {
+		globalCounts = c.dropRare(totals.counts())
+		distinct = len(globalCounts)
+	}
+	if spill != nil && spill.err != nil {
+		c.logger.Error("spilling counts failed", "dir", c.spillDir, "runs", len(spill.runs), "error", spill.err)
+	}
+	stats := Stats{
+		Successes:     int(atomic.LoadInt64(&successes)),
+		Failures:      int(atomic.LoadInt64(&failures)),
+		Skipped:       int(atomic.LoadInt64(&skipped)),
+		DistinctWords: distinct,
+		TotalTokens:   totalTokens,
+		Domains:       domains.stats,
+	}
+	stats.ArticlesAttempted = stats.Successes + stats.Failures
+	stats.RuntimeExceeded = overBudget.Load()
+	if stats.RuntimeExceeded {
+		c.logger.Warn("runtime budget reached, returning partial results", "max_runtime", c.maxRuntime)
+	}
+	stats.ArticleLimitReached = limitReached.Load()
+	if stats.ArticleLimitReached {
+		c.logger.Info("article limit reached, returning partial results", "max_articles", c.maxArticles)
+	}
+	stats.MergeStalls = int(stalls.Load())
+	if rejected != nil {
+		stats.Rejected = pickTop(rejected.counts, c.rejectedTopN)
+		c.logger.Info("most frequent rejected tokens", "rejected", stats.Rejected)
+	}
+	if n := sends.Load(); n >= minBackpressureSample && stalls.Load()*2 > n {
+		c.logger.Warn("results channel frequently full, merge is a bottleneck", "stalls", stats.MergeStalls, "articles", n, "result_buffer", cap(countsCh))
+	}
 
-	topCounts := pickTop(globalCounts, topN)
-	log.Printf("kept top %d words (distinct=%d)", topN, len(topCounts))
+	c.logger.Info("processed articles", "successes", stats.Successes, "failures", stats.Failures, "skipped", stats.Skipped)
+	c.logger.Info("counted distinct valid words", "distinct", stats.DistinctWords)
 
-	return topCounts, nil
+	return globalCounts, stats
 }
 
-func (c *Counter) processURL(ctx context.Context, url string, countsCh chan<- map[string]int) bool {
-	text, err := c.fetcher.Fetch(ctx, url)
+// minBackpressureSample is how many articles must go through the merge channel
+// before frequent stalls are reported.
+const minBackpressureSample = 20
+
+// resultBufferSize is the capacity of the merge channel.
+func (c *Counter) resultBufferSize() int {
+	if c.resultBuffer > 0 {
+		return c.resultBuffer
+	}
+	return c.workers * 2
+}
+
+// dropRare returns the words in counts that meet the WithMinCount threshold.
+// counts itself is left intact since it may be the live tally.
+func (c *Counter) dropRare(counts map[string]int) map[string]int {
+	if c.minCount <= 1 {
+		return counts
+	}
+	kept := make(map[string]int)
+	for word, n := range counts {
+		if n >= c.minCount {
+			kept[word] = n
+		}
+	}
+	return kept
+}
+
+// newTally returns the accumulator selected by the counter's options.
+func (c *Counter) newTally() tally {
+	if c.approxTopK > 0 {
+		return newApproxTally(c.approxTopK)
+	}
+	return exactTally{}
+}
+
+// articleOutcome reports what became of a single article.
+type articleOutcome int
+
+const (
+	articleSucceeded articleOutcome = iota
+	articleFailed
+	// articleSkipped means the fetcher declined the URL with ErrSkipped.
+	articleSkipped
+	// articleCancelled means the run was cancelled before the article's counts
+	// were handed to the merge goroutine, so they were discarded.
+	articleCancelled
+)
+
+// articleCounts carries one article's token counts to the merge goroutine.
+type articleCounts struct {
+	url    string
+	counts map[string]int
+}
+
+// processURL fetches and tokenizes one article, handing its counts to merge,
+// which returns false if the run was cancelled first or the WithMaxArticles
+// limit is already full. It also reports how many
+// valid tokens the article held. Rejected tokens of merged articles are
+// recorded in rejects, if non-nil.
+func (c *Counter) processURL(ctx context.Context, url string, merge func(articleCounts) bool, rejects *rejectedTracker) (articleOutcome, int) {
+	fetchCtx := ctx
+	if c.articleTimeout > 0 {
+		var cancel context.CancelFunc
+		fetchCtx, cancel = context.WithTimeout(ctx, c.articleTimeout)
+		defer cancel()
+	}
+
+	text, err := c.fetcher.Fetch(fetchCtx, url)
+	if err != nil && errors.Is(context.Cause(ctx), errArticleLimit) {
+		return articleCancelled, 0
+	}
+	if errors.Is(err, ErrSkipped) {
+		c.logger.Info("skipped article", "url", url, "reason", err)
+		return articleSkipped, 0
+	}
 	if err != nil {
-		log.Printf("failed to load article %s: %v", url, err)
-		return false
+		c.logger.Error("failed to load article", "url", url, "error", err)
+		return articleFailed, 0
+	}
+
+	var rejected map[string]int
+	if rejects != nil {
+		rejected = make(map[string]int)
+	}
+	local := c.countTokens(text, rejected)
+	var words int
+	for _, n := range local {
+		words += n
+	}
+
+	// Check first: a cancelled run must not merge anything further, and merge
+	// may pick randomly between sending and noticing the cancellation.
+	if ctx.Err() != nil {
+		return articleCancelled, 0
 	}
 
+	// Articles without valid words only need merging when checkpoints must
+	// record them as processed, or when they count toward WithMaxArticles.
+	if len(local) == 0 && c.checkpointPath == "" && c.maxArticles == 0 {
+		rejects.add(rejected)
+		return articleSucceeded, 0
+	}
+
+	if !merge(articleCounts{url: url, counts: local}) {
+		return articleCancelled, 0
+	}
+	rejects.add(rejected)
+	return articleSucceeded, words
+}
+
+// skipProcessed forwards urlCh, dropping one occurrence of each URL in
+// processed for every time it appears there.
+func skipProcessed(ctx context.Context, urlCh <-chan string, processed []string) <-chan string {
+	remaining := make(map[string]int, len(processed))
+	for _, url := range processed {
+		remaining[url]++
+	}
+
+	out := make(chan string)
+	go func() {
+		defer close(out)
+		for {
+			var url string
+			select {
+			case <-ctx.Done():
+				return
+			case next, ok := <-urlCh:
+				if !ok {
+					return
+				}
+				url = next
+			}
+			if remaining[url] > 0 {
+				remaining[url]--
+				continue
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case out <- url:
+			}
+		}
+	}()
+	return out
+}
+
+// countTokens tallies the valid words, or n-grams of valid words, in text.
+// Tokens the validator rejects are tallied in rejected when it is non-nil.
+func (c *Counter) countTokens(text string, rejected map[string]int) map[string]int {
 	local := make(map[string]int)
-	for _, token := range c.wordRegex.FindAllString(text, -1) {
-		if c.validator.Validate(token) {
-			local[token]++
+	if c.ngramSize <= 1 {
+		for _, token := range c.splitWords(text) {
+			token = c.trimToken(token)
+			if c.accept(token, rejected) {
+				local[c.foldToken(token)] += c.weight(token)
+			}
 		}
+		return local
 	}
 
-	if len(local) == 0 {
-		return true
+	window := make([]string, 0, c.ngramSize)
+	for _, token := range c.splitWords(text) {
+		token = c.trimToken(token)
+		if !c.accept(token, rejected) {
+			window = window[:0]
+			continue
+		}
+		if len(window) == c.ngramSize {
+			copy(window, window[1:])
+			window = window[:c.ngramSize-1]
+		}
+		window = append(window, c.foldToken(token))
+		if len(window) == c.ngramSize {
+			local[strings.Join(window, " ")]++
+		}
 	}
+	return local
+}
 
-	select {
-	case <-ctx.Done():
-		return true
-	case countsCh <- local:
+// splitWords breaks text into raw tokens with the configured tokenizer.
+func (c *Counter) splitWords(text string) []string {
+	if c.segmentWords {
+		return segmentWords(text)
+	}
+	return c.wordRegex.FindAllString(text, -1)
+}
+
+// accept reports whether a trimmed token counts: it must pass the numeric
+// policy and the validator. Tokens only the validator rejects are tallied in
+// rejected when it is non-nil.
+func (c *Counter) accept(token string, rejected map[string]int) bool {
+	if !c.numericPolicy.allows(token) {
+		return false
+	}
+	if c.validator.Validate(token) {
 		return true
 	}
+	if rejected != nil {
+		rejected[token]++
+	}
+	return false
+}
+
+// weight returns how many occurrences a validated token counts for.
+func (c *Counter) weight(token string) int {
+	if c.weigher == nil {
+		return 1
+	}
+	return c.weigher.Weight(token)
+}
+
+// trimToken applies WithTrimPunctuation to a raw token.
+func (c *Counter) trimToken(token string) string {
+	if c.trimPunctuation {
+		return strings.TrimFunc(token, isPunctuationEdge)
+	}
+	return token
+}
+
+// foldToken applies WithLowercaseTokens and WithStemming to an already
+// validated token.
+func (c *Counter) foldToken(token string) string {
+	if c.stem != nil {
+		return c.stem(strings.ToLower(token))
+	}
+	if c.lowercase {
+		return strings.ToLower(token)
+	}
+	return token
+}
+
+// TopWords returns the topN entries of counts by frequency, breaking ties
+// alphabetically.
+func TopWords(counts map[string]int, topN int) map[string]int {
+	return pickTop(counts, topN)
 }
 
 func pickTop(globalCounts map[string]int, topN int) map[string]int {
@@ -159,8 +825,12 @@ func pickTop(globalCounts map[string]int, topN int) map[string]int {
 		pairs = append(pairs, kv{word: word, count: count})
 	}
 
+	// Break ties alphabetically so the top-N cutoff is stable between runs.
 	sort.Slice(pairs, func(i, j int) bool {
-		return pairs[i].count > pairs[j].count
+		if pairs[i].count != pairs[j].count {
+			return pairs[i].count > pairs[j].count
+		}
+		return pairs[i].word < pairs[j].word
 	})
 
 	if len(pairs) > topN {
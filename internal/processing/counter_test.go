@@ -0,0 +1,591 @@
+package processing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/shoresh319/firefly/internal/logging"
+)
+
+// staticFetcher serves canned article text keyed by URL.
+type staticFetcher map[string]string
+
+func (f staticFetcher) Fetch(_ context.Context, url string) (string, error) {
+	text, ok := f[url]
+	if !ok {
+		return "", fmt.Errorf("no article for %s", url)
+	}
+	return text, nil
+}
+
+// setValidator accepts only the words it contains.
+type setValidator map[string]struct{}
+
+func (v setValidator) Validate(word string) bool {
+	_, ok := v[word]
+	return ok
+}
+
+func newSetValidator(words ...string) setValidator {
+	v := make(setValidator, len(words))
+	for _, w := range words {
+		v[w] = struct{}{}
+	}
+	return v
+}
+
+func urlChan(urls ...string) <-chan string {
+	ch := make(chan string, len(urls))
+	for _, u := range urls {
+		ch <- u
+	}
+	close(ch)
+	return ch
+}
+
+func newTestCounter(fetcher ArticleFetcher, validator WordValidator, opts ...Option) *Counter {
+	opts = append([]Option{WithLogger(logging.Nop())}, opts...)
+	return NewCounter(fetcher, validator, opts...)
+}
+
+func TestCountAllWordsReturnsEveryValidWord(t *testing.T) {
+	fetcher := staticFetcher{
+		"a": "apple banana apple cherry skip",
+		"b": "banana apple date",
+	}
+	validator := newSetValidator("apple", "banana", "cherry", "date")
+
+	all, err := newTestCounter(fetcher, validator).CountAllWords(context.Background(), urlChan("a", "b"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := map[string]int{"apple": 3, "banana": 2, "cherry": 1, "date": 1}
+	if len(all) != len(want) {
+		t.Fatalf("expected %v, got %v", want, all)
+	}
+	for word, count := range want {
+		if all[word] != count {
+			t.Fatalf("expected %s=%d, got %d", word, count, all[word])
+		}
+	}
+
+	top, err := newTestCounter(fetcher, validator).CountTopWords(context.Background(), urlChan("a", "b"), 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(top) != 2 {
+		t.Fatalf("expected 2 top words, got %v", top)
+	}
+	for word, count := range top {
+		if all[word] != count {
+			t.Fatalf("top word %s=%d not found in full counts", word, count)
+		}
+	}
+}
+
+func TestWithMinCountDropsRareWords(t *testing.T) {
+	fetcher := staticFetcher{
+		"a": "apple banana apple cherry",
+		"b": "banana apple date elder",
+	}
+	validator := newSetValidator("apple", "banana", "cherry", "date", "elder")
+
+	all, err := newTestCounter(fetcher, validator, WithMinCount(2)).CountAllWords(context.Background(), urlChan("a", "b"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := map[string]int{"apple": 3, "banana": 2}
+	if !reflect.DeepEqual(all, want) {
+		t.Fatalf("expected %v, got %v", want, all)
+	}
+
+	top, err := newTestCounter(fetcher, validator, WithMinCount(2)).CountTopWords(context.Background(), urlChan("a", "b"), 5)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(top, want) {
+		t.Fatalf("expected topN to be limited to words meeting the threshold %v, got %v", want, top)
+	}
+}
+
+func TestPickTopBreaksTiesAlphabetically(t *testing.T) {
+	counts := map[string]int{
+		"zebra":   5,
+		"delta":   3,
+		"bravo":   3,
+		"echo":    3,
+		"alpha":   3,
+		"charlie": 3,
+		"foxtrot": 1,
+	}
+
+	want := map[string]int{"zebra": 5, "alpha": 3, "bravo": 3}
+	for i := 0; i < 50; i++ {
+		got := pickTop(counts, 3)
+		if len(got) != len(want) {
+			t.Fatalf("run %d: expected %v, got %v", i, want, got)
+		}
+		for word, count := range want {
+			if got[word] != count {
+				t.Fatalf("run %d: expected %v, got %v", i, want, got)
+			}
+		}
+	}
+}
+
+func TestCountBigrams(t *testing.T) {
+	fetcher := staticFetcher{
+		"a": "the quick fox saw the quick dog and the quick fox",
+	}
+	// "and" is invalid, so "dog the" must not be formed across it.
+	validator := newSetValidator("the", "quick", "fox", "saw", "dog")
+
+	counts, err := newTestCounter(fetcher, validator, WithNGramSize(2)).CountAllWords(context.Background(), urlChan("a"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := map[string]int{
+		"the quick": 3,
+		"quick fox": 2,
+		"fox saw":   1,
+		"saw the":   1,
+		"quick dog": 1,
+	}
+	if len(counts) != len(want) {
+		t.Fatalf("expected %v, got %v", want, counts)
+	}
+	for phrase, count := range want {
+		if counts[phrase] != count {
+			t.Fatalf("expected %q=%d, got %d", phrase, count, counts[phrase])
+		}
+	}
+}
+
+// weightedValidator accepts the words it holds and weighs them by its values.
+type weightedValidator map[string]int
+
+func (v weightedValidator) Validate(word string) bool {
+	_, ok := v[word]
+	return ok
+}
+
+func (v weightedValidator) Weight(word string) int {
+	return v[word]
+}
+
+func TestCountAllWordsAppliesWeights(t *testing.T) {
+	fetcher := staticFetcher{
+		"a": "apple banana apple cherry skip",
+		"b": "banana apple",
+	}
+	validator := weightedValidator{"apple": 1, "banana": 2, "cherry": 5}
+
+	all, stats, err := newTestCounter(fetcher, validator).CountAllWordsWithStats(context.Background(), urlChan("a", "b"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := map[string]int{"apple": 3, "banana": 4, "cherry": 5}
+	if !reflect.DeepEqual(all, want) {
+		t.Fatalf("expected %v, got %v", want, all)
+	}
+	if stats.TotalTokens != 12 {
+		t.Fatalf("expected 12 weighted tokens, got %d", stats.TotalTokens)
+	}
+}
+
+// blockingFetcher never returns for the blocked URL until its context ends.
+type blockingFetcher struct {
+	staticFetcher
+	blocked string
+}
+
+func (f blockingFetcher) Fetch(ctx context.Context, url string) (string, error) {
+	if url == f.blocked {
+		<-ctx.Done()
+		return "", ctx.Err()
+	}
+	return f.staticFetcher.Fetch(ctx, url)
+}
+
+func TestPerArticleTimeoutSkipsHungArticle(t *testing.T) {
+	fetcher := blockingFetcher{
+		staticFetcher: staticFetcher{"a": "apple apple", "c": "apple cherry"},
+		blocked:       "b",
+	}
+	validator := newSetValidator("apple", "cherry")
+	counter := newTestCounter(fetcher, validator, WithWorkerCount(1), WithPerArticleTimeout(20*time.Millisecond))
+
+	done := make(chan struct{})
+	var counts map[string]int
+	var err error
+	go func() {
+		defer close(done)
+		counts, err = counter.CountAllWords(context.Background(), urlChan("a", "b", "c"))
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("counter did not move past the hung article")
+	}
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if counts["apple"] != 3 || counts["cherry"] != 1 {
+		t.Fatalf("expected apple=3 cherry=1, got %v", counts)
+	}
+}
+
+// cancellingFetcher cancels the run while returning a successful fetch, so the
+// article's counts are ready only after the context has ended.
+type cancellingFetcher struct {
+	cancel context.CancelFunc
+}
+
+func (f cancellingFetcher) Fetch(context.Context, string) (string, error) {
+	f.cancel()
+	return "apple apple", nil
+}
+
+func TestCancelledArticleIsNotASuccess(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	counter := newTestCounter(cancellingFetcher{cancel: cancel}, newSetValidator("apple"), WithWorkerCount(1))
+	counts, stats, err := counter.CountTopWordsWithStats(ctx, urlChan("a"), 5)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+
+	if stats.Successes != 0 || stats.ArticlesAttempted != 0 {
+		t.Fatalf("expected cancelled article to be excluded, got %+v", stats)
+	}
+	if len(counts) != 0 {
+		t.Fatalf("expected no counts after cancellation, got %v", counts)
+	}
+}
+
+// hangingFetcher blocks every fetch until its context ends.
+type hangingFetcher struct{}
+
+func (hangingFetcher) Fetch(ctx context.Context, _ string) (string, error) {
+	<-ctx.Done()
+	return "", ctx.Err()
+}
+
+func TestCountShutsDownOnCancel(t *testing.T) {
+	urls := make([]string, 100)
+	for i := range urls {
+		urls[i] = fmt.Sprintf("u%d", i)
+	}
+
+	for name, cancelAfter := range map[string]time.Duration{"immediately": 0, "mid-run": 20 * time.Millisecond} {
+		t.Run(name, func(t *testing.T) {
+			before := runtime.NumGoroutine()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			if cancelAfter == 0 {
+				cancel()
+			} else {
+				time.AfterFunc(cancelAfter, cancel)
+			}
+			defer cancel()
+
+			counter := newTestCounter(hangingFetcher{}, newSetValidator("apple"), WithWorkerCount(8))
+
+			done := make(chan Stats)
+			go func() {
+				_, stats, _ := counter.CountTopWordsWithStats(ctx, urlChan(urls...), 5)
+				done <- stats
+			}()
+
+			var stats Stats
+			select {
+			case stats = <-done:
+			case <-time.After(2 * time.Second):
+				t.Fatal("counter did not shut down after cancellation")
+			}
+			if stats.Successes != 0 {
+				t.Fatalf("expected no successes, got %+v", stats)
+			}
+
+			// Allow the runtime a moment to reap exited goroutines.
+			deadline := time.Now().Add(time.Second)
+			for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+				time.Sleep(10 * time.Millisecond)
+			}
+			if n := runtime.NumGoroutine(); n > before {
+				t.Fatalf("expected worker goroutines to exit, %d before and %d after", before, n)
+			}
+		})
+	}
+}
+
+// orderFetcher records the order in which URLs are fetched. Earlier URLs take
+// longer, so concurrent workers would finish them out of order.
+type orderFetcher struct {
+	mu    sync.Mutex
+	order []string
+}
+
+func (f *orderFetcher) Fetch(_ context.Context, url string) (string, error) {
+	var n int
+	fmt.Sscanf(url, "u%d", &n)
+	time.Sleep(time.Duration(10-n%10) * time.Millisecond)
+	f.mu.Lock()
+	f.order = append(f.order, url)
+	f.mu.Unlock()
+	return "apple", nil
+}
+
+func TestWithDeterministicProcessesURLsInOrder(t *testing.T) {
+	urls := make([]string, 20)
+	for i := range urls {
+		urls[i] = fmt.Sprintf("u%d", i)
+	}
+
+	var runs [2][]string
+	for i := range runs {
+		fetcher := &orderFetcher{}
+		counter := newTestCounter(fetcher, newSetValidator("apple"), WithWorkerCount(8), WithDeterministic(true))
+		counts, err := counter.CountAllWords(context.Background(), urlChan(urls...))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if counts["apple"] != len(urls) {
+			t.Fatalf("expected apple=%d, got %d", len(urls), counts["apple"])
+		}
+		runs[i] = fetcher.order
+	}
+
+	if !reflect.DeepEqual(runs[0], runs[1]) {
+		t.Fatalf("expected identical processing order, got %v and %v", runs[0], runs[1])
+	}
+	if !reflect.DeepEqual(runs[0], urls) {
+		t.Fatalf("expected list order %v, got %v", urls, runs[0])
+	}
+}
+
+// slowFetcher returns "apple" after a fixed delay, ignoring cancellation so
+// that in-flight articles always complete.
+type slowFetcher struct {
+	delay time.Duration
+}
+
+func (f slowFetcher) Fetch(context.Context, string) (string, error) {
+	time.Sleep(f.delay)
+	return "apple", nil
+}
+
+func TestWithMaxRuntimeReturnsPartialResults(t *testing.T) {
+	urls := make([]string, 50)
+	for i := range urls {
+		urls[i] = fmt.Sprintf("u%d", i)
+	}
+
+	counter := newTestCounter(slowFetcher{delay: 20 * time.Millisecond}, newSetValidator("apple"),
+		WithWorkerCount(2), WithMaxRuntime(100*time.Millisecond))
+
+	start := time.Now()
+	counts, stats, err := counter.CountTopWordsWithStats(context.Background(), urlChan(urls...), 5)
+	if err != nil {
+		t.Fatalf("expected partial results without error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Fatalf("expected the run to stop near the budget, took %v", elapsed)
+	}
+
+	if !stats.RuntimeExceeded {
+		t.Fatal("expected RuntimeExceeded to be set")
+	}
+	if stats.Successes == 0 || stats.Successes >= len(urls) {
+		t.Fatalf("expected some but not all articles to be counted, got %d", stats.Successes)
+	}
+	// Articles in flight when the budget ran out are finished and counted.
+	if counts["apple"] != stats.Successes {
+		t.Fatalf("expected apple=%d, got %d", stats.Successes, counts["apple"])
+	}
+}
+
+func TestWithMaxRuntimeUnusedBudget(t *testing.T) {
+	counter := newTestCounter(staticFetcher{"a": "apple"}, newSetValidator("apple"), WithMaxRuntime(time.Minute))
+
+	_, stats, err := counter.CountTopWordsWithStats(context.Background(), urlChan("a"), 5)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stats.RuntimeExceeded || stats.Successes != 1 {
+		t.Fatalf("expected a complete run, got %+v", stats)
+	}
+}
+
+func TestWithMaxArticlesStopsAfterLimit(t *testing.T) {
+	fetcher := staticFetcher{}
+	var urls []string
+	for i := 0; i < 20; i++ {
+		url := fmt.Sprintf("u%d", i)
+		urls = append(urls, url)
+		// Every third URL has no article, so its fetch fails.
+		if i%3 != 0 {
+			fetcher[url] = "apple"
+		}
+	}
+
+	for _, workers := range []int{1, 4} {
+		counter := newTestCounter(fetcher, newSetValidator("apple"), WithWorkerCount(workers), WithMaxArticles(3))
+		counts, stats, err := counter.CountTopWordsWithStats(context.Background(), urlChan(urls...), 5)
+		if err != nil {
+			t.Fatalf("workers=%d: expected partial results without error, got %v", workers, err)
+		}
+		if stats.Successes != 3 || counts["apple"] != 3 {
+			t.Fatalf("workers=%d: expected exactly 3 successes, got %d with apple=%d", workers, stats.Successes, counts["apple"])
+		}
+		if !stats.ArticleLimitReached {
+			t.Fatalf("workers=%d: expected ArticleLimitReached to be set", workers)
+		}
+	}
+}
+
+// limitFetcher serves "apple" for the first fast URLs and blocks every other
+// fetch until its context ends.
+type limitFetcher struct {
+	fast map[string]bool
+}
+
+func (f limitFetcher) Fetch(ctx context.Context, url string) (string, error) {
+	if f.fast[url] {
+		return "apple", nil
+	}
+	<-ctx.Done()
+	return "", ctx.Err()
+}
+
+func TestWithMaxArticlesCancelsFetchesInFlight(t *testing.T) {
+	fetcher := limitFetcher{fast: map[string]bool{"u1": true, "u3": true, "u5": true}}
+	urls := make([]string, 8)
+	for i := range urls {
+		urls[i] = fmt.Sprintf("u%d", i)
+	}
+
+	counter := newTestCounter(fetcher, newSetValidator("apple"), WithWorkerCount(8), WithMaxArticles(3))
+	done := make(chan struct{})
+	var stats Stats
+	go func() {
+		defer close(done)
+		_, stats, _ = counter.CountTopWordsWithStats(context.Background(), urlChan(urls...), 5)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the limit to cancel blocked fetches")
+	}
+	if stats.Successes != 3 || stats.Failures != 0 {
+		t.Fatalf("expected 3 successes and no failures, got %+v", stats)
+	}
+}
+
+func TestWithMaxArticlesUnreached(t *testing.T) {
+	counter := newTestCounter(staticFetcher{"a": "apple", "b": "apple"}, newSetValidator("apple"), WithMaxArticles(5))
+
+	_, stats, err := counter.CountTopWordsWithStats(context.Background(), urlChan("a", "b"), 5)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stats.ArticleLimitReached || stats.Successes != 2 {
+		t.Fatalf("expected a complete run, got %+v", stats)
+	}
+}
+
+// cancelAfterFetcher serves text for URLs up to and including last, then
+// cancels the run and blocks every later fetch until its context ends.
+type cancelAfterFetcher struct {
+	texts  map[string]string
+	last   string
+	cancel context.CancelFunc
+}
+
+func (f cancelAfterFetcher) Fetch(ctx context.Context, url string) (string, error) {
+	if text, ok := f.texts[url]; ok {
+		return text, nil
+	}
+	f.cancel()
+	<-ctx.Done()
+	return "", ctx.Err()
+}
+
+func TestCountTopWordsReturnsPartialCountsOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	fetcher := cancelAfterFetcher{
+		texts:  map[string]string{"a": "apple apple banana"},
+		cancel: cancel,
+	}
+	counter := newTestCounter(fetcher, newSetValidator("apple", "banana"), WithWorkerCount(1))
+
+	top, err := counter.CountTopWords(ctx, urlChan("a", "b", "c"), 5)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if top == nil {
+		t.Fatalf("expected partial counts, got nil map")
+	}
+	if top["apple"] != 2 || top["banana"] != 1 {
+		t.Fatalf("expected counts from the first article, got %v", top)
+	}
+}
+
+func TestCountAllWordsReturnsDeadlineExceeded(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	counts, err := newTestCounter(hangingFetcher{}, newSetValidator("apple")).CountAllWords(ctx, urlChan("a"))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+	if counts == nil {
+		t.Fatalf("expected an empty, non-nil map")
+	}
+}
+
+func TestWithResultBufferSetsMergeChannelCapacity(t *testing.T) {
+	if got := newTestCounter(staticFetcher{}, anyWord{}, WithWorkerCount(3)).resultBufferSize(); got != 6 {
+		t.Fatalf("expected the default of twice the workers, 6, got %d", got)
+	}
+	if got := newTestCounter(staticFetcher{}, anyWord{}, WithWorkerCount(3), WithResultBuffer(64)).resultBufferSize(); got != 64 {
+		t.Fatalf("expected the configured capacity 64, got %d", got)
+	}
+}
+
+func TestMergeStallsReportBackpressure(t *testing.T) {
+	fetcher := make(staticFetcher)
+	urls := make([]string, 10)
+	for i := range urls {
+		urls[i] = fmt.Sprintf("article-%d", i)
+		fetcher[urls[i]] = "glow"
+	}
+	counter := newTestCounter(fetcher, anyWord{}, WithWorkerCount(4), WithResultBuffer(1))
+
+	// Hold the merge goroutine on the first article so the buffer fills up.
+	gate := make(chan struct{})
+	time.AfterFunc(50*time.Millisecond, func() { close(gate) })
+	counts, stats := counter.count(context.Background(), urlChan(urls...), 0, func(map[string]int, int) { <-gate })
+
+	if counts["glow"] != 10 {
+		t.Fatalf("expected glow=10, got %v", counts)
+	}
+	if stats.MergeStalls == 0 {
+		t.Fatalf("expected workers to stall on the full merge channel, got %+v", stats)
+	}
+}
@@ -0,0 +1,13 @@
+package processing
+
+import "unicode/utf8"
+
+// LengthHistogram maps word length, in runes, to the number of distinct words
+// of that length in counts. How often each word occurred is ignored.
+func LengthHistogram(counts map[string]int) map[int]int {
+	histogram := make(map[int]int)
+	for word := range counts {
+		histogram[utf8.RuneCountInString(word)]++
+	}
+	return histogram
+}
@@ -0,0 +1,32 @@
+package processing
+
+import "testing"
+
+func TestLengthHistogram(t *testing.T) {
+	counts := map[string]int{
+		"fox":   7,
+		"dog":   1,
+		"lazy":  3,
+		"quick": 2,
+		"brown": 2,
+		"naïve": 1, // five runes, six bytes
+	}
+
+	got := LengthHistogram(counts)
+
+	want := map[int]int{3: 2, 4: 1, 5: 3}
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for length, n := range want {
+		if got[length] != n {
+			t.Fatalf("expected %d words of length %d, got %d", n, length, got[length])
+		}
+	}
+}
+
+func TestLengthHistogramEmpty(t *testing.T) {
+	if got := LengthHistogram(nil); len(got) != 0 {
+		t.Fatalf("expected empty histogram, got %v", got)
+	}
+}
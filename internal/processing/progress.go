@@ -0,0 +1,43 @@
+package processing
+
+import "sync"
+
+// WithProgress calls fn each time an article finishes, successfully or not,
+// with the number finished so far and the total set by WithProgressTotal
+// (zero when unknown). Calls are serialized and done increases by one each
+// time, so fn needs no locking of its own, but it runs on a worker goroutine
+// and should return quickly. Articles abandoned on cancellation are not reported.
+func WithProgress(fn func(done, total int)) Option {
+	return func(c *Counter) {
+		c.progress = fn
+	}
+}
+
+// WithProgressTotal sets the total passed to the WithProgress callback, such
+// as the number of URLs in the article list.
+func WithProgressTotal(n int) Option {
+	return func(c *Counter) {
+		if n > 0 {
+			c.progressTotal = n
+		}
+	}
+}
+
+// progressReporter serializes WithProgress callbacks from concurrent workers.
+type progressReporter struct {
+	mu    sync.Mutex
+	fn    func(done, total int)
+	total int
+	done  int
+}
+
+// articleDone records one finished article. It is a no-op without a callback.
+func (p *progressReporter) articleDone() {
+	if p.fn == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.done++
+	p.fn(p.done, p.total)
+}
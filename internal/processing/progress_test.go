@@ -0,0 +1,49 @@
+package processing
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestWithProgressReportsEveryArticle(t *testing.T) {
+	const total = 25
+	fetcher := staticFetcher{}
+	urls := make([]string, 0, total)
+	for i := 0; i < total; i++ {
+		url := fmt.Sprintf("u%d", i)
+		urls = append(urls, url)
+		if i%5 != 0 { // every fifth article fails
+			fetcher[url] = "apple banana"
+		}
+	}
+
+	var mu sync.Mutex
+	var dones []int
+	counter := newTestCounter(fetcher, newSetValidator("apple", "banana"),
+		WithWorkerCount(8),
+		WithProgressTotal(total),
+		WithProgress(func(done, got int) {
+			mu.Lock()
+			defer mu.Unlock()
+			if got != total {
+				t.Errorf("expected total %d, got %d", total, got)
+			}
+			dones = append(dones, done)
+		}),
+	)
+
+	if _, err := counter.CountAllWords(context.Background(), urlChan(urls...)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(dones) != total {
+		t.Fatalf("expected %d progress calls, got %d", total, len(dones))
+	}
+	for i, done := range dones {
+		if done != i+1 {
+			t.Fatalf("expected done values 1..%d in order, got %v", total, dones)
+		}
+	}
+}
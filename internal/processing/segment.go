@@ -0,0 +1,151 @@
+package processing
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// WithWordSegmentation replaces the regular-expression tokenizer with Unicode
+// word segmentation following the default rules of UAX #29, for text mixing
+// scripts. Runs of Latin, Cyrillic and other alphabetic letters form words,
+// keeping inner apostrophes and periods as in "don't" and "e.g"; numbers keep
+// their separators, as in "3.14" and "1,000"; katakana runs stay together;
+// and each Han ideograph or hiragana character is a word of its own, since
+// those scripts are written without spaces. WithWordRegex, WithApostrophes and
+// WithHyphens do not apply while it is enabled.
+func WithWordSegmentation(enabled bool) Option {
+	return func(c *Counter) {
+		c.segmentWords = enabled
+	}
+}
+
+// wordClass is the UAX #29 Word_Break property of a rune, reduced to the
+// values the tokenizer distinguishes.
+type wordClass int
+
+const (
+	wbOther wordClass = iota
+	wbALetter
+	wbNumeric
+	wbKatakana
+	wbIdeographic // Han and hiragana: one word per rune
+	wbExtendNumLet
+	wbExtend // Combining marks, format characters and ZWJ
+	wbMidLetter
+	wbMidNum
+	wbMidNumLet
+	wbSingleQuote
+)
+
+func classifyRune(r rune) wordClass {
+	switch r {
+	case '\'':
+		return wbSingleQuote
+	case '.', '\u2018', '\u2019', '\u2024', '\ufe52', '\uff07', '\uff0e':
+		return wbMidNumLet
+	case ':', '\u00b7', '\u0387', '\u05f4', '\u2027', '\ufe13', '\ufe55', '\uff1a':
+		return wbMidLetter
+	case ',', ';', '\u037e', '\u0589', '\u060c', '\u060d', '\u066c', '\u07f8', '\u2044', '\ufe10', '\ufe14', '\ufe50', '\ufe54', '\uff0c', '\uff1b':
+		return wbMidNum
+	case '\u3031', '\u3032', '\u3033', '\u3034', '\u3035', '\u309b', '\u309c', '\u30a0', '\u30fc', '\uff70', '\uff9e', '\uff9f':
+		return wbKatakana
+	case '\u200b':
+		return wbOther
+	}
+	switch {
+	case unicode.In(r, unicode.Mn, unicode.Me, unicode.Mc, unicode.Cf):
+		return wbExtend
+	case unicode.Is(unicode.Katakana, r):
+		return wbKatakana
+	case unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Ideographic):
+		return wbIdeographic
+	case unicode.IsLetter(r):
+		return wbALetter
+	case unicode.IsDigit(r):
+		return wbNumeric
+	case unicode.IsNumber(r):
+		return wbALetter
+	case unicode.Is(unicode.Pc, r):
+		return wbExtendNumLet
+	}
+	return wbOther
+}
+
+// joins reports whether no word boundary falls between runes of classes a
+// and b (rules WB5 and WB8 to WB13b).
+func joins(a, b wordClass) bool {
+	switch b {
+	case wbALetter, wbNumeric:
+		return a == wbALetter || a == wbNumeric || a == wbExtendNumLet
+	case wbKatakana:
+		return a == wbKatakana || a == wbExtendNumLet
+	case wbExtendNumLet:
+		return a == wbALetter || a == wbNumeric || a == wbKatakana || a == wbExtendNumLet
+	}
+	return false
+}
+
+// bridges reports whether the separator class mid joins a word ending in last
+// to one starting with next, as in "don't" or "3.14" (rules WB6, WB7, WB11
+// and WB12).
+func bridges(last, mid, next wordClass) bool {
+	switch {
+	case last == wbALetter && next == wbALetter:
+		return mid == wbMidLetter || mid == wbMidNumLet || mid == wbSingleQuote
+	case last == wbNumeric && next == wbNumeric:
+		return mid == wbMidNum || mid == wbMidNumLet || mid == wbSingleQuote
+	}
+	return false
+}
+
+// segmentWords splits text at UAX #29 word boundaries and returns the
+// segments holding letters, digits or connector punctuation, dropping spaces
+// and punctuation.
+func segmentWords(text string) []string {
+	var words []string
+	for i := 0; i < len(text); {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		last := classifyRune(r)
+		end := skipExtend(text, i+size)
+		switch last {
+		case wbIdeographic:
+		case wbALetter, wbNumeric, wbKatakana, wbExtendNumLet:
+			for {
+				r, size := utf8.DecodeRuneInString(text[end:])
+				next := classifyRune(r)
+				if end < len(text) && joins(last, next) {
+					end = skipExtend(text, end+size)
+					last = next
+					continue
+				}
+				after := skipExtend(text, end+size)
+				r2, size2 := utf8.DecodeRuneInString(text[after:])
+				if end < len(text) && after < len(text) && bridges(last, next, classifyRune(r2)) {
+					last = classifyRune(r2)
+					end = skipExtend(text, after+size2)
+					continue
+				}
+				break
+			}
+		default:
+			i = end
+			continue
+		}
+		words = append(words, text[i:end])
+		i = end
+	}
+	return words
+}
+
+// skipExtend returns the index after any combining marks or format
+// characters starting at i, which belong to the preceding rune (rule WB4).
+func skipExtend(text string, i int) int {
+	for i < len(text) {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		if classifyRune(r) != wbExtend {
+			break
+		}
+		i += size
+	}
+	return i
+}
@@ -0,0 +1,45 @@
+package processing
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestSegmentWordsMixedScripts(t *testing.T) {
+	tests := map[string][]string{
+		"I like 東京 and カタカナ, don't you?": {"I", "like", "東", "京", "and", "カタカナ", "don't", "you"},
+		"今日はいい天気ですね":                     {"今", "日", "は", "い", "い", "天", "気", "で", "す", "ね"},
+		"version 3.14 costs 1,000 yen.":  {"version", "3.14", "costs", "1,000", "yen"},
+		"snake_case, naïve café... e.g.": {"snake_case", "naïve", "café", "e.g"},
+		"well-known — 'quoted'":          {"well", "known", "quoted"},
+		"":                               nil,
+	}
+	for text, want := range tests {
+		if got := segmentWords(text); !reflect.DeepEqual(got, want) {
+			t.Fatalf("segmentWords(%q): expected %q, got %q", text, want, got)
+		}
+	}
+}
+
+func TestWithWordSegmentationCountsJapanese(t *testing.T) {
+	fetcher := staticFetcher{"a": "Tokyo 東京 は東京です. Tokyo"}
+
+	regexCounts, err := newTestCounter(fetcher, anyWord{}).CountAllWords(context.Background(), urlChan("a"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if regexCounts["東京"] != 1 || regexCounts["は東京です"] != 1 {
+		t.Fatalf("expected the default tokenizer to split on spaces only, got %v", regexCounts)
+	}
+
+	counts, err := newTestCounter(fetcher, anyWord{}, WithWordSegmentation(true)).
+		CountAllWords(context.Background(), urlChan("a"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := map[string]int{"Tokyo": 2, "東": 2, "京": 2, "は": 1, "で": 1, "す": 1}
+	if !reflect.DeepEqual(counts, want) {
+		t.Fatalf("expected %v, got %v", want, counts)
+	}
+}
@@ -0,0 +1,68 @@
+package processing
+
+import (
+	"hash/maphash"
+	"sync"
+)
+
+// mergeShards is the number of independently locked maps in shardedCounts.
+// It comfortably exceeds typical core counts so workers rarely contend.
+const mergeShards = 64
+
+// shardedCounts lets workers merge their article counts concurrently instead
+// of funnelling them through one merge goroutine. Each word belongs to the
+// shard picked by its hash, so shards hold disjoint keys and are combined
+// once at the end.
+type shardedCounts struct {
+	seed   maphash.Seed
+	shards [mergeShards]countShard
+}
+
+type countShard struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+func newShardedCounts() *shardedCounts {
+	s := &shardedCounts{seed: maphash.MakeSeed()}
+	for i := range s.shards {
+		s.shards[i].counts = make(map[string]int)
+	}
+	return s
+}
+
+// add merges one article's counts, taking each shard's lock at most once.
+func (s *shardedCounts) add(local map[string]int) {
+	var buckets [mergeShards][]string
+	for token := range local {
+		i := maphash.String(s.seed, token) % mergeShards
+		buckets[i] = append(buckets[i], token)
+	}
+	for i, tokens := range buckets {
+		if len(tokens) == 0 {
+			continue
+		}
+		shard := &s.shards[i]
+		shard.mu.Lock()
+		for _, token := range tokens {
+			shard.counts[token] += local[token]
+		}
+		shard.mu.Unlock()
+	}
+}
+
+// merge combines the shards into one map. It must only be called once all
+// adds have returned.
+func (s *shardedCounts) merge() map[string]int {
+	size := 0
+	for i := range s.shards {
+		size += len(s.shards[i].counts)
+	}
+	merged := make(map[string]int, size)
+	for i := range s.shards {
+		for token, n := range s.shards[i].counts {
+			merged[token] = n
+		}
+	}
+	return merged
+}
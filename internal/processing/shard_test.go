@@ -0,0 +1,123 @@
+package processing
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestShardedMergeMatchesChannelMerge(t *testing.T) {
+	fetcher, urls := skewedArticles(300, 40)
+
+	sharded, shardedStats, err := newTestCounter(fetcher, anyWord{}, WithWorkerCount(8)).
+		CountTopWordsWithStats(context.Background(), urlChan(urls...), 1000)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	channel := newTestCounter(fetcher, anyWord{}, WithWorkerCount(8))
+	channel.channelMerge = true
+	want, wantStats, err := channel.CountTopWordsWithStats(context.Background(), urlChan(urls...), 1000)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !reflect.DeepEqual(sharded, want) {
+		t.Fatalf("expected sharded counts to match channel merge")
+	}
+	if shardedStats.TotalTokens != wantStats.TotalTokens || shardedStats.DistinctWords != wantStats.DistinctWords {
+		t.Fatalf("expected stats %+v, got %+v", wantStats, shardedStats)
+	}
+}
+
+func TestShardedCountsConcurrentAdd(t *testing.T) {
+	shards := newShardedCounts()
+	var wg sync.WaitGroup
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				shards.add(map[string]int{"apple": 1, fmt.Sprintf("word%d", i): 2})
+			}
+		}()
+	}
+	wg.Wait()
+
+	merged := shards.merge()
+	if merged["apple"] != 800 {
+		t.Fatalf("expected apple=800, got %d", merged["apple"])
+	}
+	if merged["word42"] != 16 || len(merged) != 101 {
+		t.Fatalf("expected 101 words with word42=16, got %d words and word42=%d", len(merged), merged["word42"])
+	}
+}
+
+// BenchmarkMerge compares funnelling every article through the merge
+// goroutine with merging into sharded maps from the workers. The gap grows
+// with GOMAXPROCS, since the merge goroutine is serial; run it with -cpu.
+func BenchmarkMerge(b *testing.B) {
+	const articles = 256
+	var text strings.Builder
+	for i := 0; i < 4000; i++ {
+		fmt.Fprintf(&text, "word%d ", i%2000)
+	}
+	fetcher := make(staticFetcher, articles)
+	urls := make([]string, articles)
+	for i := range urls {
+		urls[i] = fmt.Sprintf("article-%d", i)
+		fetcher[urls[i]] = text.String()
+	}
+	workers := 4 * runtime.GOMAXPROCS(0)
+
+	for _, bc := range []struct {
+		name    string
+		channel bool
+	}{
+		{"channel", true},
+		{"sharded", false},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			counter := newTestCounter(fetcher, anyWord{}, WithWorkerCount(workers))
+			counter.channelMerge = bc.channel
+			for b.Loop() {
+				if _, err := counter.CountAllWords(context.Background(), urlChan(urls...)); err != nil {
+					b.Fatalf("unexpected error: %v", err)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkResultBuffer measures the channel merge with different
+// WithResultBuffer capacities; zero is the default of twice the workers.
+func BenchmarkResultBuffer(b *testing.B) {
+	const articles = 256
+	var text strings.Builder
+	for i := 0; i < 4000; i++ {
+		fmt.Fprintf(&text, "word%d ", i%2000)
+	}
+	fetcher := make(staticFetcher, articles)
+	urls := make([]string, articles)
+	for i := range urls {
+		urls[i] = fmt.Sprintf("article-%d", i)
+		fetcher[urls[i]] = text.String()
+	}
+	workers := 4 * runtime.GOMAXPROCS(0)
+
+	for _, size := range []int{0, 1, 16, 256} {
+		b.Run(fmt.Sprintf("buffer-%d", size), func(b *testing.B) {
+			counter := newTestCounter(fetcher, anyWord{}, WithWorkerCount(workers), WithResultBuffer(size))
+			counter.channelMerge = true
+			for b.Loop() {
+				if _, err := counter.CountAllWords(context.Background(), urlChan(urls...)); err != nil {
+					b.Fatalf("unexpected error: %v", err)
+				}
+			}
+		})
+	}
+}
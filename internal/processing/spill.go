@@ -0,0 +1,273 @@
+package processing
+
+import (
+	"bufio"
+	"container/heap"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+)
+
+// defaultSpillThreshold is how many distinct tokens WithSpillDir keeps in
+// memory before writing them out.
+const defaultSpillThreshold = 1 << 20
+
+// WithSpillDir bounds the memory held by the running totals on long runs.
+// Whenever they reach about a million distinct tokens they are written to a
+// sorted run file in a temporary directory under dir and cleared; at the end
+// the runs are merged in a streaming k-way merge, so CountTopWords keeps only
+// the top N in memory. Results match the in-memory path. Spilling applies to
+// batch counts without WithCheckpoint, ResumeFrom or WithApproxTopK;
+// CountTopWordsStream keeps its totals in memory for its snapshots. If a run
+// cannot be written, counting carries on in memory.
+func WithSpillDir(dir string) Option {
+	return func(c *Counter) {
+		c.spillDir = dir
+	}
+}
+
+// spillTally is an exact tally that moves its counts to sorted run files in
+// dir once threshold distinct tokens are held.
+type spillTally struct {
+	dir       string
+	threshold int
+	mem       map[string]int
+	runs      []string
+	// err collects spill and merge failures; spilling stops after the first.
+	err error
+}
+
+func newSpillTally(parent string, threshold int) (*spillTally, error) {
+	dir, err := os.MkdirTemp(parent, "firefly-spill-")
+	if err != nil {
+		return nil, fmt.Errorf("create spill directory: %w", err)
+	}
+	return &spillTally{dir: dir, threshold: threshold, mem: make(map[string]int)}, nil
+}
+
+func (t *spillTally) add(token string, n int) {
+	t.mem[token] += n
+	if len(t.mem) >= t.threshold && t.err == nil {
+		if err := t.spill(); err != nil {
+			t.err = err
+		}
+	}
+}
+
+// counts merges the runs with the in-memory counts into a single map.
+func (t *spillTally) counts() map[string]int {
+	all := make(map[string]int)
+	t.err = errors.Join(t.err, t.merge(func(token string, n int) { all[token] = n }))
+	return all
+}
+
+// top returns the topN tokens of the merged counts that reach minCount,
+// holding no more than topN of them at a time, along with how many distinct
+// tokens reached minCount.
+func (t *spillTally) top(topN, minCount int) (map[string]int, int) {
+	h := &topHeap{}
+	distinct := 0
+	t.err = errors.Join(t.err, t.merge(func(token string, n int) {
+		if n < minCount {
+			return
+		}
+		distinct++
+		if h.Len() < topN {
+			heap.Push(h, wordCount{token, n})
+		} else if h.Len() > 0 && outranks(wordCount{token, n}, (*h)[0]) {
+			(*h)[0] = wordCount{token, n}
+			heap.Fix(h, 0)
+		}
+	}))
+	top := make(map[string]int, h.Len())
+	for _, wc := range *h {
+		top[wc.word] = wc.count
+	}
+	return top, distinct
+}
+
+// close removes the run files.
+func (t *spillTally) close() error {
+	return os.RemoveAll(t.dir)
+}
+
+// spill writes the in-memory counts, sorted by token, to a new run file.
+func (t *spillTally) spill() error {
+	tokens := make([]string, 0, len(t.mem))
+	for token := range t.mem {
+		tokens = append(tokens, token)
+	}
+	slices.Sort(tokens)
+
+	f, err := os.CreateTemp(t.dir, "run-*")
+	if err != nil {
+		return fmt.Errorf("create spill run: %w", err)
+	}
+	w := bufio.NewWriter(f)
+	var buf [binary.MaxVarintLen64]byte
+	for _, token := range tokens {
+		w.Write(buf[:binary.PutUvarint(buf[:], uint64(len(token)))])
+		w.WriteString(token)
+		w.Write(buf[:binary.PutUvarint(buf[:], uint64(t.mem[token]))])
+	}
+	if err := errors.Join(w.Flush(), f.Close()); err != nil {
+		os.Remove(f.Name())
+		return fmt.Errorf("write spill run: %w", err)
+	}
+
+	t.runs = append(t.runs, f.Name())
+	clear(t.mem)
+	return nil
+}
+
+// merge streams every token with its total over the runs and the in-memory
+// counts, in token order.
+func (t *spillTally) merge(emit func(token string, n int)) error {
+	var sources mergeHeap
+	for _, path := range t.runs {
+		f, err := os.Open(path)
+		if err != nil {
+			return fmt.Errorf("open spill run: %w", err)
+		}
+		defer f.Close()
+		src := &runReader{r: bufio.NewReader(f)}
+		if err := src.next(); err != nil {
+			return err
+		}
+		if !src.exhausted() {
+			sources = append(sources, src)
+		}
+	}
+	if len(t.mem) > 0 {
+		mem := &memReader{counts: t.mem, tokens: make([]string, 0, len(t.mem))}
+		for token := range t.mem {
+			mem.tokens = append(mem.tokens, token)
+		}
+		slices.Sort(mem.tokens)
+		sources = append(sources, mem)
+	}
+	heap.Init(&sources)
+
+	for sources.Len() > 0 {
+		token, _ := sources[0].head()
+		total := 0
+		for sources.Len() > 0 {
+			src := sources[0]
+			next, n := src.head()
+			if next != token {
+				break
+			}
+			total += n
+			if err := src.next(); err != nil {
+				return err
+			}
+			if src.exhausted() {
+				heap.Pop(&sources)
+			} else {
+				heap.Fix(&sources, 0)
+			}
+		}
+		emit(token, total)
+	}
+	return nil
+}
+
+// mergeSource yields (token, count) pairs in token order.
+type mergeSource interface {
+	head() (string, int)
+	next() error
+	exhausted() bool
+}
+
+type runReader struct {
+	r     *bufio.Reader
+	token string
+	count int
+	done  bool
+}
+
+func (s *runReader) head() (string, int) { return s.token, s.count }
+func (s *runReader) exhausted() bool     { return s.done }
+
+func (s *runReader) next() error {
+	size, err := binary.ReadUvarint(s.r)
+	if err == io.EOF {
+		s.done = true
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("read spill run: %w", err)
+	}
+	var token strings.Builder
+	if _, err := io.CopyN(&token, s.r, int64(size)); err != nil {
+		return fmt.Errorf("read spill run: %w", err)
+	}
+	count, err := binary.ReadUvarint(s.r)
+	if err != nil {
+		return fmt.Errorf("read spill run: %w", err)
+	}
+	s.token, s.count = token.String(), int(count)
+	return nil
+}
+
+type memReader struct {
+	counts map[string]int
+	tokens []string
+	pos    int
+}
+
+func (s *memReader) head() (string, int) {
+	token := s.tokens[s.pos]
+	return token, s.counts[token]
+}
+func (s *memReader) exhausted() bool { return s.pos == len(s.tokens) }
+func (s *memReader) next() error     { s.pos++; return nil }
+
+// mergeHeap orders sources by their current token.
+type mergeHeap []mergeSource
+
+func (h mergeHeap) Len() int { return len(h) }
+func (h mergeHeap) Less(i, j int) bool {
+	a, _ := h[i].head()
+	b, _ := h[j].head()
+	return a < b
+}
+func (h mergeHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+func (h *mergeHeap) Push(x any)   { *h = append(*h, x.(mergeSource)) }
+func (h *mergeHeap) Pop() any {
+	old := *h
+	src := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return src
+}
+
+type wordCount struct {
+	word  string
+	count int
+}
+
+// outranks orders words as pickTop does: by count, then alphabetically.
+func outranks(a, b wordCount) bool {
+	if a.count != b.count {
+		return a.count > b.count
+	}
+	return a.word < b.word
+}
+
+// topHeap keeps the weakest of the top words at the root.
+type topHeap []wordCount
+
+func (h topHeap) Len() int           { return len(h) }
+func (h topHeap) Less(i, j int) bool { return outranks(h[j], h[i]) }
+func (h topHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *topHeap) Push(x any)        { *h = append(*h, x.(wordCount)) }
+func (h *topHeap) Pop() any {
+	old := *h
+	wc := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return wc
+}
@@ -0,0 +1,94 @@
+package processing
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"reflect"
+	"testing"
+)
+
+// spillFixture returns articles sharing an uneven vocabulary, so words appear
+// in several spilled runs with tied and untied totals.
+func spillFixture() (staticFetcher, []string) {
+	fetcher := make(staticFetcher)
+	var urls []string
+	for i := 0; i < 12; i++ {
+		text := ""
+		for j := 0; j < 40; j++ {
+			text += fmt.Sprintf("w%d ", (i*7+j*j)%53)
+		}
+		url := fmt.Sprintf("article-%d", i)
+		fetcher[url] = text
+		urls = append(urls, url)
+	}
+	return fetcher, urls
+}
+
+func TestWithSpillDirMatchesInMemoryCounts(t *testing.T) {
+	fetcher, urls := spillFixture()
+
+	for _, opts := range [][]Option{nil, {WithMinCount(3)}} {
+		inMemory := newTestCounter(fetcher, anyWord{}, opts...)
+		wantTop, wantStats, err := inMemory.CountTopWordsWithStats(context.Background(), urlChan(urls...), 10)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		wantAll, err := inMemory.CountAllWords(context.Background(), urlChan(urls...))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		dir := t.TempDir()
+		spilled := newTestCounter(fetcher, anyWord{}, append(opts, WithSpillDir(dir))...)
+		spilled.spillThreshold = 4
+		gotTop, gotStats, err := spilled.CountTopWordsWithStats(context.Background(), urlChan(urls...), 10)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		gotAll, err := spilled.CountAllWords(context.Background(), urlChan(urls...))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if !reflect.DeepEqual(gotTop, wantTop) {
+			t.Fatalf("expected top words %v, got %v", wantTop, gotTop)
+		}
+		if !reflect.DeepEqual(gotAll, wantAll) {
+			t.Fatalf("expected all words %v, got %v", wantAll, gotAll)
+		}
+		if gotStats.DistinctWords != wantStats.DistinctWords || gotStats.TotalTokens != wantStats.TotalTokens {
+			t.Fatalf("expected stats %+v, got %+v", wantStats, gotStats)
+		}
+		if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+			t.Fatalf("expected spill files to be removed, got %d entries", len(entries))
+		}
+	}
+}
+
+func TestSpillTallyWritesRuns(t *testing.T) {
+	tally, err := newSpillTally(t.TempDir(), 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer tally.close()
+
+	for _, token := range []string{"b", "a", "b", "c", "a", "d", "b"} {
+		tally.add(token, 1)
+	}
+	if len(tally.runs) != 3 || len(tally.mem) != 1 {
+		t.Fatalf("expected 3 runs and 1 word in memory, got %d runs and %v", len(tally.runs), tally.mem)
+	}
+
+	want := map[string]int{"a": 2, "b": 3, "c": 1, "d": 1}
+	if got := tally.counts(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	top, distinct := tally.top(2, 0)
+	if !reflect.DeepEqual(top, map[string]int{"a": 2, "b": 3}) || distinct != 4 {
+		t.Fatalf("expected a=2 b=3 of 4 distinct, got %v of %d", top, distinct)
+	}
+	if tally.err != nil {
+		t.Fatalf("unexpected spill error: %v", tally.err)
+	}
+}
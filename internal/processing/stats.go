@@ -0,0 +1,130 @@
+package processing
+
+import (
+	"context"
+	"net/url"
+	"sync"
+)
+
+// Stats summarizes a counting run.
+type Stats struct {
+	ArticlesAttempted int // Articles that succeeded or failed; ones abandoned on cancellation are excluded
+	Successes         int // Articles fetched and tokenized
+	Failures          int // Articles whose fetch failed
+	Skipped           int // Articles the fetcher declined with ErrSkipped; not counted as attempted
+	DistinctWords     int // Distinct valid tokens across all articles, capped by WithApproxTopK
+	TotalTokens       int // Valid tokens counted, including repeats
+	// RuntimeExceeded reports that the WithMaxRuntime budget ran out, so the
+	// counts may cover only part of the URL list.
+	RuntimeExceeded bool
+	// ArticleLimitReached reports that the WithMaxArticles limit stopped the
+	// run before the URL list was exhausted.
+	ArticleLimitReached bool
+	// MergeStalls counts articles whose counts found the merge channel full
+	// and had to wait. A large share of Successes suggests raising
+	// WithResultBuffer or that merging, such as checkpointing, is the bottleneck.
+	MergeStalls int
+	// Rejected holds the WithRejectedTopN most frequent tokens the validator
+	// rejected, with how often each occurred; nil unless that option is set.
+	Rejected map[string]int
+	// Domains breaks the article outcomes down by host name. Local files are
+	// grouped under the empty string.
+	Domains map[string]DomainStat
+}
+
+// DomainStat summarizes the articles fetched from one host.
+type DomainStat struct {
+	Attempted int
+	Successes int
+	Failures  int
+	Words     int // Valid tokens contributed, including repeats
+}
+
+// domainTracker accumulates DomainStat values from concurrent workers.
+type domainTracker struct {
+	mu    sync.Mutex
+	stats map[string]DomainStat
+}
+
+func newDomainTracker() *domainTracker {
+	return &domainTracker{stats: make(map[string]DomainStat)}
+}
+
+// record adds one finished article. Cancelled and skipped articles are
+// ignored, matching the run-wide totals.
+func (d *domainTracker) record(rawURL string, outcome articleOutcome, words int) {
+	if outcome == articleCancelled || outcome == articleSkipped {
+		return
+	}
+	domain := hostOf(rawURL)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	stat := d.stats[domain]
+	stat.Attempted++
+	if outcome == articleSucceeded {
+		stat.Successes++
+		stat.Words += words
+	} else {
+		stat.Failures++
+	}
+	d.stats[domain] = stat
+}
+
+// rejectedTracker accumulates the tokens rejected by the validator across
+// concurrent workers. A nil tracker records nothing.
+type rejectedTracker struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+// newRejectedTracker returns a tracker when topN enables WithRejectedTopN,
+// and nil otherwise.
+func newRejectedTracker(topN int) *rejectedTracker {
+	if topN <= 0 {
+		return nil
+	}
+	return &rejectedTracker{counts: make(map[string]int)}
+}
+
+// add merges one article's rejected tokens.
+func (r *rejectedTracker) add(rejected map[string]int) {
+	if r == nil || len(rejected) == 0 {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for token, n := range rejected {
+		r.counts[token] += n
+	}
+}
+
+// hostOf returns the host name of rawURL the same way articles.Source groups
+// requests per domain: the URL's host without port. Unparseable URLs and
+// local paths yield the empty string.
+func hostOf(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return parsed.Hostname()
+}
+
+// CountTopWordsWithStats behaves like CountTopWords and additionally returns
+// statistics describing the run, which are also filled in when the run was cut
+// short.
+func (c *Counter) CountTopWordsWithStats(ctx context.Context, urlCh <-chan string, topN int) (map[string]int, Stats, error) {
+	globalCounts, stats := c.count(ctx, urlCh, topN, nil)
+
+	topCounts := pickTop(globalCounts, topN)
+	c.logger.Info("kept top words", "top_n", topN, "distinct", len(topCounts))
+
+	return topCounts, stats, ctx.Err()
+}
+
+// CountAllWordsWithStats behaves like CountAllWords and additionally returns
+// statistics describing the run.
+func (c *Counter) CountAllWordsWithStats(ctx context.Context, urlCh <-chan string) (map[string]int, Stats, error) {
+	globalCounts, stats := c.count(ctx, urlCh, 0, nil)
+	return globalCounts, stats, ctx.Err()
+}
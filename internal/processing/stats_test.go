@@ -0,0 +1,120 @@
+package processing
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestCountTopWordsWithStats(t *testing.T) {
+	fetcher := staticFetcher{
+		"good-1": "apple banana apple skip",
+		"good-2": "cherry apple",
+		"empty":  "nothing valid here",
+	}
+	validator := newSetValidator("apple", "banana", "cherry")
+
+	top, stats, err := newTestCounter(fetcher, validator).CountTopWordsWithStats(
+		context.Background(),
+		urlChan("good-1", "missing-1", "good-2", "empty", "missing-2"),
+		2,
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := Stats{
+		ArticlesAttempted: 5,
+		Successes:         3,
+		Failures:          2,
+		DistinctWords:     3,
+		TotalTokens:       5,
+	}
+	stats.Domains = nil
+	if !reflect.DeepEqual(stats, want) {
+		t.Fatalf("expected %+v, got %+v", want, stats)
+	}
+	if len(top) != 2 || top["apple"] != 3 {
+		t.Fatalf("unexpected top words %v", top)
+	}
+}
+
+func TestCountTopWordsWithStatsPerDomain(t *testing.T) {
+	fetcher := staticFetcher{
+		"https://a.example/1":      "apple banana apple",
+		"https://a.example:8443/2": "cherry skip",
+		"http://b.example/1":       "banana",
+	}
+	validator := newSetValidator("apple", "banana", "cherry")
+
+	_, stats, err := newTestCounter(fetcher, validator).CountTopWordsWithStats(
+		context.Background(),
+		urlChan("https://a.example/1", "https://a.example:8443/2", "https://a.example/missing", "http://b.example/1", "http://b.example/missing"),
+		5,
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := map[string]DomainStat{
+		"a.example": {Attempted: 3, Successes: 2, Failures: 1, Words: 4},
+		"b.example": {Attempted: 2, Successes: 1, Failures: 1, Words: 1},
+	}
+	if !reflect.DeepEqual(stats.Domains, want) {
+		t.Fatalf("expected %+v, got %+v", want, stats.Domains)
+	}
+}
+
+// skippingFetcher declines every URL with ErrSkipped.
+type skippingFetcher struct{}
+
+func (skippingFetcher) Fetch(_ context.Context, url string) (string, error) {
+	return "", fmt.Errorf("fetch %s: %w", url, ErrSkipped)
+}
+
+func TestSkippedArticlesAreNotFailures(t *testing.T) {
+	_, stats, err := newTestCounter(skippingFetcher{}, newSetValidator("apple")).CountTopWordsWithStats(
+		context.Background(),
+		urlChan("https://a.example/private", "https://a.example/secret"),
+		5,
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if stats.Skipped != 2 || stats.Failures != 0 || stats.ArticlesAttempted != 0 {
+		t.Fatalf("expected 2 skipped and no attempts, got %+v", stats)
+	}
+	if len(stats.Domains) != 0 {
+		t.Fatalf("expected skipped articles to be left out of domain stats, got %v", stats.Domains)
+	}
+}
+
+func TestWithRejectedTopNReportsFrequentUnknownWords(t *testing.T) {
+	fetcher := staticFetcher{
+		"a": "apple blockchain banana blockchain 2024 zzz",
+		"b": "blockchain apple metaverse metaverse nothing",
+		"c": "no words here",
+	}
+	validator := newSetValidator("apple", "banana")
+
+	counter := newTestCounter(fetcher, validator, WithRejectedTopN(2), WithNumericPolicy(NumericExclude))
+	top, stats, err := counter.CountTopWordsWithStats(context.Background(), urlChan("a", "b", "c"), 5)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if want := map[string]int{"apple": 2, "banana": 1}; !reflect.DeepEqual(top, want) {
+		t.Fatalf("expected accepted words %v, got %v", want, top)
+	}
+	// "2024" is dropped by the numeric policy, not the validator.
+	if want := map[string]int{"blockchain": 3, "metaverse": 2}; !reflect.DeepEqual(stats.Rejected, want) {
+		t.Fatalf("expected rejected words %v, got %v", want, stats.Rejected)
+	}
+
+	_, stats, _ = newTestCounter(fetcher, validator).CountTopWordsWithStats(context.Background(), urlChan("a"), 5)
+	if stats.Rejected != nil {
+		t.Fatalf("expected no rejected report by default, got %v", stats.Rejected)
+	}
+}
@@ -0,0 +1,238 @@
+package processing
+
+import "strings"
+
+// stemmers maps the languages accepted by WithStemming to their stemmers.
+var stemmers = map[string]func(string) string{
+	"en":      porterStem,
+	"english": porterStem,
+}
+
+// WithStemming counts words by stem so inflected forms such as "run",
+// "running" and "runs" share one count under the key "run". Stemming runs
+// after validation, so the validator still sees each surface form, and folds
+// case like WithLowercaseTokens. Stems are not always words themselves:
+// "happy" is counted as "happi". lang is an ISO 639-1 code or English name;
+// only English ("en", Porter's algorithm) is supported, and other languages
+// are counted unstemmed with a warning. An empty lang disables stemming.
+func WithStemming(lang string) Option {
+	return func(c *Counter) {
+		c.stemLang = strings.ToLower(lang)
+	}
+}
+
+// porterStem reduces a lowercase English word to its stem with Porter's
+// algorithm. Words of two letters or fewer, or containing anything but
+// ASCII letters, are returned unchanged.
+func porterStem(word string) string {
+	if len(word) <= 2 {
+		return word
+	}
+	for i := 0; i < len(word); i++ {
+		if word[i] < 'a' || word[i] > 'z' {
+			return word
+		}
+	}
+
+	p := porter{b: []byte(word)}
+	p.step1a()
+	p.step1b()
+	p.step1c()
+	p.step2()
+	p.step3()
+	p.step4()
+	p.step5()
+	return string(p.b)
+}
+
+// porter holds a word being stemmed. Helpers taking n look at its first n
+// bytes, the stem left once a suffix is removed.
+type porter struct {
+	b []byte
+}
+
+// cons reports whether b[i] is a consonant; y is one unless it follows a
+// consonant.
+func (p *porter) cons(i int) bool {
+	switch p.b[i] {
+	case 'a', 'e', 'i', 'o', 'u':
+		return false
+	case 'y':
+		return i == 0 || !p.cons(i-1)
+	}
+	return true
+}
+
+// measure counts the vowel-consonant sequences in b[:n], Porter's m.
+func (p *porter) measure(n int) int {
+	i := 0
+	for i < n && p.cons(i) {
+		i++
+	}
+	m := 0
+	for {
+		for i < n && !p.cons(i) {
+			i++
+		}
+		if i >= n {
+			return m
+		}
+		for i < n && p.cons(i) {
+			i++
+		}
+		m++
+	}
+}
+
+// hasVowel reports whether b[:n] contains a vowel.
+func (p *porter) hasVowel(n int) bool {
+	for i := 0; i < n; i++ {
+		if !p.cons(i) {
+			return true
+		}
+	}
+	return false
+}
+
+// doubleCons reports whether b[:n] ends in a doubled consonant.
+func (p *porter) doubleCons(n int) bool {
+	return n >= 2 && p.b[n-1] == p.b[n-2] && p.cons(n-1)
+}
+
+// cvc reports whether b[:n] ends consonant-vowel-consonant with the last
+// consonant not w, x or y, as in "hop" but not "snow".
+func (p *porter) cvc(n int) bool {
+	if n < 3 || !p.cons(n-3) || p.cons(n-2) || !p.cons(n-1) {
+		return false
+	}
+	last := p.b[n-1]
+	return last != 'w' && last != 'x' && last != 'y'
+}
+
+// stemLen returns the length of the stem before suffix, or -1 when the word
+// does not end in suffix.
+func (p *porter) stemLen(suffix string) int {
+	if !strings.HasSuffix(string(p.b), suffix) {
+		return -1
+	}
+	return len(p.b) - len(suffix)
+}
+
+// replace swaps the suffix following the first n bytes for repl.
+func (p *porter) replace(n int, repl string) {
+	p.b = append(p.b[:n], repl...)
+}
+
+// replaceFirst replaces the first of rules' suffixes the word ends in,
+// provided its stem has a measure above minMeasure. Later rules are not
+// tried once one matches.
+func (p *porter) replaceFirst(rules [][2]string, minMeasure int) {
+	for _, rule := range rules {
+		if n := p.stemLen(rule[0]); n >= 0 {
+			if p.measure(n) > minMeasure {
+				p.replace(n, rule[1])
+			}
+			return
+		}
+	}
+}
+
+// step1a removes plurals: caresses -> caress, ponies -> poni, cats -> cat.
+func (p *porter) step1a() {
+	switch {
+	case p.stemLen("sses") >= 0, p.stemLen("ies") >= 0:
+		p.b = p.b[:len(p.b)-2]
+	case p.stemLen("ss") >= 0:
+	case p.stemLen("s") >= 0:
+		p.b = p.b[:len(p.b)-1]
+	}
+}
+
+// step1b removes -ed and -ing: agreed -> agree, hopping -> hop, filing -> file.
+func (p *porter) step1b() {
+	if n := p.stemLen("eed"); n >= 0 {
+		if p.measure(n) > 0 {
+			p.replace(n+2, "")
+		}
+		return
+	}
+	n := p.stemLen("ed")
+	if n < 0 {
+		n = p.stemLen("ing")
+	}
+	if n < 0 || !p.hasVowel(n) {
+		return
+	}
+	p.replace(n, "")
+	switch {
+	case p.stemLen("at") >= 0, p.stemLen("bl") >= 0, p.stemLen("iz") >= 0:
+		p.b = append(p.b, 'e')
+	case p.doubleCons(len(p.b)):
+		if last := p.b[len(p.b)-1]; last != 'l' && last != 's' && last != 'z' {
+			p.b = p.b[:len(p.b)-1]
+		}
+	case p.measure(len(p.b)) == 1 && p.cvc(len(p.b)):
+		p.b = append(p.b, 'e')
+	}
+}
+
+// step1c turns a final y into i after a vowel: happy -> happi.
+func (p *porter) step1c() {
+	if n := p.stemLen("y"); n >= 0 && p.hasVowel(n) {
+		p.b[n] = 'i'
+	}
+}
+
+var porterStep2 = [][2]string{
+	{"ational", "ate"}, {"tional", "tion"}, {"enci", "ence"}, {"anci", "ance"},
+	{"izer", "ize"}, {"bli", "ble"}, {"alli", "al"}, {"entli", "ent"},
+	{"eli", "e"}, {"ousli", "ous"}, {"ization", "ize"}, {"ation", "ate"},
+	{"ator", "ate"}, {"alism", "al"}, {"iveness", "ive"}, {"fulness", "ful"},
+	{"ousness", "ous"}, {"aliti", "al"}, {"iviti", "ive"}, {"biliti", "ble"},
+	{"logi", "log"},
+}
+
+// step2 maps double suffixes to single ones: relational -> relate.
+func (p *porter) step2() { p.replaceFirst(porterStep2, 0) }
+
+var porterStep3 = [][2]string{
+	{"icate", "ic"}, {"ative", ""}, {"alize", "al"}, {"iciti", "ic"},
+	{"ical", "ic"}, {"ful", ""}, {"ness", ""},
+}
+
+// step3 handles -ic-, -full and -ness: hopeful -> hope.
+func (p *porter) step3() { p.replaceFirst(porterStep3, 0) }
+
+var porterStep4 = []string{
+	"al", "ance", "ence", "er", "ic", "able", "ible", "ant", "ement", "ment",
+	"ent", "ion", "ou", "ism", "ate", "iti", "ous", "ive", "ize",
+}
+
+// step4 removes suffixes from stems of measure above one: adjustment -> adjust.
+func (p *porter) step4() {
+	for _, suffix := range porterStep4 {
+		n := p.stemLen(suffix)
+		if n < 0 {
+			continue
+		}
+		if suffix == "ion" && (n == 0 || (p.b[n-1] != 's' && p.b[n-1] != 't')) {
+			continue
+		}
+		if p.measure(n) > 1 {
+			p.replace(n, "")
+		}
+		return
+	}
+}
+
+// step5 tidies the end: probate -> probat, controll -> control.
+func (p *porter) step5() {
+	if n := p.stemLen("e"); n >= 0 {
+		if m := p.measure(n); m > 1 || (m == 1 && !p.cvc(n)) {
+			p.b = p.b[:n]
+		}
+	}
+	if n := len(p.b); p.b[n-1] == 'l' && p.doubleCons(n) && p.measure(n) > 1 {
+		p.b = p.b[:n-1]
+	}
+}
@@ -0,0 +1,65 @@
+package processing
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestPorterStem(t *testing.T) {
+	tests := map[string]string{
+		"caresses":       "caress",
+		"ponies":         "poni",
+		"cats":           "cat",
+		"feed":           "feed",
+		"agreed":         "agre",
+		"plastered":      "plaster",
+		"motoring":       "motor",
+		"sing":           "sing",
+		"hopping":        "hop",
+		"falling":        "fall",
+		"filing":         "file",
+		"happy":          "happi",
+		"relational":     "relat",
+		"generalization": "gener",
+		"adjustment":     "adjust",
+		"controlling":    "control",
+		"running":        "run",
+		"runs":           "run",
+		"run":            "run",
+		"is":             "is",
+		"naïve":          "naïve", // non-ASCII words are left alone
+	}
+	for word, want := range tests {
+		if got := porterStem(word); got != want {
+			t.Fatalf("porterStem(%q): expected %q, got %q", word, want, got)
+		}
+	}
+}
+
+func TestWithStemmingMergesInflectedForms(t *testing.T) {
+	fetcher := staticFetcher{"a": "Run running runs ran connected connection connecting"}
+
+	counts, err := newTestCounter(fetcher, anyWord{}, WithStemming("en")).
+		CountAllWords(context.Background(), urlChan("a"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := map[string]int{"run": 3, "ran": 1, "connect": 3}
+	if !reflect.DeepEqual(counts, want) {
+		t.Fatalf("expected %v, got %v", want, counts)
+	}
+}
+
+func TestWithStemmingUnknownLanguageCountsUnstemmed(t *testing.T) {
+	fetcher := staticFetcher{"a": "running runs"}
+
+	counts, err := newTestCounter(fetcher, anyWord{}, WithStemming("xx")).
+		CountAllWords(context.Background(), urlChan("a"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := map[string]int{"running": 1, "runs": 1}; !reflect.DeepEqual(counts, want) {
+		t.Fatalf("expected %v, got %v", want, counts)
+	}
+}
@@ -0,0 +1,51 @@
+package processing
+
+import "context"
+
+const defaultSnapshotInterval = 50
+
+// CountTopWordsStream behaves like CountTopWords but reports progress: a top-N
+// snapshot is sent every time the configured number of articles has been
+// merged, followed by a final snapshot equal to the batch result. Both channels
+// are closed when the run ends; if the run was cut short, the error channel
+// yields the context error and no final snapshot is sent. Callers must drain
+// the snapshot channel.
+func (c *Counter) CountTopWordsStream(ctx context.Context, urlCh <-chan string, topN int) (<-chan map[string]int, <-chan error) {
+	snapshots := make(chan map[string]int)
+	errCh := make(chan error, 1)
+
+	send := func(snapshot map[string]int) bool {
+		select {
+		case <-ctx.Done():
+			return false
+		case snapshots <- snapshot:
+			return true
+		}
+	}
+
+	go func() {
+		defer close(errCh)
+		defer close(snapshots)
+
+		globalCounts, _ := c.count(ctx, urlCh, topN, func(counts map[string]int, merged int) {
+			if merged%c.snapshotInterval == 0 {
+				send(pickTop(counts, topN))
+			}
+		})
+
+		topCounts := pickTop(globalCounts, topN)
+		c.logger.Info("kept top words", "top_n", topN, "distinct", len(topCounts))
+
+		// Check first: send picks randomly when ctx is done and the caller is
+		// ready, which could pass off a cancelled run as complete.
+		if err := ctx.Err(); err != nil {
+			errCh <- err
+			return
+		}
+		if !send(topCounts) {
+			errCh <- ctx.Err()
+		}
+	}()
+
+	return snapshots, errCh
+}
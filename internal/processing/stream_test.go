@@ -0,0 +1,68 @@
+package processing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestCountTopWordsStreamEmitsSnapshots(t *testing.T) {
+	fetcher := staticFetcher{}
+	urls := make([]string, 0, 10)
+	for i := 0; i < 10; i++ {
+		url := fmt.Sprintf("article-%d", i)
+		fetcher[url] = "apple banana apple cherry"
+		urls = append(urls, url)
+	}
+	validator := newSetValidator("apple", "banana", "cherry")
+
+	counter := newTestCounter(fetcher, validator, WithSnapshotInterval(3))
+	snapshots, errCh := counter.CountTopWordsStream(context.Background(), urlChan(urls...), 2)
+
+	var received []map[string]int
+	for snapshot := range snapshots {
+		received = append(received, snapshot)
+	}
+	if err := <-errCh; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// 10 articles at an interval of 3 yields 3 progress snapshots plus the final one.
+	if len(received) != 4 {
+		t.Fatalf("expected 4 snapshots, got %d: %v", len(received), received)
+	}
+
+	batch, err := newTestCounter(fetcher, validator).CountTopWords(context.Background(), urlChan(urls...), 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	final := received[len(received)-1]
+	if len(final) != len(batch) {
+		t.Fatalf("expected final snapshot %v to equal batch result %v", final, batch)
+	}
+	for word, count := range batch {
+		if final[word] != count {
+			t.Fatalf("expected final snapshot %v to equal batch result %v", final, batch)
+		}
+	}
+}
+
+func TestCountTopWordsStreamReportsCancellation(t *testing.T) {
+	fetcher := staticFetcher{"a": "apple banana apple"}
+	validator := newSetValidator("apple", "banana")
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// The caller is always ready to receive, so a final snapshot racing the
+	// cancellation would win about half the time.
+	for range 50 {
+		snapshots, errCh := newTestCounter(fetcher, validator).CountTopWordsStream(ctx, urlChan("a"), 2)
+		for range snapshots {
+		}
+		if err := <-errCh; !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected context.Canceled, got %v", err)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package processing
+
+import (
+	"container/heap"
+	"hash/maphash"
+)
+
+// tally accumulates token counts in the merge goroutine.
+type tally interface {
+	add(token string, n int)
+	// counts returns the tracked totals. The map must not be modified and is
+	// only valid until the next add.
+	counts() map[string]int
+}
+
+// exactTally counts every token precisely.
+type exactTally map[string]int
+
+func (t exactTally) add(token string, n int) { t[token] += n }
+
+func (t exactTally) counts() map[string]int { return t }
+
+// Count-min sketch dimensions. With 2^16 counters per row an estimate exceeds
+// the true count by more than e/2^16 (about 0.004%) of all tokens counted with
+// probability at most e^-4 (about 2%), using 2 MiB regardless of vocabulary.
+const (
+	sketchWidth = 1 << 16
+	sketchDepth = 4
+)
+
+// countMinSketch estimates token frequencies in fixed memory. Estimates are
+// never below the true count.
+type countMinSketch struct {
+	seed maphash.Seed
+	rows [sketchDepth][]uint64
+}
+
+func newCountMinSketch() *countMinSketch {
+	s := &countMinSketch{seed: maphash.MakeSeed()}
+	for i := range s.rows {
+		s.rows[i] = make([]uint64, sketchWidth)
+	}
+	return s
+}
+
+// add records n occurrences of token and returns its updated estimate.
+func (s *countMinSketch) add(token string, n int) int {
+	// Derive one index per row from a single hash (Kirsch-Mitzenmacher).
+	h := maphash.String(s.seed, token)
+	h1, h2 := h&0xffffffff, h>>32|1
+	estimate := ^uint64(0)
+	for i := range s.rows {
+		idx := (h1 + uint64(i)*h2) % sketchWidth
+		s.rows[i][idx] += uint64(n)
+		estimate = min(estimate, s.rows[i][idx])
+	}
+	return int(estimate)
+}
+
+// candidate is a word tracked by approxTally.
+type candidate struct {
+	word  string
+	count int
+	index int
+}
+
+// candidateHeap is a min-heap on count, so the weakest candidate is at the root.
+type candidateHeap []*candidate
+
+func (h candidateHeap) Len() int           { return len(h) }
+func (h candidateHeap) Less(i, j int) bool { return h[i].count < h[j].count }
+func (h candidateHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *candidateHeap) Push(x any) {
+	c := x.(*candidate)
+	c.index = len(*h)
+	*h = append(*h, c)
+}
+
+func (h *candidateHeap) Pop() any {
+	old := *h
+	c := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return c
+}
+
+// approxTally keeps estimated counts for the k most frequent tokens seen.
+type approxTally struct {
+	k        int
+	sketch   *countMinSketch
+	heap     candidateHeap
+	tracked  map[string]*candidate
+	snapshot map[string]int
+}
+
+func newApproxTally(k int) *approxTally {
+	return &approxTally{
+		k:       k,
+		sketch:  newCountMinSketch(),
+		tracked: make(map[string]*candidate, k),
+	}
+}
+
+func (t *approxTally) add(token string, n int) {
+	estimate := t.sketch.add(token, n)
+	t.snapshot = nil
+
+	if c, ok := t.tracked[token]; ok {
+		c.count = estimate
+		heap.Fix(&t.heap, c.index)
+		return
+	}
+	if len(t.heap) < t.k {
+		c := &candidate{word: token, count: estimate}
+		heap.Push(&t.heap, c)
+		t.tracked[token] = c
+		return
+	}
+	if weakest := t.heap[0]; estimate > weakest.count {
+		delete(t.tracked, weakest.word)
+		weakest.word, weakest.count = token, estimate
+		t.tracked[token] = weakest
+		heap.Fix(&t.heap, 0)
+	}
+}
+
+func (t *approxTally) counts() map[string]int {
+	if t.snapshot == nil {
+		t.snapshot = make(map[string]int, len(t.heap))
+		for _, c := range t.heap {
+			t.snapshot[c.word] = c.count
+		}
+	}
+	return t.snapshot
+}
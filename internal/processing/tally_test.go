@@ -0,0 +1,94 @@
+package processing
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// skewedArticles builds a Zipf-like corpus: word i appears about 2000/(i+1)
+// times, spread across several articles.
+func skewedArticles(distinct, articles int) (staticFetcher, []string) {
+	var tokens []string
+	for i := 0; i < distinct; i++ {
+		for n := 0; n < 2000/(i+1); n++ {
+			tokens = append(tokens, fmt.Sprintf("word%03d", i))
+		}
+	}
+
+	fetcher := make(staticFetcher, articles)
+	urls := make([]string, articles)
+	for a := 0; a < articles; a++ {
+		var text strings.Builder
+		for i := a; i < len(tokens); i += articles {
+			text.WriteString(tokens[i])
+			text.WriteByte(' ')
+		}
+		urls[a] = fmt.Sprintf("article-%d", a)
+		fetcher[urls[a]] = text.String()
+	}
+	return fetcher, urls
+}
+
+// anyWord accepts every token.
+type anyWord struct{}
+
+func (anyWord) Validate(string) bool { return true }
+
+func TestApproxTopKMatchesExactOnSkewedData(t *testing.T) {
+	fetcher, urls := skewedArticles(500, 20)
+
+	exactAll, err := newTestCounter(fetcher, anyWord{}).CountAllWords(context.Background(), urlChan(urls...))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	exact := pickTop(exactAll, 10)
+
+	approx, err := newTestCounter(fetcher, anyWord{}, WithApproxTopK(50)).
+		CountTopWords(context.Background(), urlChan(urls...), 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(approx) != len(exact) {
+		t.Fatalf("expected %d words, got %v", len(exact), approx)
+	}
+	for word, count := range exact {
+		estimate, ok := approx[word]
+		if !ok {
+			t.Fatalf("expected %q in approximate top words, got %v", word, approx)
+		}
+		if estimate < count {
+			t.Fatalf("expected estimate for %q to be at least %d, got %d", word, count, estimate)
+		}
+	}
+}
+
+func TestApproxTopKBoundsTrackedWords(t *testing.T) {
+	fetcher, urls := skewedArticles(500, 5)
+
+	all, stats, err := newTestCounter(fetcher, anyWord{}, WithApproxTopK(25)).
+		CountTopWordsWithStats(context.Background(), urlChan(urls...), 1000)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(all) != 25 || stats.DistinctWords != 25 {
+		t.Fatalf("expected 25 tracked words, got %d (distinct %d)", len(all), stats.DistinctWords)
+	}
+}
+
+func TestCountMinSketchNeverUndercounts(t *testing.T) {
+	sketch := newCountMinSketch()
+	want := map[string]int{}
+	for i := 0; i < 10000; i++ {
+		word := fmt.Sprintf("w%d", i%997)
+		want[word]++
+		sketch.add(word, 1)
+	}
+	for word, count := range want {
+		if got := sketch.add(word, 0); got < count {
+			t.Fatalf("expected estimate for %q of at least %d, got %d", word, count, got)
+		}
+	}
+}
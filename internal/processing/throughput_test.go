@@ -0,0 +1,76 @@
+package processing_test
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/shoresh319/firefly/internal/articles"
+	"github.com/shoresh319/firefly/internal/logging"
+	"github.com/shoresh319/firefly/internal/processing"
+)
+
+// throughputArticles is the size of the synthetic run each iteration counts.
+const throughputArticles = 10_000
+
+type acceptAll struct{}
+
+func (acceptAll) Validate(string) bool { return true }
+
+// syntheticText returns a fixed article of n words over a vocabulary of
+// vocab words, skewed so a few words dominate as in real text.
+func syntheticText(n, vocab int) string {
+	var text strings.Builder
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&text, "word%d ", (i*i+i)%vocab)
+	}
+	return text.String()
+}
+
+func urlChan(urls []string) <-chan string {
+	ch := make(chan string, len(urls))
+	for _, u := range urls {
+		ch <- u
+	}
+	close(ch)
+	return ch
+}
+
+// BenchmarkCountThroughput counts a synthetic 10k-article run served by
+// articles.StaticFetcher with 1, 4 and GOMAXPROCS workers, reporting
+// words/sec so worker counts and merge strategies can be compared without
+// network variance. Run with go test -bench CountThroughput -count 5 and
+// compare with benchstat to spot regressions.
+func BenchmarkCountThroughput(b *testing.B) {
+	const wordsPerArticle = 200
+	fetcher := articles.StaticFetcher(syntheticText(wordsPerArticle, 500))
+	urls := make([]string, throughputArticles)
+	for i := range urls {
+		urls[i] = fmt.Sprintf("article-%d", i)
+	}
+
+	workerCounts := []int{1, 4}
+	if n := runtime.GOMAXPROCS(0); n != 1 && n != 4 {
+		workerCounts = append(workerCounts, n)
+	}
+	for _, workers := range workerCounts {
+		b.Run(fmt.Sprintf("workers-%d", workers), func(b *testing.B) {
+			counter := processing.NewCounter(fetcher, acceptAll{},
+				processing.WithWorkerCount(workers), processing.WithLogger(logging.Nop()))
+			words := 0
+			for b.Loop() {
+				_, stats, err := counter.CountAllWordsWithStats(context.Background(), urlChan(urls))
+				if err != nil {
+					b.Fatalf("unexpected error: %v", err)
+				}
+				if stats.TotalTokens != throughputArticles*wordsPerArticle {
+					b.Fatalf("expected %d tokens, got %d", throughputArticles*wordsPerArticle, stats.TotalTokens)
+				}
+				words += stats.TotalTokens
+			}
+			b.ReportMetric(float64(words)/b.Elapsed().Seconds(), "words/s")
+		})
+	}
+}
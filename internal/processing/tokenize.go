@@ -0,0 +1,60 @@
+package processing
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// wordChars matches a run of letters, combining marks, digits and underscores
+// in any script: the Unicode counterpart of \w.
+const wordChars = `[\p{L}\p{M}\p{N}_]+`
+
+// wordPattern builds the default token expression. Enabled joiners are kept
+// inside a token only when word characters follow them on both sides, so
+// "don't" and "well-known" stay whole while "rock-" and "'quoted'" do not.
+func wordPattern(apostrophes, hyphens bool) *regexp.Regexp {
+	var joiners strings.Builder
+	if apostrophes {
+		joiners.WriteString(`'’`)
+	}
+	if hyphens {
+		joiners.WriteString(`\-`)
+	}
+	if joiners.Len() == 0 {
+		return regexp.MustCompile(wordChars)
+	}
+	return regexp.MustCompile(wordChars + `(?:[` + joiners.String() + `]` + wordChars + `)*`)
+}
+
+// NumericPolicy selects how tokens containing digits are treated.
+type NumericPolicy int
+
+const (
+	// NumericInclude counts tokens whether or not they contain digits, so
+	// "2024" and "mp3" count when the validator accepts them. It is the default.
+	NumericInclude NumericPolicy = iota
+	// NumericExclude drops every token containing a digit.
+	NumericExclude
+	// NumericAlphaOnly drops tokens without at least one letter, such as
+	// "2024", but keeps alphanumerics such as "mp3".
+	NumericAlphaOnly
+)
+
+// allows reports whether token passes the policy.
+func (p NumericPolicy) allows(token string) bool {
+	switch p {
+	case NumericExclude:
+		return !strings.ContainsFunc(token, unicode.IsNumber)
+	case NumericAlphaOnly:
+		return strings.ContainsFunc(token, unicode.IsLetter)
+	default:
+		return true
+	}
+}
+
+// isPunctuationEdge reports whether r falls outside wordChars and may be
+// trimmed from the ends of a token.
+func isPunctuationEdge(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsMark(r) && !unicode.IsNumber(r) && r != '_'
+}
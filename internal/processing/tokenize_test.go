@@ -0,0 +1,168 @@
+package processing
+
+import (
+	"context"
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+func TestWordPattern(t *testing.T) {
+	tests := map[string]struct {
+		apostrophes, hyphens bool
+		text                 string
+		want                 []string
+	}{
+		"default splits contractions": {
+			text: "don't stop",
+			want: []string{"don", "t", "stop"},
+		},
+		"apostrophes": {
+			apostrophes: true,
+			text:        "don't stop, it’s 'quoted'",
+			want:        []string{"don't", "stop", "it’s", "quoted"},
+		},
+		"hyphens": {
+			hyphens: true,
+			text:    "a well-known rock- band -- state-of-the-art",
+			want:    []string{"a", "well-known", "rock", "band", "state-of-the-art"},
+		},
+		"hyphens only": {
+			hyphens: true,
+			text:    "don't",
+			want:    []string{"don", "t"},
+		},
+		"accented words": {
+			text: "café naïve Über straße",
+			want: []string{"café", "naïve", "Über", "straße"},
+		},
+		"decomposed accent": {
+			text: "cafe\u0301 au lait",
+			want: []string{"cafe\u0301", "au", "lait"},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := wordPattern(tc.apostrophes, tc.hyphens).FindAllString(tc.text, -1)
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("expected %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestCounterTokenizerOptions(t *testing.T) {
+	fetcher := staticFetcher{"a": "Don't panic: a well-known café don't"}
+	validator := newSetValidator("Don't", "don't", "well-known", "café")
+
+	counts, err := newTestCounter(fetcher, validator, WithApostrophes(true), WithHyphens(true)).
+		CountAllWords(context.Background(), urlChan("a"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := map[string]int{"Don't": 1, "don't": 1, "well-known": 1, "café": 1}
+	if !reflect.DeepEqual(counts, want) {
+		t.Fatalf("expected %v, got %v", want, counts)
+	}
+}
+
+func TestWithWordRegexOverridesTokenizerOptions(t *testing.T) {
+	fetcher := staticFetcher{"a": "don't well-known"}
+	validator := newSetValidator("don", "well")
+
+	counts, err := newTestCounter(fetcher, validator, WithApostrophes(true), WithHyphens(true), WithWordRegex(regexp.MustCompile(`[a-z]+`))).
+		CountAllWords(context.Background(), urlChan("a"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if counts["don"] != 1 || counts["well"] != 1 || len(counts) != 2 {
+		t.Fatalf("expected custom regex to win, got %v", counts)
+	}
+}
+
+func TestWithLowercaseTokensMergesCase(t *testing.T) {
+	fetcher := staticFetcher{"a": "Apple apple APPLE pear", "b": "apple Pear"}
+	validator := newSetValidator("Apple", "apple", "APPLE", "pear", "Pear")
+
+	counts, err := newTestCounter(fetcher, validator, WithLowercaseTokens(true)).
+		CountAllWords(context.Background(), urlChan("a", "b"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := map[string]int{"apple": 4, "pear": 2}
+	if !reflect.DeepEqual(counts, want) {
+		t.Fatalf("expected %v, got %v", want, counts)
+	}
+}
+
+func TestWithTrimPunctuation(t *testing.T) {
+	fetcher := staticFetcher{"a": `(apple) "banana" 'cherry' apple. apple, don't! “pear” ... --`}
+	validator := newSetValidator("apple", "banana", "cherry", "don't", "pear")
+	whitespace := regexp.MustCompile(`\S+`)
+
+	counts, err := newTestCounter(fetcher, validator, WithWordRegex(whitespace), WithTrimPunctuation(true)).
+		CountAllWords(context.Background(), urlChan("a"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := map[string]int{"apple": 3, "banana": 1, "cherry": 1, "don't": 1, "pear": 1}
+	if !reflect.DeepEqual(counts, want) {
+		t.Fatalf("expected %v, got %v", want, counts)
+	}
+
+	untrimmed, err := newTestCounter(fetcher, validator, WithWordRegex(whitespace)).
+		CountAllWords(context.Background(), urlChan("a"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(untrimmed) != 0 {
+		t.Fatalf("expected punctuated tokens to be dropped without trimming, got %v", untrimmed)
+	}
+}
+
+func TestWithNumericPolicy(t *testing.T) {
+	fetcher := staticFetcher{"a": "2024 mp3 hello 2024 ½"}
+	validator := newSetValidator("2024", "mp3", "hello", "½")
+
+	tests := []struct {
+		name   string
+		policy NumericPolicy
+		want   map[string]int
+	}{
+		{name: "include", policy: NumericInclude, want: map[string]int{"2024": 2, "mp3": 1, "hello": 1, "½": 1}},
+		{name: "exclude", policy: NumericExclude, want: map[string]int{"hello": 1}},
+		{name: "alpha only", policy: NumericAlphaOnly, want: map[string]int{"mp3": 1, "hello": 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			counts, err := newTestCounter(fetcher, validator, WithNumericPolicy(tt.policy)).
+				CountAllWords(context.Background(), urlChan("a"))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(counts, tt.want) {
+				t.Fatalf("expected %v, got %v", tt.want, counts)
+			}
+		})
+	}
+}
+
+func TestNumericPolicyBreaksNGrams(t *testing.T) {
+	fetcher := staticFetcher{"a": "hello 2024 world hello world"}
+	validator := newSetValidator("hello", "2024", "world")
+
+	counts, err := newTestCounter(fetcher, validator, WithNGramSize(2), WithNumericPolicy(NumericExclude)).
+		CountAllWords(context.Background(), urlChan("a"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := map[string]int{"hello world": 1, "world hello": 1}
+	if !reflect.DeepEqual(counts, want) {
+		t.Fatalf("expected %v, got %v", want, counts)
+	}
+}
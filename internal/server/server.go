@@ -0,0 +1,100 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/shoresh319/firefly/internal/app"
+	"github.com/shoresh319/firefly/internal/handlers"
+	"github.com/shoresh319/firefly/internal/logging"
+	"github.com/shoresh319/firefly/internal/metrics"
+	"github.com/shoresh319/firefly/internal/processing"
+	"github.com/shoresh319/firefly/internal/wordbank"
+)
+
+// Config holds the dependencies served over HTTP.
+type Config struct {
+	Addr      string
+	Fetcher   processing.ArticleFetcher
+	Validator processing.WordValidator
+	Logger    logging.Logger
+	// Metrics are registered with a dedicated registry exposed on /metrics.
+	// The same instance should be passed to the Source so fetches are recorded.
+	Metrics *metrics.Metrics
+	// WordBankPath enables POST /wordbank/reload, which rereads the bank from
+	// this path and replaces Validator with one built from WordBankOptions.
+	WordBankPath    string
+	WordBankOptions []wordbank.ValidatorOption
+	// HealthCheckURL, when set, is fetched through Fetcher by every /healthz
+	// request so the probe fails while article sources are unreachable.
+	HealthCheckURL string
+	// HealthChecks are extra readiness checks reported by /healthz.
+	HealthChecks map[string]handlers.HealthCheck
+	// RuntimeConfig, when set, is reported without its secrets by GET /config,
+	// typically App.Config so that defaults show.
+	RuntimeConfig *app.Config
+}
+
+// New builds an http.Server exposing the firefly endpoints.
+func New(cfg Config) (*http.Server, error) {
+	if cfg.Logger == nil {
+		cfg.Logger = logging.Default()
+	}
+	if cfg.Metrics == nil {
+		cfg.Metrics = metrics.New()
+	}
+
+	registry := prometheus.NewRegistry()
+	if err := cfg.Metrics.Register(registry); err != nil {
+		return nil, fmt.Errorf("register metrics: %w", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		_, _ = w.Write([]byte("firefly\n"))
+	})
+	validator := cfg.Validator
+	if cfg.WordBankPath != "" {
+		store := handlers.NewValidatorStore(cfg.Validator)
+		validator = store
+		mux.Handle("/wordbank/reload", handlers.NewWordBankReloadHandler(store, cfg.WordBankPath, cfg.Logger, cfg.WordBankOptions...))
+	}
+
+	checks := map[string]handlers.HealthCheck{"wordbank": handlers.WordBankCheck(validator)}
+	if cfg.HealthCheckURL != "" {
+		checks["fetch"] = handlers.FetchCheck(cfg.Fetcher, cfg.HealthCheckURL)
+	}
+	for name, check := range cfg.HealthChecks {
+		checks[name] = check
+	}
+
+	mux.Handle("/healthz", handlers.NewHealthHandler(checks))
+	mux.HandleFunc("/livez", handlers.Health)
+	mux.Handle("/count", handlers.NewCountHandler(cfg.Fetcher, validator, cfg.Logger, processing.WithMetrics(cfg.Metrics)))
+	mux.Handle("/count/stream", handlers.NewCountStreamHandler(cfg.Fetcher, validator, cfg.Logger, processing.WithMetrics(cfg.Metrics)))
+	jobs := handlers.NewCountJobs(cfg.Fetcher, validator, cfg.Logger, processing.WithMetrics(cfg.Metrics))
+	mux.HandleFunc("/count/async", jobs.ServeSubmit)
+	mux.HandleFunc("/count/result/{jobID}", jobs.ServeResult)
+	mux.HandleFunc("/count/{jobID}", jobs.ServeCancel)
+	if cfg.RuntimeConfig != nil {
+		mux.Handle("/config", handlers.NewConfigHandler(*cfg.RuntimeConfig))
+	}
+	mux.Handle("/metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+
+	srv := &http.Server{
+		Addr:              cfg.Addr,
+		Handler:           handlers.RequestID(mux),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	srv.RegisterOnShutdown(jobs.Close)
+	return srv, nil
+}
@@ -0,0 +1,151 @@
+package server
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/shoresh319/firefly/internal/app"
+	"github.com/shoresh319/firefly/internal/articles"
+	"github.com/shoresh319/firefly/internal/logging"
+	"github.com/shoresh319/firefly/internal/metrics"
+	"github.com/shoresh319/firefly/internal/wordbank"
+)
+
+func TestMetricsEndpointReflectsFetches(t *testing.T) {
+	articleSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("<html><body><p>firefly glow</p></body></html>"))
+	}))
+	defer articleSrv.Close()
+
+	m := metrics.New()
+	source := articles.NewSource(articles.SourceConfig{Logger: logging.Nop(), Metrics: m})
+	srv, err := New(Config{
+		Fetcher:   source,
+		Validator: wordbank.NewValidator(wordbank.NewBank(map[string]struct{}{"firefly": {}})),
+		Logger:    logging.Nop(),
+		Metrics:   m,
+	})
+	if err != nil {
+		t.Fatalf("new server: %v", err)
+	}
+
+	body := fmt.Sprintf(`{"urls": [%q]}`, articleSrv.URL)
+	countRR := httptest.NewRecorder()
+	srv.Handler.ServeHTTP(countRR, httptest.NewRequest(http.MethodPost, "/count", strings.NewReader(body)))
+	if countRR.Code != http.StatusOK {
+		t.Fatalf("expected count status %d, got %d", http.StatusOK, countRR.Code)
+	}
+
+	metricsRR := httptest.NewRecorder()
+	srv.Handler.ServeHTTP(metricsRR, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if metricsRR.Code != http.StatusOK {
+		t.Fatalf("expected metrics status %d, got %d", http.StatusOK, metricsRR.Code)
+	}
+
+	scraped, _ := io.ReadAll(metricsRR.Body)
+	for _, want := range []string{
+		"firefly_articles_fetched_total 1",
+		"firefly_articles_failed_total 0",
+		`firefly_fetch_duration_seconds_count{domain="127.0.0.1",status="200"} 1`,
+	} {
+		if !strings.Contains(string(scraped), want) {
+			t.Fatalf("expected metrics to contain %q, got:\n%s", want, scraped)
+		}
+	}
+}
+
+func TestHealthzReportsUnreachableFetch(t *testing.T) {
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	down.Close()
+
+	srv, err := New(Config{
+		Fetcher:        articles.NewSource(articles.SourceConfig{Logger: logging.Nop()}),
+		Validator:      wordbank.NewValidator(wordbank.NewBank(map[string]struct{}{"firefly": {}})),
+		Logger:         logging.Nop(),
+		HealthCheckURL: down.URL,
+	})
+	if err != nil {
+		t.Fatalf("new server: %v", err)
+	}
+
+	rr := httptest.NewRecorder()
+	srv.Handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if rr.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected healthz status %d, got %d", http.StatusServiceUnavailable, rr.Code)
+	}
+	if body := rr.Body.String(); !strings.Contains(body, `"fetch"`) || !strings.Contains(body, `"wordbank":"ok"`) {
+		t.Fatalf("expected failed fetch check and ok wordbank check, got %s", body)
+	}
+
+	rr = httptest.NewRecorder()
+	srv.Handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/livez", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected livez status %d, got %d", http.StatusOK, rr.Code)
+	}
+}
+
+func TestCountPropagatesRequestID(t *testing.T) {
+	var fetchIDs []string
+	articleSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetchIDs = append(fetchIDs, r.Header.Get("X-Request-Id"))
+		_, _ = w.Write([]byte("<html><body><p>firefly glow</p></body></html>"))
+	}))
+	defer articleSrv.Close()
+
+	srv, err := New(Config{
+		Fetcher:   articles.NewSource(articles.SourceConfig{Logger: logging.Nop()}),
+		Validator: wordbank.NewValidator(wordbank.NewBank(map[string]struct{}{"firefly": {}})),
+		Logger:    logging.Nop(),
+	})
+	if err != nil {
+		t.Fatalf("new server: %v", err)
+	}
+
+	for _, sent := range []string{"", "client-id-42"} {
+		fetchIDs = nil
+		req := httptest.NewRequest(http.MethodPost, "/count", strings.NewReader(fmt.Sprintf(`{"urls": [%q]}`, articleSrv.URL)))
+		if sent != "" {
+			req.Header.Set("X-Request-Id", sent)
+		}
+		rr := httptest.NewRecorder()
+		srv.Handler.ServeHTTP(rr, req)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected count status %d, got %d", http.StatusOK, rr.Code)
+		}
+
+		id := rr.Header().Get("X-Request-Id")
+		if id == "" || (sent != "" && id != sent) {
+			t.Fatalf("expected response request ID %q, got %q", sent, id)
+		}
+		if len(fetchIDs) != 1 || fetchIDs[0] != id {
+			t.Fatalf("expected the fetch to carry request ID %q, got %v", id, fetchIDs)
+		}
+	}
+}
+
+func TestConfigEndpointOnlyWhenConfigured(t *testing.T) {
+	validator := wordbank.NewValidator(wordbank.NewBank(map[string]struct{}{"firefly": {}}))
+	srv, err := New(Config{Validator: validator, Logger: logging.Nop()})
+	if err != nil {
+		t.Fatalf("new server: %v", err)
+	}
+	rr := httptest.NewRecorder()
+	srv.Handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/config", nil))
+	if rr.Code != http.StatusNotFound {
+		t.Fatalf("expected status %d without RuntimeConfig, got %d", http.StatusNotFound, rr.Code)
+	}
+
+	srv, err = New(Config{Validator: validator, Logger: logging.Nop(), RuntimeConfig: &app.Config{RetryMax: 4}})
+	if err != nil {
+		t.Fatalf("new server: %v", err)
+	}
+	rr = httptest.NewRecorder()
+	srv.Handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/config", nil))
+	if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), `"retryMax":4`) {
+		t.Fatalf("expected the runtime config, got %d: %s", rr.Code, rr.Body.String())
+	}
+}
@@ -0,0 +1,146 @@
+package wordbank
+
+import "sync"
+
+// Bank is a set of words that can be changed while validators use it. All
+// methods are safe for concurrent use.
+//
+// Once a Validator is built on a Bank, the bank applies that validator's case
+// folding and normalization to every entry and to the arguments of Add, Remove
+// and Contains, so a bank should only be shared by validators configured alike.
+type Bank struct {
+	mu      sync.RWMutex
+	words   map[string]struct{}
+	weights map[string]int // nil for an unweighted bank
+	fold    func(string) string
+}
+
+// NewBank returns a Bank holding words, such as the set returned by Load. The
+// bank takes ownership of the map.
+func NewBank(words map[string]struct{}) *Bank {
+	if words == nil {
+		words = make(map[string]struct{})
+	}
+	return &Bank{words: words}
+}
+
+// NewWeightedBank returns a Bank holding the words of weights, such as the map
+// returned by LoadWeighted. Each occurrence of a word counts as its weight when
+// validators built on the bank are used by a processing.Counter. Words added
+// later with Add have weight 1. The bank takes ownership of the map.
+func NewWeightedBank(weights map[string]int) *Bank {
+	words := make(map[string]struct{}, len(weights))
+	for w := range weights {
+		words[w] = struct{}{}
+	}
+	if weights == nil {
+		weights = make(map[string]int)
+	}
+	return &Bank{words: words, weights: weights}
+}
+
+// Add inserts word. Adding a word that is already present has no effect.
+func (b *Bank) Add(word string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.words[b.canonical(word)] = struct{}{}
+}
+
+// Remove deletes word. Removing a missing word has no effect.
+func (b *Bank) Remove(word string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	word = b.canonical(word)
+	delete(b.words, word)
+	delete(b.weights, word)
+}
+
+// Weight returns the weight of word: 1 for words of an unweighted bank or
+// added with Add, and 0 for words not in the bank.
+func (b *Bank) Weight(word string) int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.weightLocked(b.canonical(word))
+}
+
+// Contains reports whether word is in the bank.
+func (b *Bank) Contains(word string) bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	_, ok := b.words[b.canonical(word)]
+	return ok
+}
+
+// Len returns the number of words in the bank.
+func (b *Bank) Len() int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return len(b.words)
+}
+
+// contains looks up a word that is already in canonical form.
+func (b *Bank) contains(word string) bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	_, ok := b.words[word]
+	return ok
+}
+
+// weight looks up the weight of a word that is already in canonical form.
+func (b *Bank) weight(word string) int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.weightLocked(word)
+}
+
+func (b *Bank) weightLocked(word string) int {
+	if _, ok := b.words[word]; !ok {
+		return 0
+	}
+	if n, ok := b.weights[word]; ok {
+		return n
+	}
+	return 1
+}
+
+// setFold makes fold the bank's canonical form, rewriting existing entries.
+// The map is only replaced when some entry actually changes, which is the
+// uncommon case for banks produced by Load.
+func (b *Bank) setFold(fold func(string) string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.fold = fold
+
+	var rewritten map[string]struct{}
+	for w := range b.words {
+		if fold(w) != w {
+			rewritten = make(map[string]struct{}, len(b.words))
+			break
+		}
+	}
+	if rewritten == nil {
+		return
+	}
+	for w := range b.words {
+		rewritten[fold(w)] = struct{}{}
+	}
+	b.words = rewritten
+
+	if b.weights == nil {
+		return
+	}
+	// Entries that fold together keep the largest weight.
+	weights := make(map[string]int, len(b.weights))
+	for w, n := range b.weights {
+		folded := fold(w)
+		weights[folded] = max(weights[folded], n)
+	}
+	b.weights = weights
+}
+
+func (b *Bank) canonical(word string) string {
+	if b.fold == nil {
+		return word
+	}
+	return b.fold(word)
+}
@@ -0,0 +1,83 @@
+package wordbank
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestBankAddRemoveIdempotent(t *testing.T) {
+	b := testBank("apple")
+
+	b.Add("banana")
+	b.Add("banana")
+	if !b.Contains("banana") || b.Len() != 2 {
+		t.Fatalf("expected apple and banana, got %d words", b.Len())
+	}
+
+	b.Remove("apple")
+	b.Remove("apple")
+	b.Remove("missing")
+	if b.Contains("apple") || b.Len() != 1 {
+		t.Fatalf("expected only banana, got %d words", b.Len())
+	}
+}
+
+func TestValidatorSeesBankChanges(t *testing.T) {
+	b := testBank("apple")
+	v := NewValidator(b, WithCaseInsensitive(true))
+
+	if v.Validate("banana") {
+		t.Fatalf("expected %q to be rejected before Add", "banana")
+	}
+	b.Add("Banana")
+	if !v.Validate("banana") || !v.Validate("BANANA") {
+		t.Fatalf("expected added word to be accepted in any case")
+	}
+	b.Remove("APPLE")
+	if v.Validate("apple") {
+		t.Fatalf("expected removed word to be rejected")
+	}
+}
+
+func TestBankConcurrentMutationAndValidation(t *testing.T) {
+	b := testBank("anchor")
+	v := NewValidator(b)
+
+	var wg sync.WaitGroup
+	for w := 0; w < 4; w++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 500; i++ {
+				word := fmt.Sprintf("word%d", i%50)
+				b.Add(word)
+				b.Remove(word)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 500; i++ {
+				v.Validate(fmt.Sprintf("word%d", i%50))
+				if !v.Validate("anchor") {
+					t.Errorf("expected untouched word to stay valid")
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestWeightedBankRemoveAndAdd(t *testing.T) {
+	b := NewWeightedBank(map[string]int{"apple": 4})
+
+	if b.Weight("apple") != 4 || b.Weight("missing") != 0 {
+		t.Fatalf("expected apple=4 and missing=0, got %d and %d", b.Weight("apple"), b.Weight("missing"))
+	}
+	b.Remove("apple")
+	b.Add("apple")
+	if b.Weight("apple") != 1 {
+		t.Fatalf("expected re-added word to have weight 1, got %d", b.Weight("apple"))
+	}
+}
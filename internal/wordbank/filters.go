@@ -0,0 +1,93 @@
+package wordbank
+
+import "strings"
+
+// WordValidator reports whether a token should be counted. It matches
+// processing.WordValidator so validators from this package compose freely.
+type WordValidator interface {
+	Validate(word string) bool
+}
+
+// StopWordFilter rejects tokens found in its stop-word set and accepts
+// everything else.
+type StopWordFilter struct {
+	stopWords map[string]struct{}
+}
+
+// NewStopWordFilter constructs a filter rejecting the supplied stop words.
+func NewStopWordFilter(stopWords map[string]struct{}) *StopWordFilter {
+	return &StopWordFilter{stopWords: stopWords}
+}
+
+// Validate returns false when word is a stop word.
+func (f *StopWordFilter) Validate(word string) bool {
+	_, stop := f.stopWords[word]
+	return !stop
+}
+
+// AndValidator accepts a token only when every validator accepts it. Validators
+// run in order and evaluation stops at the first rejection, so cheaper checks
+// should come first.
+type AndValidator []WordValidator
+
+// Validate returns true when all validators accept word.
+func (a AndValidator) Validate(word string) bool {
+	for _, v := range a {
+		if !v.Validate(word) {
+			return false
+		}
+	}
+	return true
+}
+
+// OrValidator accepts a token when any validator accepts it. Validators run in
+// order and evaluation stops at the first acceptance; an empty OrValidator
+// accepts nothing.
+type OrValidator []WordValidator
+
+// Validate returns true when at least one validator accepts word.
+func (o OrValidator) Validate(word string) bool {
+	for _, v := range o {
+		if v.Validate(word) {
+			return true
+		}
+	}
+	return false
+}
+
+// NewSuffixValidator accepts tokens that end in suffix and are longer than
+// it, so "ing" or "-ing" accepts "running" but neither "ran" nor "ing" itself.
+// Combine it with a bank validator through AndValidator or OrValidator.
+func NewSuffixValidator(suffix string) WordValidator {
+	suffix = strings.TrimPrefix(suffix, "-")
+	return FuncValidator(func(word string) bool {
+		return len(word) > len(suffix) && strings.HasSuffix(word, suffix)
+	})
+}
+
+// NewPrefixValidator accepts tokens that start with prefix and are longer
+// than it, so "un" or "un-" accepts "undo" but not "do".
+func NewPrefixValidator(prefix string) WordValidator {
+	prefix = strings.TrimSuffix(prefix, "-")
+	return FuncValidator(func(word string) bool {
+		return len(word) > len(prefix) && strings.HasPrefix(word, prefix)
+	})
+}
+
+// FuncValidator adapts an ordinary predicate to the WordValidator interface.
+type FuncValidator func(word string) bool
+
+// Validate returns fn(word).
+func (fn FuncValidator) Validate(word string) bool {
+	return fn(word)
+}
+
+// NewFuncValidator returns a validator backed by fn, for rules the word bank
+// cannot express such as capitalization or script checks. A nil fn accepts
+// nothing.
+func NewFuncValidator(fn func(word string) bool) WordValidator {
+	if fn == nil {
+		return FuncValidator(func(string) bool { return false })
+	}
+	return FuncValidator(fn)
+}
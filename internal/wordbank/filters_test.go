@@ -0,0 +1,144 @@
+package wordbank
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/shoresh319/firefly/internal/logging"
+	"github.com/shoresh319/firefly/internal/processing"
+)
+
+func TestStopWordChain(t *testing.T) {
+	chain := AndValidator{
+		NewValidator(testBank("the", "and", "elephant", "giraffe")),
+		NewStopWordFilter(bank("the", "and", "that")),
+	}
+
+	tests := map[string]bool{
+		"the":      false, // in bank but a stop word
+		"and":      false,
+		"elephant": true,
+		"giraffe":  true,
+		"zebra":    false, // not in bank
+	}
+	for word, want := range tests {
+		if got := chain.Validate(word); got != want {
+			t.Fatalf("Validate(%q): expected %v, got %v", word, want, got)
+		}
+	}
+}
+
+type textFetcher map[string]string
+
+func (f textFetcher) Fetch(_ context.Context, url string) (string, error) {
+	text, ok := f[url]
+	if !ok {
+		return "", fmt.Errorf("no article for %s", url)
+	}
+	return text, nil
+}
+
+func isPalindrome(word string) bool {
+	runes := []rune(word)
+	for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+		if runes[i] != runes[j] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestFuncValidatorWithCounter(t *testing.T) {
+	urls := make(chan string, 1)
+	urls <- "article"
+	close(urls)
+
+	fetcher := textFetcher{"article": "level kayak river noon level racecar stone"}
+	counter := processing.NewCounter(fetcher, NewFuncValidator(isPalindrome), processing.WithLogger(logging.Nop()))
+
+	counts, err := counter.CountAllWords(context.Background(), urls)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := map[string]int{"level": 2, "kayak": 1, "noon": 1, "racecar": 1}
+	if len(counts) != len(want) {
+		t.Fatalf("expected %v, got %v", want, counts)
+	}
+	for word, n := range want {
+		if counts[word] != n {
+			t.Fatalf("expected %s=%d, got %d", word, n, counts[word])
+		}
+	}
+}
+
+func TestValidatorCountsAccentedWords(t *testing.T) {
+	urls := make(chan string, 1)
+	urls <- "article"
+	close(urls)
+
+	fetcher := textFetcher{"article": "Un café naïve, café au lait; straße über alles"}
+	validator := NewValidator(testBank("café", "naïve", "straße", "über"))
+	counter := processing.NewCounter(fetcher, validator, processing.WithLogger(logging.Nop()))
+
+	counts, err := counter.CountAllWords(context.Background(), urls)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := map[string]int{"café": 2, "naïve": 1, "straße": 1, "über": 1}
+	if len(counts) != len(want) {
+		t.Fatalf("expected %v, got %v", want, counts)
+	}
+	for word, n := range want {
+		if counts[word] != n {
+			t.Fatalf("expected %s=%d, got %d", word, n, counts[word])
+		}
+	}
+}
+
+func TestNewFuncValidatorNil(t *testing.T) {
+	if NewFuncValidator(nil).Validate("anything") {
+		t.Fatal("expected nil predicate to reject every word")
+	}
+}
+
+func TestSuffixValidator(t *testing.T) {
+	v := NewSuffixValidator("-ing")
+	tests := map[string]bool{
+		"running": true,
+		"ran":     false,
+		"ing":     false, // the suffix alone is not a derived word
+	}
+	for word, want := range tests {
+		if got := v.Validate(word); got != want {
+			t.Fatalf("Validate(%q): expected %v, got %v", word, want, got)
+		}
+	}
+}
+
+func TestAffixValidatorsCompose(t *testing.T) {
+	affixes := OrValidator{NewSuffixValidator("ing"), NewPrefixValidator("un-")}
+	chain := AndValidator{
+		NewValidator(testBank("running", "undo", "ran", "under", "sing")),
+		affixes,
+	}
+
+	tests := map[string]bool{
+		"running": true,
+		"undo":    true,
+		"under":   true,
+		"sing":    true,
+		"ran":     false, // in bank, no affix
+		"jumping": false, // affix, not in bank
+	}
+	for word, want := range tests {
+		if got := chain.Validate(word); got != want {
+			t.Fatalf("Validate(%q): expected %v, got %v", word, want, got)
+		}
+	}
+	if (OrValidator{}).Validate("running") {
+		t.Fatal("expected an empty OrValidator to reject every word")
+	}
+}
@@ -2,30 +2,347 @@ package wordbank
 
 import (
 	"bufio"
+	"compress/gzip"
 	"context"
+	"encoding/csv"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"regexp"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
 )
 
 // Validator checks whether a token is considered a valid word and exists in the
 // previously loaded word bank.
 type Validator struct {
-	words map[string]struct{}
+	bank *Bank
+
+	wordMatcher     *regexp.Regexp
+	caseInsensitive bool
+	normalize       bool
+	minLength       int
+	maxLength       int
+}
+
+const defaultMinLength = 3
+
+// ValidatorOption configures a Validator.
+type ValidatorOption func(*Validator)
 
-	wordMatcher *regexp.Regexp
+// WithCaseInsensitive makes the validator ignore letter case by lowercasing both
+// the word bank entries and incoming tokens. The default is case-sensitive.
+func WithCaseInsensitive(enabled bool) ValidatorOption {
+	return func(v *Validator) {
+		v.caseInsensitive = enabled
+	}
+}
+
+// WithNormalization controls whether bank entries and tokens are normalized to
+// Unicode NFC before comparison, so precomposed and decomposed spellings of
+// the same word match. It is enabled by default.
+func WithNormalization(enabled bool) ValidatorOption {
+	return func(v *Validator) {
+		v.normalize = enabled
+	}
+}
+
+// LoadOption configures how a word bank is loaded.
+type LoadOption func(*loadConfig)
+
+type loadConfig struct {
+	normalize   bool
+	allowEmpty  bool
+	progress    *loadProgress
+	concurrency int
+}
+
+// ErrEmptyWordBank is returned by Load and LoadFromReader when the input holds
+// no words, since every token would then be rejected.
+var ErrEmptyWordBank = errors.New("word bank is empty")
+
+// WithAllowEmpty makes loading an empty word bank succeed instead of failing
+// with ErrEmptyWordBank.
+func WithAllowEmpty(allow bool) LoadOption {
+	return func(c *loadConfig) {
+		c.allowEmpty = allow
+	}
+}
+
+// WithLoadNormalization controls whether loaded words are stored in Unicode
+// NFC form. It is enabled by default.
+func WithLoadNormalization(enabled bool) LoadOption {
+	return func(c *loadConfig) {
+		c.normalize = enabled
+	}
+}
+
+// WithLoadProgress calls fn with the number of lines read so far every time
+// another every lines have been read, so loading a huge bank can report
+// progress. Loading several files counts their lines together; with
+// WithLoadConcurrency fn may be called from several goroutines, though never
+// at the same time.
+func WithLoadProgress(every int, fn func(lines int)) LoadOption {
+	return func(c *loadConfig) {
+		if every > 0 && fn != nil {
+			c.progress = &loadProgress{every: int64(every), fn: fn}
+		}
+	}
+}
+
+// WithLoadConcurrency lets LoadAll and its variants read up to n files at
+// once, which speeds up loading a bank sharded across several files. The
+// merged result is the same as reading them in order. The first failure
+// cancels the files still loading.
+func WithLoadConcurrency(n int) LoadOption {
+	return func(c *loadConfig) {
+		c.concurrency = n
+	}
+}
+
+// loadProgress counts the lines read for WithLoadProgress.
+type loadProgress struct {
+	every int64
+	fn    func(lines int)
+	lines atomic.Int64
+	mu    sync.Mutex
+}
+
+// line records a line read. A nil *loadProgress does nothing.
+func (p *loadProgress) line() {
+	if p == nil {
+		return
+	}
+	if n := p.lines.Add(1); n%p.every == 0 {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		p.fn(int(n))
+	}
 }
 
 // Load reads the word bank from the supplied file path and returns it as a set.
-func Load(ctx context.Context, filePath string) (map[string]struct{}, error) {
+// Gzip-compressed banks, recognised by a .gz suffix or the gzip magic number,
+// are decompressed transparently. CSV banks, recognised by a .csv or .csv.gz
+// suffix, are read with LoadWeightedFromReader and their weights dropped.
+func Load(ctx context.Context, filePath string, opts ...LoadOption) (map[string]struct{}, error) {
+	if !isCSV(filePath) {
+		var words map[string]struct{}
+		err := openBank(filePath, func(r io.Reader) (err error) {
+			words, err = LoadFromReader(ctx, r, opts...)
+			return err
+		})
+		return words, err
+	}
+
+	weights, err := LoadWeighted(ctx, filePath, opts...)
+	if err != nil {
+		return nil, err
+	}
+	words := make(map[string]struct{}, len(weights))
+	for w := range weights {
+		words[w] = struct{}{}
+	}
+	return words, nil
+}
+
+// LoadWeighted reads the word bank at filePath as a map from word to weight,
+// for use with NewWeightedBank. CSV banks, recognised by a .csv or .csv.gz
+// suffix, are parsed by LoadWeightedFromReader; words of plain banks have
+// weight 1. Gzip compression is handled as in Load.
+func LoadWeighted(ctx context.Context, filePath string, opts ...LoadOption) (map[string]int, error) {
+	var weights map[string]int
+	err := openBank(filePath, func(r io.Reader) error {
+		if isCSV(filePath) {
+			var err error
+			weights, err = LoadWeightedFromReader(ctx, r, opts...)
+			return err
+		}
+		words, err := LoadFromReader(ctx, r, opts...)
+		if err != nil {
+			return err
+		}
+		weights = make(map[string]int, len(words))
+		for w := range words {
+			weights[w] = 1
+		}
+		return nil
+	})
+	return weights, err
+}
+
+// openBank opens the word bank at filePath, decompressing it if needed, and
+// passes it to read.
+func openBank(filePath string, read func(io.Reader) error) error {
 	f, err := os.Open(filePath)
 	if err != nil {
-		return nil, fmt.Errorf("open word bank: %w", err)
+		return fmt.Errorf("open word bank: %w", err)
 	}
 	defer f.Close()
 
-	scanner := bufio.NewScanner(f)
+	br := bufio.NewReader(f)
+	var r io.Reader = br
+	if isGzip(br, filePath) {
+		zr, err := gzip.NewReader(br)
+		if err != nil {
+			return fmt.Errorf("open word bank: gzip: %w", err)
+		}
+		defer zr.Close()
+		r = zr
+	}
+
+	return read(r)
+}
+
+// isCSV reports whether the bank at filePath holds word,weight records.
+func isCSV(filePath string) bool {
+	return strings.HasSuffix(strings.TrimSuffix(filePath, ".gz"), ".csv")
+}
+
+// LoadStats describes the word banks merged by LoadAllWithStats.
+type LoadStats struct {
+	Files  int // Word bank files read
+	Total  int // Words read across all files, counting a word once per file it appears in
+	Unique int // Distinct words in the merged set
+}
+
+// LoadAll reads several word bank files, such as domain-specific lists, and
+// returns the union of their words.
+func LoadAll(ctx context.Context, paths ...string) (map[string]struct{}, error) {
+	words, _, err := LoadAllWithStats(ctx, paths)
+	return words, err
+}
+
+// LoadAllWithStats behaves like LoadAll with the supplied options applied, and
+// also reports how many words were read and how many remained after merging.
+// Individual files may be empty, but the merged set fails with
+// ErrEmptyWordBank when it has no words unless WithAllowEmpty is given.
+func LoadAllWithStats(ctx context.Context, paths []string, opts ...LoadOption) (map[string]struct{}, LoadStats, error) {
+	weights, stats, err := LoadAllWeightedWithStats(ctx, paths, opts...)
+	if err != nil {
+		return nil, LoadStats{}, err
+	}
+	words := make(map[string]struct{}, len(weights))
+	for w := range weights {
+		words[w] = struct{}{}
+	}
+	return words, stats, nil
+}
+
+// LoadAllWeightedWithStats is LoadAllWithStats for LoadWeighted banks. A word
+// found in several files keeps its largest weight.
+func LoadAllWeightedWithStats(ctx context.Context, paths []string, opts ...LoadOption) (map[string]int, LoadStats, error) {
+	cfg := loadConfig{normalize: true}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	// The files share cfg's progress counter rather than counting one each.
+	fileOpts := append(append([]LoadOption(nil), opts...), WithAllowEmpty(true), func(c *loadConfig) { c.progress = cfg.progress })
+
+	banks, err := loadFiles(ctx, paths, cfg.concurrency, fileOpts)
+	if err != nil {
+		return nil, LoadStats{}, err
+	}
+
+	var stats LoadStats
+	merged := make(map[string]int)
+	for _, weights := range banks {
+		stats.Files++
+		stats.Total += len(weights)
+		for w, n := range weights {
+			merged[w] = max(merged[w], n)
+		}
+	}
+	stats.Unique = len(merged)
+
+	if stats.Unique == 0 && !cfg.allowEmpty {
+		return nil, LoadStats{}, ErrEmptyWordBank
+	}
+	return merged, stats, nil
+}
+
+// loadFiles reads each of paths with LoadWeighted, up to concurrency at a
+// time, and returns their banks in the order of paths.
+func loadFiles(ctx context.Context, paths []string, concurrency int, opts []LoadOption) ([]map[string]int, error) {
+	banks := make([]map[string]int, len(paths))
+	if concurrency <= 1 {
+		for i, path := range paths {
+			weights, err := LoadWeighted(ctx, path, opts...)
+			if err != nil {
+				return nil, fmt.Errorf("load word bank %s: %w", path, err)
+			}
+			banks[i] = weights
+		}
+		return banks, nil
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+	slots := make(chan struct{}, concurrency)
+	for i, path := range paths {
+		select {
+		case <-ctx.Done():
+		case slots <- struct{}{}:
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer func() { <-slots }()
+				weights, err := LoadWeighted(ctx, path, opts...)
+				if err != nil {
+					mu.Lock()
+					if firstErr == nil {
+						firstErr = fmt.Errorf("load word bank %s: %w", path, err)
+						cancel()
+					}
+					mu.Unlock()
+					return
+				}
+				banks[i] = weights
+			}()
+		}
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		// Cancelled by the caller before every file was started.
+		return nil, err
+	}
+	return banks, nil
+}
+
+// isGzip reports whether the bank at filePath should be decompressed.
+func isGzip(br *bufio.Reader, filePath string) bool {
+	if strings.HasSuffix(filePath, ".gz") {
+		return true
+	}
+	magic, _ := br.Peek(2)
+	return len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b
+}
+
+// LoadFromReader reads a newline separated word bank from r and returns it as a
+// set. Blank lines and surrounding whitespace are ignored, and words are
+// normalized to NFC unless disabled. Input without any words fails with
+// ErrEmptyWordBank unless WithAllowEmpty is given.
+func LoadFromReader(ctx context.Context, r io.Reader, opts ...LoadOption) (map[string]struct{}, error) {
+	cfg := loadConfig{normalize: true}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	scanner := bufio.NewScanner(r)
 	scanner.Buffer(make([]byte, 0, 4096), 1024*1024)
 
 	words := make(map[string]struct{})
@@ -36,10 +353,14 @@ func Load(ctx context.Context, filePath string) (map[string]struct{}, error) {
 		default:
 		}
 
+		cfg.progress.line()
 		w := strings.TrimSpace(scanner.Text())
 		if w == "" {
 			continue
 		}
+		if cfg.normalize {
+			w = norm.NFC.String(w)
+		}
 		words[w] = struct{}{}
 	}
 
@@ -47,24 +368,164 @@ func Load(ctx context.Context, filePath string) (map[string]struct{}, error) {
 		return nil, fmt.Errorf("scan word bank: %w", err)
 	}
 
+	if len(words) == 0 && !cfg.allowEmpty {
+		return nil, ErrEmptyWordBank
+	}
+
 	return words, nil
 }
 
-// NewValidator constructs a validator for the supplied in-memory word bank.
-func NewValidator(words map[string]struct{}) *Validator {
-	return &Validator{
-		words:       words,
-		wordMatcher: regexp.MustCompile(`^\w{3,}$`),
+// LoadWeightedFromReader reads a CSV word bank of word,weight records from r.
+// The weight column may be omitted for weight 1; otherwise it must be a
+// positive integer. Lines starting with # are comments, and a leading
+// word,weight header is skipped. A word listed twice keeps its largest weight.
+// Words are normalized as in LoadFromReader, and input without any words
+// fails with ErrEmptyWordBank unless WithAllowEmpty is given.
+func LoadWeightedFromReader(ctx context.Context, r io.Reader, opts ...LoadOption) (map[string]int, error) {
+	cfg := loadConfig{normalize: true}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	cr := csv.NewReader(r)
+	cr.Comment = '#'
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	weights := make(map[string]int)
+	for first := true; ; first = false {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+		}
+
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read word bank CSV: %w", err)
+		}
+		cfg.progress.line()
+		if len(record) > 2 {
+			line, _ := cr.FieldPos(0)
+			return nil, fmt.Errorf("word bank CSV line %d: expected word,weight, got %d fields", line, len(record))
+		}
+
+		w := strings.TrimSpace(record[0])
+		if first && len(record) == 2 && strings.EqualFold(w, "word") && strings.EqualFold(strings.TrimSpace(record[1]), "weight") {
+			continue
+		}
+		if w == "" {
+			continue
+		}
+		weight := 1
+		if len(record) == 2 {
+			weight, err = strconv.Atoi(strings.TrimSpace(record[1]))
+			if err != nil || weight < 1 {
+				line, _ := cr.FieldPos(1)
+				return nil, fmt.Errorf("word bank CSV line %d: invalid weight %q for %q", line, record[1], w)
+			}
+		}
+		if cfg.normalize {
+			w = norm.NFC.String(w)
+		}
+		weights[w] = max(weights[w], weight)
+	}
+
+	if len(weights) == 0 && !cfg.allowEmpty {
+		return nil, ErrEmptyWordBank
+	}
+
+	return weights, nil
+}
+
+// WithMinLength sets the minimum word length in runes (default: 3).
+func WithMinLength(n int) ValidatorOption {
+	return func(v *Validator) {
+		if n >= 0 {
+			v.minLength = n
+		}
 	}
 }
 
+// WithMaxLength sets the maximum word length in runes. Zero means no limit,
+// which is the default.
+func WithMaxLength(n int) ValidatorOption {
+	return func(v *Validator) {
+		if n >= 0 {
+			v.maxLength = n
+		}
+	}
+}
+
+// NewValidator constructs a validator for the supplied word bank, which may
+// keep changing afterwards. Words are runs of letters, combining marks,
+// digits and underscores in any script, optionally joined by single
+// apostrophes or hyphens as in "don't" or "well-known".
+func NewValidator(bank *Bank, opts ...ValidatorOption) *Validator {
+	if bank == nil {
+		bank = NewBank(nil)
+	}
+	validator := &Validator{
+		bank:        bank,
+		wordMatcher: regexp.MustCompile(`^[\p{L}\p{M}\p{N}_]+(?:['’\-][\p{L}\p{M}\p{N}_]+)*$`),
+		normalize:   true,
+		minLength:   defaultMinLength,
+	}
+
+	for _, opt := range opts {
+		opt(validator)
+	}
+
+	if validator.normalize || validator.caseInsensitive {
+		bank.setFold(validator.canonical)
+	}
+
+	return validator
+}
+
+// canonical applies the configured normalization and case folding to word.
+func (v *Validator) canonical(word string) string {
+	if v.normalize {
+		word = norm.NFC.String(word)
+	}
+	if v.caseInsensitive {
+		word = strings.ToLower(word)
+	}
+	return word
+}
+
+// Weight returns how many occurrences a valid word counts for: its weight in
+// a bank built with NewWeightedBank, and 1 otherwise. It implements
+// processing.WordWeigher.
+func (v *Validator) Weight(word string) int {
+	if n := v.bank.weight(v.canonical(word)); n > 0 {
+		return n
+	}
+	return 1
+}
+
 // Validate returns true when the provided token matches the configured word
-// pattern and exists in the word bank.
+// pattern and length bounds and exists in the word bank.
 func (v *Validator) Validate(word string) bool {
+	if v.normalize {
+		word = norm.NFC.String(word)
+	}
+
 	if !v.wordMatcher.MatchString(word) {
 		return false
 	}
 
-	_, ok := v.words[word]
-	return ok
+	length := utf8.RuneCountInString(word)
+	if length < v.minLength || (v.maxLength > 0 && length > v.maxLength) {
+		return false
+	}
+
+	if v.caseInsensitive {
+		word = strings.ToLower(word)
+	}
+
+	return v.bank.contains(word)
 }
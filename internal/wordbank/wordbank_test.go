@@ -0,0 +1,499 @@
+package wordbank
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"testing/iotest"
+	"time"
+)
+
+// testBank returns a Bank holding words.
+func testBank(words ...string) *Bank {
+	return NewBank(bank(words...))
+}
+
+func bank(words ...string) map[string]struct{} {
+	set := make(map[string]struct{}, len(words))
+	for _, w := range words {
+		set[w] = struct{}{}
+	}
+	return set
+}
+
+func TestValidateCaseSensitiveByDefault(t *testing.T) {
+	v := NewValidator(testBank("the", "Apple"))
+
+	tests := map[string]bool{
+		"the":   true,
+		"The":   false,
+		"Apple": true,
+		"apple": false,
+	}
+	for word, want := range tests {
+		if got := v.Validate(word); got != want {
+			t.Fatalf("Validate(%q): expected %v, got %v", word, want, got)
+		}
+	}
+}
+
+func TestValidateCaseInsensitive(t *testing.T) {
+	v := NewValidator(testBank("the", "Apple", "über"), WithCaseInsensitive(true))
+
+	tests := map[string]bool{
+		"the":   true,
+		"The":   true,
+		"THE":   true,
+		"apple": true,
+		"aPPLE": true,
+		"pear":  false,
+		"Über":  true,
+		"über":  true,
+		"ÜBER":  true,
+		"it":    false,
+	}
+	for word, want := range tests {
+		if got := v.Validate(word); got != want {
+			t.Fatalf("Validate(%q): expected %v, got %v", word, want, got)
+		}
+	}
+}
+
+func TestLoadFromReaderEmpty(t *testing.T) {
+	words, err := LoadFromReader(context.Background(), strings.NewReader(""), WithAllowEmpty(true))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(words) != 0 {
+		t.Fatalf("expected empty bank, got %d words", len(words))
+	}
+}
+
+func TestLoadEmptyFileReturnsSentinel(t *testing.T) {
+	for name, content := range map[string]string{"empty": "", "whitespace": "  \n\t\n\n"} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "words.txt")
+			if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+				t.Fatalf("write word bank: %v", err)
+			}
+
+			if _, err := Load(context.Background(), path); !errors.Is(err, ErrEmptyWordBank) {
+				t.Fatalf("expected ErrEmptyWordBank, got %v", err)
+			}
+		})
+	}
+}
+
+func TestLoadFromReaderTrimsWhitespace(t *testing.T) {
+	input := "alpha\n  beta  \n\ngamma\n   \n\t\n"
+
+	words, err := LoadFromReader(context.Background(), strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(words) != 3 {
+		t.Fatalf("expected 3 words, got %d: %v", len(words), words)
+	}
+	for _, w := range []string{"alpha", "beta", "gamma"} {
+		if _, ok := words[w]; !ok {
+			t.Fatalf("expected %q in bank", w)
+		}
+	}
+}
+
+func TestLoadDelegatesToReader(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "words.txt")
+	if err := os.WriteFile(path, []byte("one\ntwo\n"), 0o644); err != nil {
+		t.Fatalf("write word bank: %v", err)
+	}
+
+	words, err := Load(context.Background(), path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(words) != 2 {
+		t.Fatalf("expected 2 words, got %d", len(words))
+	}
+}
+
+func TestLoadGzip(t *testing.T) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	zw.Write([]byte("one\ntwo\nthree\n"))
+	if err := zw.Close(); err != nil {
+		t.Fatalf("compress word bank: %v", err)
+	}
+
+	for _, name := range []string{"words.txt.gz", "words.txt"} {
+		path := filepath.Join(t.TempDir(), name)
+		if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+			t.Fatalf("write word bank: %v", err)
+		}
+
+		words, err := Load(context.Background(), path)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+		if len(words) != 3 {
+			t.Fatalf("%s: expected 3 words, got %d", name, len(words))
+		}
+		if _, ok := words["three"]; !ok {
+			t.Fatalf("%s: expected %q in word bank", name, "three")
+		}
+	}
+}
+
+func TestLoadWeightedFromReader(t *testing.T) {
+	const input = `word,weight
+# domain terms count double
+artery,2
+clause
+suture, 3
+artery,1
+`
+	weights, err := LoadWeightedFromReader(context.Background(), strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := map[string]int{"artery": 2, "clause": 1, "suture": 3}
+	if !reflect.DeepEqual(weights, want) {
+		t.Fatalf("expected %v, got %v", want, weights)
+	}
+}
+
+func TestLoadWeightedFromReaderRejectsBadWeights(t *testing.T) {
+	for _, input := range []string{"apple,two\n", "apple,0\n", "apple,-1\n", "apple,1,extra\n"} {
+		if _, err := LoadWeightedFromReader(context.Background(), strings.NewReader(input)); err == nil {
+			t.Fatalf("expected error for %q", input)
+		}
+	}
+	if _, err := LoadWeightedFromReader(context.Background(), strings.NewReader("word,weight\n")); !errors.Is(err, ErrEmptyWordBank) {
+		t.Fatalf("expected ErrEmptyWordBank for a header-only bank, got %v", err)
+	}
+}
+
+func TestLoadWeightedDetectsFormat(t *testing.T) {
+	dir := t.TempDir()
+	csvPath := filepath.Join(dir, "words.csv")
+	txtPath := filepath.Join(dir, "words.txt")
+	if err := os.WriteFile(csvPath, []byte("apple,2\nbanana\n"), 0o644); err != nil {
+		t.Fatalf("write word bank: %v", err)
+	}
+	if err := os.WriteFile(txtPath, []byte("apple\nbanana\n"), 0o644); err != nil {
+		t.Fatalf("write word bank: %v", err)
+	}
+
+	weights, err := LoadWeighted(context.Background(), csvPath)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := map[string]int{"apple": 2, "banana": 1}; !reflect.DeepEqual(weights, want) {
+		t.Fatalf("expected %v, got %v", want, weights)
+	}
+
+	weights, err = LoadWeighted(context.Background(), txtPath)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := map[string]int{"apple": 1, "banana": 1}; !reflect.DeepEqual(weights, want) {
+		t.Fatalf("expected %v, got %v", want, weights)
+	}
+
+	words, err := Load(context.Background(), csvPath)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := bank("apple", "banana"); !reflect.DeepEqual(words, want) {
+		t.Fatalf("expected Load to drop weights, got %v", words)
+	}
+}
+
+func TestValidatorWeight(t *testing.T) {
+	v := NewValidator(NewWeightedBank(map[string]int{"Apple": 3, "banana": 1}), WithCaseInsensitive(true))
+
+	if !v.Validate("APPLE") || v.Weight("APPLE") != 3 {
+		t.Fatalf("expected apple to be valid with weight 3, got %d", v.Weight("APPLE"))
+	}
+	if v.Weight("banana") != 1 {
+		t.Fatalf("expected banana weight 1, got %d", v.Weight("banana"))
+	}
+	if w := NewValidator(testBank("apple")).Weight("apple"); w != 1 {
+		t.Fatalf("expected unweighted bank to give weight 1, got %d", w)
+	}
+}
+
+func TestLoadAllMergesFiles(t *testing.T) {
+	dir := t.TempDir()
+	medical := filepath.Join(dir, "medical.txt")
+	legal := filepath.Join(dir, "legal.txt")
+	if err := os.WriteFile(medical, []byte("artery\nclause\nsuture\n"), 0o644); err != nil {
+		t.Fatalf("write word bank: %v", err)
+	}
+	if err := os.WriteFile(legal, []byte("clause\nstatute\nartery\n"), 0o644); err != nil {
+		t.Fatalf("write word bank: %v", err)
+	}
+
+	words, stats, err := LoadAllWithStats(context.Background(), []string{medical, legal})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, w := range []string{"artery", "clause", "suture", "statute"} {
+		if _, ok := words[w]; !ok {
+			t.Fatalf("expected %q in merged bank", w)
+		}
+	}
+	want := LoadStats{Files: 2, Total: 6, Unique: 4}
+	if stats != want || len(words) != 4 {
+		t.Fatalf("expected %+v with 4 words, got %+v with %d", want, stats, len(words))
+	}
+
+	if all, err := LoadAll(context.Background(), medical, legal); err != nil || len(all) != 4 {
+		t.Fatalf("expected LoadAll to merge 4 words, got %d (err %v)", len(all), err)
+	}
+}
+
+func TestLoadAllNamesFailingFile(t *testing.T) {
+	dir := t.TempDir()
+	good := filepath.Join(dir, "good.txt")
+	if err := os.WriteFile(good, []byte("apple\n"), 0o644); err != nil {
+		t.Fatalf("write word bank: %v", err)
+	}
+	missing := filepath.Join(dir, "missing.txt")
+
+	_, err := LoadAll(context.Background(), good, missing)
+	if err == nil || !strings.Contains(err.Error(), missing) {
+		t.Fatalf("expected error naming %s, got %v", missing, err)
+	}
+}
+
+func TestLoadAllEmpty(t *testing.T) {
+	empty := filepath.Join(t.TempDir(), "empty.txt")
+	if err := os.WriteFile(empty, nil, 0o644); err != nil {
+		t.Fatalf("write word bank: %v", err)
+	}
+
+	if _, err := LoadAll(context.Background(), empty); !errors.Is(err, ErrEmptyWordBank) {
+		t.Fatalf("expected ErrEmptyWordBank, got %v", err)
+	}
+}
+
+func TestValidateLengthBounds(t *testing.T) {
+	words := bank("cat", "lion", "tiger", "elephant", "hippopotamus", "rhinoceroses")
+
+	t.Run("defaults", func(t *testing.T) {
+		v := NewValidator(NewBank(words))
+		for _, w := range []string{"cat", "hippopotamus", "rhinoceroses"} {
+			if !v.Validate(w) {
+				t.Fatalf("expected %q to be valid with default bounds", w)
+			}
+		}
+	})
+
+	t.Run("custom", func(t *testing.T) {
+		v := NewValidator(NewBank(words), WithMinLength(4), WithMaxLength(12))
+		tests := map[string]bool{
+			"cat":          false, // below min
+			"lion":         true,  // exactly min
+			"elephant":     true,
+			"hippopotamus": true, // exactly max
+			"rhinoceroses": true, // exactly max
+		}
+		for word, want := range tests {
+			if got := v.Validate(word); got != want {
+				t.Fatalf("Validate(%q): expected %v, got %v", word, want, got)
+			}
+		}
+
+		v = NewValidator(NewBank(words), WithMinLength(4), WithMaxLength(11))
+		if v.Validate("hippopotamus") {
+			t.Fatal("expected word exceeding max length to be rejected")
+		}
+	})
+}
+
+func TestValidateCountsRunesNotBytes(t *testing.T) {
+	// "naïve" is 5 runes but 6 bytes, and "ça" 2 runes but 3 bytes.
+	v := NewValidator(testBank("naïve", "naïves", "ça"), WithMinLength(3), WithMaxLength(5))
+
+	tests := map[string]bool{
+		"naïve":  true,
+		"naïves": false,
+		"ça":     false,
+	}
+	for word, want := range tests {
+		if got := v.Validate(word); got != want {
+			t.Fatalf("Validate(%q): expected %v, got %v", word, want, got)
+		}
+	}
+}
+
+func TestLoadFromReaderSurfacesReadErrors(t *testing.T) {
+	errDisk := errors.New("disk failure")
+	r := io.MultiReader(strings.NewReader("alpha\nbeta\n"), iotest.ErrReader(errDisk))
+
+	words, err := LoadFromReader(context.Background(), r)
+	if !errors.Is(err, errDisk) {
+		t.Fatalf("expected read error to surface, got %v", err)
+	}
+	if words != nil {
+		t.Fatalf("expected no partial word bank, got %v", words)
+	}
+}
+
+func TestValidateNormalizesToNFC(t *testing.T) {
+	const (
+		nfc = "caf\u00e9"  // é as a single code point
+		nfd = "cafe\u0301" // e followed by a combining acute accent
+	)
+
+	v := NewValidator(testBank(nfc))
+	if !v.Validate(nfd) {
+		t.Fatal("expected NFD token to match NFC bank entry")
+	}
+	if !v.Validate(nfc) {
+		t.Fatal("expected NFC token to match NFC bank entry")
+	}
+
+	// A bank built by hand in NFD form is normalized too.
+	if !NewValidator(testBank(nfd)).Validate(nfc) {
+		t.Fatal("expected NFC token to match NFD bank entry")
+	}
+
+	if NewValidator(testBank(nfc), WithNormalization(false)).Validate(nfd) {
+		t.Fatal("expected NFD token not to match when normalization is disabled")
+	}
+}
+
+func TestLoadFromReaderNormalizesToNFC(t *testing.T) {
+	words, err := LoadFromReader(context.Background(), strings.NewReader("cafe\u0301\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := words["caf\u00e9"]; !ok {
+		t.Fatalf("expected NFC entry, got %q", words)
+	}
+
+	raw, err := LoadFromReader(context.Background(), strings.NewReader("cafe\u0301\n"), WithLoadNormalization(false))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := raw["cafe\u0301"]; !ok {
+		t.Fatalf("expected untouched NFD entry, got %q", raw)
+	}
+}
+
+func TestValidateAcceptsJoinedWords(t *testing.T) {
+	v := NewValidator(testBank("don't", "it’s", "well-known"))
+
+	for word, want := range map[string]bool{
+		"don't":       true,
+		"it’s":        true,
+		"well-known":  true,
+		"well--known": false,
+		"-known":      false,
+		"don'":        false,
+	} {
+		if got := v.Validate(word); got != want {
+			t.Fatalf("Validate(%q): expected %v, got %v", word, want, got)
+		}
+	}
+}
+
+// endlessBank yields "wordN" lines forever.
+type endlessBank struct{ n int }
+
+func (b *endlessBank) Read(p []byte) (int, error) {
+	var buf bytes.Buffer
+	for buf.Len() < len(p) {
+		b.n++
+		fmt.Fprintf(&buf, "word%d\n", b.n)
+	}
+	return copy(p, buf.Bytes()), nil
+}
+
+func TestLoadProgressReportsLines(t *testing.T) {
+	var input strings.Builder
+	for i := 0; i < 2500; i++ {
+		fmt.Fprintf(&input, "word%d\n", i)
+	}
+
+	var reported []int
+	words, err := LoadFromReader(context.Background(), strings.NewReader(input.String()), WithLoadProgress(1000, func(lines int) {
+		reported = append(reported, lines)
+	}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(words) != 2500 {
+		t.Fatalf("expected 2500 words, got %d", len(words))
+	}
+	if !reflect.DeepEqual(reported, []int{1000, 2000}) {
+		t.Fatalf("expected progress at 1000 and 2000 lines, got %v", reported)
+	}
+}
+
+func TestLoadCancelledMidLoadReturnsPromptly(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := LoadFromReader(ctx, &endlessBank{}, WithLoadProgress(10000, func(int) { cancel() }))
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected context.Canceled, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected loading to stop promptly after cancellation")
+	}
+}
+
+func TestLoadAllConcurrentShards(t *testing.T) {
+	dir := t.TempDir()
+	var paths []string
+	for shard := 0; shard < 8; shard++ {
+		var content strings.Builder
+		for i := 0; i < 100; i++ {
+			// Neighbouring shards overlap by half their words.
+			fmt.Fprintf(&content, "word%d\n", shard*50+i)
+		}
+		path := filepath.Join(dir, fmt.Sprintf("bank-%02d.txt", shard))
+		if err := os.WriteFile(path, []byte(content.String()), 0o644); err != nil {
+			t.Fatalf("write word bank: %v", err)
+		}
+		paths = append(paths, path)
+	}
+
+	want, wantStats, err := LoadAllWithStats(context.Background(), paths)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var lines atomic.Int64
+	got, gotStats, err := LoadAllWithStats(context.Background(), paths, WithLoadConcurrency(4), WithLoadProgress(1, func(int) { lines.Add(1) }))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) || gotStats != wantStats {
+		t.Fatalf("expected concurrent load to match sequential %+v, got %+v", wantStats, gotStats)
+	}
+	if lines.Load() != 800 {
+		t.Fatalf("expected progress for all 800 lines, got %d", lines.Load())
+	}
+
+	if _, _, err := LoadAllWithStats(context.Background(), append(paths, filepath.Join(dir, "missing.txt")), WithLoadConcurrency(4)); err == nil || !strings.Contains(err.Error(), "missing.txt") {
+		t.Fatalf("expected error naming the missing shard, got %v", err)
+	}
+}